@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/models"
+)
+
+// defaultAdherenceDays est la durée de la période de « gofit adherence » sans --from
+const defaultAdherenceDays = 7
+
+func init() {
+	register(&Command{
+		Name:    "adherence",
+		Usage:   "adherence [--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--tolerance <%>]",
+		Summary: "Comparer les apports de l'utilisateur courant à ses objectifs, jour par jour et sur la période",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA (7 jours jusqu'à --to par défaut)")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus, jour courant par défaut)")
+			tolerance := fs.Float64("tolerance", 0, "marge autour de l'objectif, en % (tolerance de la configuration, sinon 10)")
+			return func([]string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				first, end, err := recentPeriod(&user, *from, *to, defaultAdherenceDays)
+				if err != nil {
+					return err
+				}
+				margin, err := adherenceTolerance(*tolerance)
+				if err != nil {
+					return err
+				}
+				report, err := fdc.GetAdherence(user.ID, first, end, margin)
+				if err != nil {
+					return err
+				}
+				return emit(newAdherenceOut(report), func() error {
+					fdc.PrintAdherence(report, colorOutput())
+					return nil
+				})
+			}
+		},
+	})
+}
+
+// recentPeriod renvoie la période de from à to inclus : to vaut par défaut
+// le jour courant, from les days jours qui s'y terminent
+func recentPeriod(user *models.User, from, to string, days int) (time.Time, time.Time, error) {
+	var first, last time.Time
+	var err error
+	if to != "" {
+		if last, err = parseDate(to); err != nil {
+			return first, last, usageErrorf("--to : %v", err)
+		}
+	} else {
+		day, err := currentDay(user)
+		if err != nil {
+			return first, last, err
+		}
+		last = models.CalendarDay(day)
+	}
+	if from != "" {
+		if first, err = parseDate(from); err != nil {
+			return first, last, usageErrorf("--from : %v", err)
+		}
+	} else {
+		first = last.AddDate(0, 0, 1-days)
+	}
+	if last.Before(first) {
+		return first, last, usageErrorf("--to doit être postérieur ou égal à --from")
+	}
+	return first, last.AddDate(0, 0, 1), nil
+}
+
+// adherenceTolerance renvoie la marge de --tolerance, sinon celle de la
+// configuration, sinon fdc.DefaultTolerance
+func adherenceTolerance(flagValue float64) (float64, error) {
+	if flagValue < 0 {
+		return 0, usageErrorf("l'option --tolerance doit être un nombre positif")
+	}
+	if flagValue > 0 {
+		return flagValue, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Tolerance > 0 {
+		return cfg.Tolerance, nil
+	}
+	return fdc.DefaultTolerance, nil
+}
+
+// colorOutput indique si la sortie peut être colorée : un terminal, sans la
+// variable NO_COLOR
+func colorOutput() bool {
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && lineedit.IsTerminal(os.Stdout)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/output"
+)
+
+// defaultTopFoods est le nombre d'aliments du classement sans --top
+const defaultTopFoods = 10
+
+func init() {
+	register(&Command{
+		Name:    "breakdown",
+		Usage:   "breakdown [--user <utilisateur>] [--date JJ/MM/AAAA | --from JJ/MM/AAAA --to JJ/MM/AAAA] [--meal-type <type>] [--rank <nutriment>] [--top <n>] [--detail]",
+		Summary: "Détailler les apports par type de repas, par repas et par aliment, et classer les aliments qui apportent le plus",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus)")
+			mealType := fs.String("meal-type", "", "ne compter que les repas de ce type (breakfast, lunch, dinner, snack)")
+			rank := fs.String("rank", string(fdc.NutrientCalories), "nutriment du classement : calories, proteins, carbohydrates, lipids, sugars, fiber, saturated_fat, sodium, cholesterol")
+			top := fs.Int("top", defaultTopFoods, "nombre d'aliments du classement")
+			detail := fs.Bool("detail", false, "détailler chaque repas et ses aliments, même sur plusieurs jours")
+			return func([]string) error {
+				nutrient, err := fdc.ParseNutrient(*rank)
+				if err != nil {
+					return usageErrorf("--rank : %v", err)
+				}
+				if *top <= 0 {
+					return usageErrorf("l'option --top doit être un entier positif")
+				}
+				if _, err := requireUser("--user"); err != nil {
+					return err
+				}
+				filter, err := reportFilter(false, *from, *to, *mealType)
+				if err != nil {
+					return err
+				}
+				b, err := fdc.GetBreakdown(filter)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+				}
+
+				if outputFormat != output.Table {
+					foods, total, missing, err := fdc.TopContributors(b, nutrient, *top)
+					if err != nil {
+						return err
+					}
+					return emit(newBreakdownOut(b, nutrient, foods, total, missing), nil)
+				}
+				// Un seul jour se détaille toujours repas par repas
+				oneDay := !filter.From.IsZero() && filter.To.Equal(filter.From.AddDate(0, 0, 1))
+				return fdc.PrintBreakdown(b, *detail || oneDay, nutrient, *top)
+			}
+		},
+	})
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+func init() {
+	register(&Command{
+		Name:     "chart",
+		Usage:    "chart [--user <utilisateur>] [--kind <nutrition|body|macros|meals|targets>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--view <combined|dual|normalized>] [--width <px>] [--height <px>] [--format <png|svg>] <fichier>",
+		Summary:  "Tracer les apports, leur répartition ou les mesures de l'utilisateur dans une image PNG ou SVG",
+		Session:  true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			kind := fs.String("kind", string(models.ChartNutrition), "graphique : nutrition (calories et macronutriments), body (IMC et masse grasse), macros (répartition des calories), meals (calories par type de repas) ou targets (apports et besoins)")
+			from := fs.String("from", "", "premier jour tracé JJ/MM/AAAA (tous par défaut, jour courant pour macros et targets)")
+			to := fs.String("to", "", "dernier jour tracé JJ/MM/AAAA (inclus)")
+			view := fs.String("view", string(models.ChartDualAxis), "courbes : combined (un seul axe), dual (calories ou IMC sur un second axe) ou normalized (% de la moyenne)")
+			width := fs.Int("width", models.DefaultChartWidth, "largeur de l'image en pixels")
+			height := fs.Int("height", models.DefaultChartHeight, "hauteur de l'image en pixels")
+			format := fs.String("format", "", "format de l'image : png ou svg (déduit de l'extension par défaut)")
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("précisez le fichier à écrire")
+				}
+				filename := args[0]
+				opts := models.ChartOptions{Width: *width, Height: *height}
+				k, err := models.ParseChartKind(*kind)
+				if err != nil {
+					return usageErrorf("--kind : %v", err)
+				}
+				if opts.View, err = models.ParseChartView(*view); err != nil {
+					return usageErrorf("--view : %v", err)
+				}
+				if *width <= 0 || *height <= 0 {
+					return usageErrorf("la largeur et la hauteur doivent être des entiers positifs")
+				}
+				if opts.Format, err = chartFormat(filename, *format); err != nil {
+					return err
+				}
+				if *from != "" {
+					if opts.From, err = parseDate(*from); err != nil {
+						return usageErrorf("--from : %v", err)
+					}
+				}
+				if *to != "" {
+					var last time.Time
+					if last, err = parseDate(*to); err != nil {
+						return usageErrorf("--to : %v", err)
+					}
+					if !opts.From.IsZero() && last.Before(opts.From) {
+						return usageErrorf("--to doit être postérieur ou égal à --from")
+					}
+					opts.To = last.AddDate(0, 0, 1)
+				}
+
+				current, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				// La répartition et les objectifs portent par défaut sur le jour courant
+				if (k == models.ChartMacros || k == models.ChartTargets) && *from == "" && *to == "" {
+					day, err := currentDay(&current)
+					if err != nil {
+						return err
+					}
+					opts.From = models.CalendarDay(day)
+					opts.To = opts.From.AddDate(0, 0, 1)
+				}
+				user, err := fdc.GetUser(current.ID)
+				if err != nil {
+					return err
+				}
+				file, err := os.Create(filename)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la création du fichier : %w", err)
+				}
+				if err := user.RenderChart(file, k, opts); err != nil {
+					file.Close()
+					os.Remove(filename)
+					return err
+				}
+				if err := file.Close(); err != nil {
+					os.Remove(filename)
+					return i18n.Errorf("erreur lors de la création du fichier : %w", err)
+				}
+				i18n.Printf("✅ Graphique de %s %s écrit dans %s (%d × %d)\n", user.FirstName, user.LastName,
+					filename, *width, *height)
+				return nil
+			}
+		},
+	})
+}
+
+// chartFormat renvoie le format de --format, sinon celui de l'extension du fichier
+func chartFormat(filename, flagValue string) (models.ChartFormat, error) {
+	if flagValue != "" {
+		f, err := models.ParseChartFormat(flagValue)
+		if err != nil {
+			return f, usageErrorf("--format : %v", err)
+		}
+		return f, nil
+	}
+	f, err := models.ParseChartFormat(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if err != nil {
+		return f, usageErrorf("%s : extension inconnue, précisez --format png ou svg", filename)
+	}
+	return f, nil
+}
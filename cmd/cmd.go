@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/output"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+// Codes de sortie du processus
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// Command est une sous-commande de gofit, utilisable depuis le shell
+// (`gofit addfood --meal 12 ...`) comme depuis la boucle interactive.
+// Usage et Summary sont écrits en français et traduits à l'affichage.
+type Command struct {
+	Name    string
+	Usage   string
+	Summary string
+
+	// NoDB indique que la commande n'a pas besoin de la base de données
+	NoDB bool
+
+	// NoOutput indique que la commande n'écrit que du texte et n'accepte pas --output
+	NoOutput bool
+
+	// Session indique que la commande agit par défaut sur l'utilisateur et le
+	// jour courants (gofit use) ; elle accepte --user et --date pour les remplacer
+	Session bool
+
+	// Interactive indique que la commande agit sur l'état de la boucle
+	// interactive et n'y est disponible que là : gofit <commande> la refuse
+	Interactive bool
+
+	// Setup déclare les options de la commande sur fs et renvoie la fonction
+	// qui l'exécute avec les arguments positionnels restants
+	Setup func(fs *flag.FlagSet) func(args []string) error
+
+	// Wizard guide la saisie question par question quand la commande est
+	// saisie sans option dans la boucle interactive ; nil si elle n'en a pas
+	Wizard func(in wizard.LineReader, args []string) error
+}
+
+var commands = map[string]*Command{}
+
+func register(c *Command) {
+	commands[c.Name] = c
+}
+
+// Lookup renvoie la commande nommée name
+func Lookup(name string) (*Command, bool) {
+	c, ok := commands[name]
+	return c, ok
+}
+
+// Commands renvoie les commandes triées par nom
+func Commands() []*Command {
+	list := make([]*Command, 0, len(commands))
+	for _, c := range commands {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// UsageError signale des arguments invalides : la commande n'a pas été exécutée
+type UsageError struct {
+	msg string
+}
+
+func (e *UsageError) Error() string { return e.msg }
+
+func usageErrorf(format string, args ...any) error {
+	return &UsageError{msg: i18n.Sprintf(format, args...)}
+}
+
+// Execute exécute une sous-commande depuis les arguments du processus et
+// renvoie le code de sortie
+func Execute(args []string) int {
+	if len(args) == 0 {
+		detectLocale()
+		i18n.Fprintln(os.Stderr, "Usage : gofit <commande> [options] (gofit help pour la liste)")
+		return ExitUsage
+	}
+
+	detectLocale()
+	if args[0] == completeCommand {
+		return completeShell(args[1:], os.Stdout)
+	}
+	c, ok := Lookup(args[0])
+	if !ok {
+		i18n.Fprintf(os.Stderr, "Commande inconnue : %s (gofit help pour la liste)\n", args[0])
+		return ExitUsage
+	}
+	if c.Interactive {
+		if l, ok := langArg(args[1:]); ok {
+			i18n.SetLocale(l)
+		}
+		i18n.Fprintf(os.Stderr, "%s n'est disponible que dans la boucle interactive (gofit repl)\n", c.Name)
+		return ExitUsage
+	}
+
+	if !c.NoDB {
+		if err := db.InitDatabase(); err != nil {
+			i18n.Fprintf(os.Stderr, "Erreur lors de l'initialisation de la base de données : %v\n", err)
+			return ExitError
+		}
+	}
+
+	return exitCode(Run(c, args[1:], os.Stderr))
+}
+
+// Run exécute la commande c avec ses arguments, en signalant les erreurs
+// d'utilisation sur errOut
+func Run(c *Command, args []string, errOut io.Writer) error {
+	if l, ok := langArg(args); ok {
+		defer i18n.SetLocale(i18n.Current())
+		i18n.SetLocale(l)
+	}
+	fs, run, opts := newFlagSet(c)
+	defer func() {
+		outputFormat = output.Table
+		override = sessionOverride{}
+	}()
+
+	positional, err := parseFlags(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		printUsage(os.Stdout, c, fs)
+		return nil
+	}
+	if err != nil {
+		err = &UsageError{msg: err.Error()}
+	} else {
+		outputFormat = opts.format
+		override = opts.session
+		fdc.SetAuditCommand(strings.TrimSpace("gofit " + c.Name + " " + strings.Join(args, " ")))
+		err = run(positional)
+	}
+
+	var usageErr *UsageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(errOut, usageErr.msg)
+		printUsage(errOut, c, fs)
+	case err != nil:
+		i18n.Fprintf(errOut, "Erreur : %v\n", err)
+	}
+	return err
+}
+
+// globalOptions regroupe les options communes à toutes les commandes
+type globalOptions struct {
+	format  output.Format
+	lang    i18n.Locale
+	session sessionOverride
+}
+
+// newFlagSet déclare les options de la commande c, les options communes
+// --output et --lang et, pour les commandes liées à la session, --user et --date
+func newFlagSet(c *Command) (*flag.FlagSet, func([]string) error, *globalOptions) {
+	fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run := c.Setup(fs)
+	opts := &globalOptions{format: output.Table}
+	if !c.NoOutput {
+		fs.Var(&opts.format, "output", "format de sortie : table, json, csv ou yaml")
+	}
+	fs.Var(&opts.lang, "lang", "langue de l'interface : fr ou en")
+	if c.Session {
+		fs.StringVar(&opts.session.user, "user", "", "utilisateur (identifiant ou nom) ; défaut : utilisateur courant")
+		fs.StringVar(&opts.session.date, "date", "", "jour JJ/MM/AAAA ; défaut : jour courant")
+	}
+	return fs, run, opts
+}
+
+// langArg cherche l'option --lang parmi les arguments, pour que les messages
+// d'analyse des autres options soient déjà dans la bonne langue
+func langArg(args []string) (i18n.Locale, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		if l, err := i18n.ParseLocale(value); err == nil {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// detectLocale choisit la langue de l'interface d'après l'environnement et
+// le fichier de configuration ; une configuration illisible est ignorée ici
+func detectLocale() {
+	cfg, _ := config.Load()
+	i18n.SetLocale(i18n.Detect(cfg.Lang))
+}
+
+func exitCode(err error) int {
+	var usageErr *UsageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	}
+	return ExitError
+}
+
+// parseFlags analyse les options où qu'elles se trouvent parmi les arguments
+// positionnels ; tout ce qui suit « -- » est positionnel
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printUsage affiche la syntaxe de c et, si fs n'est pas nil, ses options.
+// Usage commence par le nom de la commande ; seule la suite est traduite,
+// comme les descriptions des options.
+func printUsage(w io.Writer, c *Command, fs *flag.FlagSet) {
+	usage := c.Name
+	if args := strings.TrimPrefix(c.Usage, c.Name); args != "" {
+		usage += " " + i18n.T(strings.TrimSpace(args))
+	}
+	i18n.Fprintf(w, "Usage : gofit %s\n", usage)
+	if fs == nil {
+		return
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "  --%-12s %s\n", f.Name, i18n.T(f.Usage))
+	})
+}
+
+// SplitLine découpe une ligne saisie dans la boucle interactive en arguments,
+// en respectant les guillemets simples et doubles
+func SplitLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, i18n.Errorf("guillemet %c non fermé", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/export"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/output"
+)
+
+// completion fournit les valeurs possibles d'un argument
+type completion func() []lineedit.Candidate
+
+// argCompletions donne, par commande, la complétion de chaque argument positionnel
+var argCompletions = map[string][]completion{
+	"detail":          {foodCompletions},
+	"addfood":         {foodCompletions},
+	"timezone":        {userCompletions},
+	"addmeasurement":  {userCompletions},
+	"deluser":         {userCompletions},
+	"delmenu":         {menuCompletions},
+	"delmeal":         {mealCompletions},
+	"edituser":        {userCompletions, fieldCompletions(fdc.EntityUser)},
+	"editmeal":        {mealCompletions, fieldCompletions(fdc.EntityMeal)},
+	"editmeasurement": {nil, fieldCompletions(fdc.EntityMeasurement)},
+	"editmenu":        {menuCompletions},
+	"restore":         {words("user", "menu", "meal", "measurement")},
+	"history":         {words("user", "menu", "meal", "item", "measurement")},
+	"list":            {words("users", "menus", "meals", "measurements"), userCompletions},
+	"help":            {commandCompletions},
+	"lang":            {localeCompletions},
+	"use":             {userCompletions},
+	"star":            {foodCompletions},
+	"unstar":          {foodCompletions},
+	"copymenu":        {menuCompletions},
+	"copymeal":        {mealCompletions},
+}
+
+// flagCompletions donne la complétion de la valeur des options, par nom d'option
+var flagCompletions = map[string]completion{
+	"user":      userCompletions,
+	"menu":      menuCompletions,
+	"meal":      mealCompletions,
+	"fdc":       foodCompletions,
+	"type":      words(string(models.Breakfast), string(models.Lunch), string(models.Dinner), string(models.Snack)),
+	"meal-type": words(string(models.Breakfast), string(models.Lunch), string(models.Dinner), string(models.Snack)),
+	"by":        words(string(fdc.PeriodWeek), string(fdc.PeriodMonth)),
+	"rank":      nutrientCompletions,
+	"gender":    words(string(models.Male), string(models.Female)),
+	"goal":      words(string(models.WeightLoss), string(models.Maintenance), string(models.MuscleGain)),
+	"format":    words(string(export.HTML), string(export.PDF), string(models.ChartPNG), string(models.ChartSVG)),
+	"kind":      words(string(models.ChartNutrition), string(models.ChartBody), string(models.ChartMacros), string(models.ChartMeals), string(models.ChartTargets)),
+	"view":      words(string(models.ChartCombined), string(models.ChartDualAxis), string(models.ChartNormalized)),
+	"output":    words(string(output.Table), string(output.JSON), string(output.CSV), string(output.YAML)),
+	"lang":      localeCompletions,
+	"bars":      words(fdc.TrendNames()...),
+}
+
+// completeLine complète le mot word d'une ligne de la boucle interactive dont
+// le début est prefix : noms de commandes, options, identifiants
+// d'utilisateurs, de menus et de repas, aliments des dernières recherches
+// et de l'utilisateur courant
+func completeLine(prefix, word string) []lineedit.Candidate {
+	parts, err := SplitLine(prefix)
+	if err != nil {
+		return nil
+	}
+	if len(parts) > 0 && parts[0] == "help" {
+		parts = append([]string{"gofit"}, parts...)
+	}
+	switch {
+	case len(parts) == 0:
+		return filterCandidates(words("gofit", "help", "exit")(), word)
+	case len(parts) == 1 && parts[0] == "gofit":
+		return filterCandidates(append(commandCompletions(), lineedit.Candidate{Value: "exit"}), word)
+	}
+	return completeWords(parts, word)
+}
+
+// completeWords complète word, précédé des mots parts dont le premier est « gofit »
+func completeWords(parts []string, word string) []lineedit.Candidate {
+	if len(parts) == 0 || parts[0] != "gofit" {
+		return nil
+	}
+	if len(parts) == 1 {
+		return filterCandidates(commandCompletions(), word)
+	}
+
+	c, ok := Lookup(parts[1])
+	if !ok {
+		return nil
+	}
+	fs, _, _ := newFlagSet(c)
+	args := parts[2:]
+
+	if strings.HasPrefix(word, "-") {
+		if name, value, ok := strings.Cut(strings.TrimLeft(word, "-"), "="); ok {
+			candidates := filterCandidates(flagValueCompletions(c, name), value)
+			for i := range candidates {
+				candidates[i].Value = "--" + name + "=" + candidates[i].Value
+			}
+			return candidates
+		}
+		var candidates []lineedit.Candidate
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, lineedit.Candidate{Value: "--" + f.Name, Description: i18n.T(f.Usage)})
+		})
+		return filterCandidates(candidates, word)
+	}
+
+	position := 0
+	for i := 0; i < len(args); i++ {
+		name, ok := flagName(fs, args[i])
+		if !ok {
+			position++
+			continue
+		}
+		if i == len(args)-1 {
+			return filterCandidates(flagValueCompletions(c, name), word)
+		}
+		i++
+	}
+	if completions := argCompletions[c.Name]; position < len(completions) && completions[position] != nil {
+		return filterCandidates(completions[position](), word)
+	}
+	return nil
+}
+
+// flagName renvoie le nom de l'option arg si elle attend une valeur dans l'argument suivant
+func flagName(fs *flag.FlagSet, arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return "", false
+	}
+	f := fs.Lookup(strings.TrimLeft(arg, "-"))
+	if f == nil {
+		return "", false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return "", false
+	}
+	return f.Name, true
+}
+
+func flagValueCompletions(c *Command, name string) []lineedit.Candidate {
+	if c.Name == "addmeal" && name == "meal" {
+		return templateCompletions()
+	}
+	if complete, ok := flagCompletions[name]; ok {
+		return complete()
+	}
+	return nil
+}
+
+// filterCandidates garde les complétions dont la valeur commence par word
+// ou dont le libellé le contient, pour compléter un nom par son identifiant
+func filterCandidates(candidates []lineedit.Candidate, word string) []lineedit.Candidate {
+	var kept []lineedit.Candidate
+	lower := strings.ToLower(word)
+	for _, c := range candidates {
+		if strings.HasPrefix(c.Value, word) || (word != "" && strings.Contains(strings.ToLower(c.Description), lower)) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func nutrientCompletions() []lineedit.Candidate {
+	candidates := make([]lineedit.Candidate, len(fdc.RankedNutrients))
+	for i, n := range fdc.RankedNutrients {
+		candidates[i] = lineedit.Candidate{Value: string(n), Description: n.Label()}
+	}
+	return candidates
+}
+
+func words(values ...string) completion {
+	return func() []lineedit.Candidate {
+		candidates := make([]lineedit.Candidate, len(values))
+		for i, v := range values {
+			candidates[i] = lineedit.Candidate{Value: v}
+		}
+		return candidates
+	}
+}
+
+// labelled associe un identifiant à son libellé
+func labelled(id uint, label string) lineedit.Candidate {
+	return lineedit.Candidate{Value: strconv.FormatUint(uint64(id), 10), Description: label}
+}
+
+func commandCompletions() []lineedit.Candidate {
+	var candidates []lineedit.Candidate
+	for _, c := range Commands() {
+		candidates = append(candidates, lineedit.Candidate{Value: c.Name, Description: i18n.T(c.Summary)})
+	}
+	return candidates
+}
+
+func localeCompletions() []lineedit.Candidate {
+	var candidates []lineedit.Candidate
+	for _, l := range i18n.Locales {
+		candidates = append(candidates, lineedit.Candidate{Value: string(l)})
+	}
+	return candidates
+}
+
+func fieldCompletions(entity fdc.Entity) completion {
+	return words(editableFieldNames(entity)...)
+}
+
+func userCompletions() []lineedit.Candidate {
+	if db.DB == nil {
+		return nil
+	}
+	users, err := fdc.GetUsers()
+	if err != nil {
+		return nil
+	}
+	var candidates []lineedit.Candidate
+	for _, u := range users {
+		candidates = append(candidates, labelled(u.ID, u.FirstName+" "+u.LastName))
+	}
+	return candidates
+}
+
+func menuCompletions() []lineedit.Candidate {
+	if db.DB == nil {
+		return nil
+	}
+	menus, err := fdc.GetDailyMenus()
+	if err != nil {
+		return nil
+	}
+	var candidates []lineedit.Candidate
+	for _, m := range menus {
+		candidates = append(candidates, labelled(m.ID, i18n.Sprintf("%s %s - %s", m.User.FirstName, m.User.LastName, i18n.Date(m.Date))))
+	}
+	return candidates
+}
+
+// mealCompletions propose les repas des menus puis les repas types
+func mealCompletions() []lineedit.Candidate {
+	if db.DB == nil {
+		return nil
+	}
+	menus, err := fdc.GetDailyMenus()
+	if err != nil {
+		return nil
+	}
+	var candidates []lineedit.Candidate
+	for _, m := range menus {
+		for _, meal := range m.Meals {
+			candidates = append(candidates, labelled(meal.ID, i18n.Sprintf("%s (%s) - %s", meal.Description, meal.Type, i18n.Date(m.Date))))
+		}
+	}
+	return append(candidates, templateCompletions()...)
+}
+
+func templateCompletions() []lineedit.Candidate {
+	if db.DB == nil {
+		return nil
+	}
+	meals, err := fdc.GetMeals()
+	if err != nil {
+		return nil
+	}
+	var candidates []lineedit.Candidate
+	for _, meal := range meals {
+		candidates = append(candidates, labelled(meal.ID, i18n.Sprintf("%s (%s)", meal.Description, meal.Type)))
+	}
+	return candidates
+}
+
+// foodCompletions propose les aliments trouvés par les dernières recherches
+func foodCompletions() []lineedit.Candidate {
+	var candidates []lineedit.Candidate
+	seen := map[int]bool{}
+	for _, r := range fdc.RecentResults() {
+		candidates = append(candidates, lineedit.Candidate{Value: strconv.Itoa(r.FdcID), Description: r.Description})
+		seen[r.FdcID] = true
+	}
+	// Puis les aliments consommés ou favoris de l'utilisateur courant
+	if db.DB == nil {
+		return candidates
+	}
+	usage, err := usedFoods()
+	if err != nil {
+		return candidates
+	}
+	for _, u := range usage {
+		if !seen[u.FdcID] {
+			candidates = append(candidates, lineedit.Candidate{Value: strconv.Itoa(u.FdcID), Description: u.Name})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/lsoulet/gofit/db"
+)
+
+// completeCommand est la commande cachée appelée par les scripts de complétion
+// du shell : « gofit __complete <mots précédents...> <mot courant> » écrit une
+// complétion par ligne, suivie d'une tabulation et de sa description
+const completeCommand = "__complete"
+
+func init() {
+	register(&Command{
+		Name:     "completion",
+		Usage:    "completion <bash|zsh|fish>",
+		Summary:  "Écrire le script de complétion du shell",
+		NoDB:     true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("un shell est attendu : bash, zsh ou fish")
+				}
+				script, ok := completionScripts[args[0]]
+				if !ok {
+					return usageErrorf("shell inconnu : %s (bash, zsh ou fish)", args[0])
+				}
+				fmt.Print(script)
+				return nil
+			}
+		},
+	})
+}
+
+// completeShell répond aux scripts de complétion. Les identifiants sont lus
+// dans la base si elle est joignable, sans migration ni message d'erreur.
+func completeShell(args []string, out io.Writer) int {
+	if len(args) == 0 {
+		return ExitUsage
+	}
+	if err := db.Open(); err != nil {
+		db.DB = nil
+	}
+	parts := append([]string{"gofit"}, args[:len(args)-1]...)
+	for _, c := range completeWords(parts, args[len(args)-1]) {
+		fmt.Fprintf(out, "%s\t%s\n", c.Value, c.Description)
+	}
+	return ExitOK
+}
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion = `# Complétion bash de gofit
+# Installation : gofit completion bash > /etc/bash_completion.d/gofit
+# ou, pour la session en cours : source <(gofit completion bash)
+_gofit() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	local IFS=$'\n'
+	COMPREPLY=($(gofit __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null | cut -f1))
+}
+complete -o default -F _gofit gofit
+`
+
+const zshCompletion = `#compdef gofit
+# Complétion zsh de gofit
+# Installation : gofit completion zsh > "${fpath[1]}/_gofit"
+# ou, pour la session en cours : source <(gofit completion zsh)
+_gofit() {
+	local -a candidates
+	local line
+	for line in "${(@f)$(gofit __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}"; do
+		[[ -n $line ]] || continue
+		candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+	done
+	_describe gofit candidates
+}
+if [[ $funcstack[1] == _gofit ]]; then
+	_gofit "$@"
+else
+	compdef _gofit gofit
+fi
+`
+
+const fishCompletion = `# Complétion fish de gofit
+# Installation : gofit completion fish > ~/.config/fish/completions/gofit.fish
+function __gofit_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	gofit __complete $words (commandline -ct) 2>/dev/null
+end
+complete -c gofit -f -a '(__gofit_complete)'
+`
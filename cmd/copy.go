@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"flag"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// maxCopyDays borne la période de --to à --until, pour qu'une faute de frappe
+// sur l'année ne remplisse pas des années de menus
+const maxCopyDays = 366
+
+func init() {
+	register(&Command{
+		Name:    "copymenu",
+		Usage:   "copymenu [<id menu>] --to JJ/MM/AAAA [--until JJ/MM/AAAA] [--scale <facteur>]",
+		Summary: "Copier tous les repas d'un menu journalier (jour courant par défaut) vers un autre jour ou une période",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			to, until, scale := copyFlags(fs)
+			return func(args []string) error {
+				days, err := copyDays(*to, *until, *scale)
+				if err != nil {
+					return err
+				}
+				var menuID uint
+				switch len(args) {
+				case 0:
+					user, ok, err := currentUser()
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return usageErrorf("l'identifiant du menu est attendu sans utilisateur courant (gofit use <utilisateur>)")
+					}
+					day, err := currentDay(&user)
+					if err != nil {
+						return err
+					}
+					menu, err := user.GetDailyMenuByDate(day)
+					if err != nil {
+						return usageErrorf("aucun menu le %s pour %s %s", i18n.Date(day), user.FirstName, user.LastName)
+					}
+					menuID = menu.ID
+				case 1:
+					if menuID, err = parseID(args[0]); err != nil {
+						return usageErrorf("%v", err)
+					}
+				default:
+					return usageErrorf("un seul menu est attendu")
+				}
+
+				meals, err := fdc.CopyDailyMenu(menuID, days, *scale)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la copie du menu : %w", err)
+				}
+				return emit(listOf(meals, newMealOut), func() error {
+					printCopiedMeals(meals, len(days))
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "copymeal",
+		Usage:   "copymeal [<id repas> | --type <type>] --to JJ/MM/AAAA [--until JJ/MM/AAAA] [--scale <facteur>]",
+		Summary: "Copier un repas (dernier repas du jour courant par défaut) vers un autre jour ou une période",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			mealType := fs.String("type", "", "type du repas du jour courant à copier (breakfast, lunch, dinner, snack)")
+			to, until, scale := copyFlags(fs)
+			return func(args []string) error {
+				days, err := copyDays(*to, *until, *scale)
+				if err != nil {
+					return err
+				}
+				if *mealType != "" {
+					if _, err := parseMealType(*mealType); err != nil {
+						return usageErrorf("--type : %v", err)
+					}
+				}
+				if len(args) > 1 {
+					return usageErrorf("un seul repas est attendu")
+				}
+
+				// Le repas est copié chez l'utilisateur courant, ou à défaut
+				// chez celui de son menu
+				user, ok, err := currentUser()
+				if err != nil {
+					return err
+				}
+				var mealID uint
+				if len(args) == 1 {
+					if mealID, err = parseID(args[0]); err != nil {
+						return usageErrorf("%v", err)
+					}
+				} else {
+					if !ok {
+						return usageErrorf("l'identifiant du repas est attendu sans utilisateur courant (gofit use <utilisateur>)")
+					}
+					meal, err := currentMeal(&user, *mealType)
+					if err != nil {
+						return err
+					}
+					mealID = meal.ID
+				}
+
+				meals, err := fdc.CopyMeal(mealID, user.ID, days, *scale)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la copie du repas : %w", err)
+				}
+				return emit(listOf(meals, newMealOut), func() error {
+					printCopiedMeals(meals, len(days))
+					return nil
+				})
+			}
+		},
+	})
+}
+
+// copyFlags déclare les options communes à copymenu et copymeal
+func copyFlags(fs *flag.FlagSet) (to, until *string, scale *float64) {
+	to = fs.String("to", "", "jour de destination JJ/MM/AAAA, ou premier jour de la période")
+	until = fs.String("until", "", "dernier jour de la période JJ/MM/AAAA (inclus)")
+	scale = fs.Float64("scale", 1, "facteur appliqué aux portions, ex. 0.5 ou 1.5")
+	return to, until, scale
+}
+
+// copyDays renvoie les jours calendaires de to à until inclus
+func copyDays(to, until string, scale float64) ([]time.Time, error) {
+	if to == "" {
+		return nil, usageErrorf("l'option --to est obligatoire")
+	}
+	if scale <= 0 {
+		return nil, usageErrorf("l'option --scale doit être un nombre positif")
+	}
+	from, err := parseDate(to)
+	if err != nil {
+		return nil, usageErrorf("--to : %v", err)
+	}
+	last := from
+	if until != "" {
+		if last, err = parseDate(until); err != nil {
+			return nil, usageErrorf("--until : %v", err)
+		}
+	}
+	if last.Before(from) {
+		return nil, usageErrorf("--until doit être postérieur ou égal à --to")
+	}
+
+	var days []time.Time
+	for day := from; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if len(days) == maxCopyDays {
+			return nil, usageErrorf("la période ne peut pas dépasser %d jours", maxCopyDays)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func printCopiedMeals(meals []models.Meal, days int) {
+	for _, meal := range meals {
+		i18n.Printf("✔ %s (%s) copié dans le menu %d (id %d) : %.0f kcal\n",
+			meal.Description, meal.Type, *meal.DailyMenuID, meal.ID, meal.Calories)
+	}
+	i18n.Printf("✅ %d repas copié(s) sur %d jour(s)\n", len(meals), days)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lsoulet/gofit/dashboard"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+func init() {
+	register(&Command{
+		Name:     "dashboard",
+		Usage:    "dashboard [--user <utilisateur>] [--date JJ/MM/AAAA] [--tolerance <%>]",
+		Summary:  "Ouvrir le tableau de bord plein écran : menu du jour, objectifs, apports restants et tendance du poids",
+		Session:  true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			tolerance := fs.Float64("tolerance", 0, "marge autour de l'objectif, en % (tolerance de la configuration, sinon 10)")
+			return func([]string) error {
+				if !lineedit.IsTerminal(os.Stdin) || !lineedit.IsTerminal(os.Stdout) {
+					return usageErrorf("le tableau de bord demande un terminal : utilisez gofit report ou gofit trend dans un script")
+				}
+				margin, err := adherenceTolerance(*tolerance)
+				if err != nil {
+					return err
+				}
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				day, err := currentDay(&user)
+				if err != nil {
+					return err
+				}
+				return dashboard.Run(os.Stdin, os.Stdout, dashboard.Options{
+					UserID:    user.ID,
+					Day:       day,
+					Tolerance: margin,
+					Color:     colorOutput(),
+					AddFood:   dashboardAddFood,
+				})
+			}
+		},
+	})
+}
+
+// dashboardAddFood ajoute des aliments depuis le tableau de bord comme
+// « gofit log --user <userID> --date <day> » : les ambiguïtés sont levées
+// par des questions lues sur in
+func dashboardAddFood(in wizard.LineReader, userID uint, day time.Time, text string) error {
+	saved := override
+	defer func() { override = saved }()
+	override = sessionOverride{user: strconv.FormatUint(uint64(userID), 10), date: i18n.Date(day)}
+	return logFoods(in, text, "")
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"flag"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/output"
+)
+
+func init() {
+	for name, c := range deleteCommands {
+		register(&Command{
+			Name:    name,
+			Usage:   name + " <id>",
+			Summary: c.summary,
+			Setup:   deleteSetup(c.entity),
+		})
+	}
+	for name, c := range editCommands {
+		register(&Command{
+			Name:    name,
+			Usage:   name + " <id> <champ> <valeur>",
+			Summary: c.summary,
+			Setup:   editSetup(c.entity),
+		})
+	}
+
+	register(&Command{
+		Name:    "editmenu",
+		Usage:   "editmenu <id> <JJ/MM/AAAA>",
+		Summary: "Déplacer un menu journalier à une autre date",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) != 2 {
+					return usageErrorf("un identifiant de menu et une date sont attendus")
+				}
+				id, err := parseID(args[0])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				date, err := parseDate(args[1])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				if err := fdc.UpdateDailyMenuDate(id, date); err != nil {
+					return err
+				}
+				return done("update", fdc.EntityDailyMenu, id, i18n.Sprintf("✅ Menu %d déplacé au %s", id, args[1]))
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "trash",
+		Usage:   "trash",
+		Summary: "Afficher la corbeille",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func([]string) error {
+				if outputFormat == output.Table {
+					return fdc.ListTrash()
+				}
+				entries, err := fdc.GetTrash()
+				if err != nil {
+					return err
+				}
+				return emit(listOf(entries, newTrashOut), nil)
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "restore",
+		Usage:   "restore <user|menu|meal|measurement> <id>",
+		Summary: "Restaurer un enregistrement de la corbeille",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) != 2 {
+					return usageErrorf("une entité et un identifiant sont attendus")
+				}
+				entity, err := fdc.ParseEntity(args[0])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				id, err := parseID(args[1])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				if err := fdc.RestoreEntity(entity, id); err != nil {
+					return err
+				}
+				return done("restore", entity, id, i18n.Sprintf("♻️ %s %d restauré", entity, id))
+			}
+		},
+	})
+
+	register(&Command{
+		Name:        "undo",
+		Usage:       "undo",
+		Summary:     "Annuler la dernière modification de la boucle interactive (disponible seulement dans celle-ci)",
+		Interactive: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func([]string) error {
+				description, err := fdc.Undo()
+				if err != nil {
+					return err
+				}
+				return done("undo", "", 0, i18n.Sprintf("↩️ Annulé : %s", description))
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "history",
+		Usage:   "history [user|menu|meal|item|measurement [id]] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA]",
+		Summary: "Consulter le journal des modifications",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "date de début JJ/MM/AAAA")
+			to := fs.String("to", "", "date de fin JJ/MM/AAAA (incluse)")
+			return func(args []string) error {
+				filter, err := historyFilter(args, *from, *to)
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				if outputFormat == output.Table {
+					return fdc.ShowHistory(filter)
+				}
+				logs, err := fdc.GetHistory(filter)
+				if err != nil {
+					return err
+				}
+				return emit(listOf(logs, newHistoryOut), nil)
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "list",
+		Usage:   "list <users|menus|meals|measurements> [utilisateur]",
+		Summary: "Lister les enregistrements avec leur identifiant",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) < 1 {
+					return usageErrorf("précisez ce qu'il faut lister")
+				}
+				switch args[0] {
+				case "users":
+					if outputFormat == output.Table {
+						return fdc.ListUsers()
+					}
+					users, err := fdc.GetUsers()
+					if err != nil {
+						return err
+					}
+					return emit(listOf(users, newUserOut), nil)
+				case "menus":
+					if outputFormat == output.Table {
+						return fdc.ListDailyMenus()
+					}
+					menus, err := fdc.GetDailyMenus()
+					if err != nil {
+						return err
+					}
+					return emit(listOf(menus, newMenuOut), nil)
+				case "meals":
+					if outputFormat == output.Table {
+						return fdc.ListMeals()
+					}
+					meals, err := fdc.GetMeals()
+					if err != nil {
+						return err
+					}
+					return emit(listOf(meals, newMealOut), nil)
+				case "measurements":
+					// Sans identifiant, ce sont les mesures de l'utilisateur courant
+					var (
+						user models.User
+						ok   = true
+						err  error
+					)
+					if len(args) >= 2 {
+						user, err = resolveUser(args[1])
+					} else {
+						user, ok, err = currentUser()
+					}
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return usageErrorf("l'identifiant de l'utilisateur est attendu")
+					}
+					if outputFormat == output.Table {
+						return fdc.ListMeasurements(user.ID)
+					}
+					return emit(listOf(user.Measurements, newMeasurementOut), nil)
+				}
+				return usageErrorf("liste inconnue : %s", args[0])
+			}
+		},
+	})
+}
+
+// entityCommand associe une commande à l'entité qu'elle manipule ; le résumé
+// est une clé de traduction, il reste donc littéral
+type entityCommand struct {
+	entity  fdc.Entity
+	summary string
+}
+
+var deleteCommands = map[string]entityCommand{
+	"deluser":        {fdc.EntityUser, "Placer un utilisateur dans la corbeille, avec ses menus et mesures"},
+	"delmenu":        {fdc.EntityDailyMenu, "Placer un menu journalier dans la corbeille, avec ses repas"},
+	"delmeal":        {fdc.EntityMeal, "Placer un repas dans la corbeille, avec ses aliments"},
+	"delmeasurement": {fdc.EntityMeasurement, "Placer une mesure dans la corbeille"},
+}
+
+var editCommands = map[string]entityCommand{
+	"edituser":        {fdc.EntityUser, "Modifier un champ d'un utilisateur (prenom, nom, age, genre, objectif, fuseau, calories, proteines, glucides, lipides)"},
+	"editmeal":        {fdc.EntityMeal, "Modifier un champ d'un repas (type, description, calories, proteines, glucides, lipides)"},
+	"editmeasurement": {fdc.EntityMeasurement, "Modifier un champ d'une mesure (poids, taille, massegrasse)"},
+}
+
+// editableField associe un champ saisi en CLI à sa colonne et à son
+// analyseur ; english est son nom en anglais, accepté dans les deux langues
+type editableField struct {
+	column  string
+	english string
+	parse   func(string) (any, error)
+}
+
+var editableFields = map[fdc.Entity]map[string]editableField{
+	fdc.EntityUser: {
+		"prenom":    {"first_name", "firstname", parseNonEmpty},
+		"nom":       {"last_name", "lastname", parseNonEmpty},
+		"age":       {"age", "age", parsePositiveInt},
+		"genre":     {"gender", "gender", parseGender},
+		"objectif":  {"goal", "goal", parseGoal},
+		"fuseau":    {"time_zone", "timezone", parseTimeZone},
+		"calories":  {"calorie_needs", "calories", parsePositiveFloat},
+		"proteines": {"protein_needs", "proteins", parsePositiveFloat},
+		"glucides":  {"carohydrates_needs", "carbohydrates", parsePositiveFloat},
+		"lipides":   {"lipid_needs", "lipids", parsePositiveFloat},
+	},
+	fdc.EntityMeal: {
+		"type":        {"type", "type", parseMealType},
+		"description": {"description", "description", parseNonEmpty},
+		"calories":    {"calories", "calories", parsePositiveFloat},
+		"proteines":   {"proteins", "proteins", parsePositiveFloat},
+		"glucides":    {"carbohydrates", "carbohydrates", parsePositiveFloat},
+		"lipides":     {"lipids", "lipids", parsePositiveFloat},
+	},
+	fdc.EntityMeasurement: {
+		"poids":       {"weight", "weight", parsePositiveFloat},
+		"taille":      {"height", "height", parsePositiveFloat},
+		"massegrasse": {"body_fat", "bodyfat", parsePositiveFloat},
+	},
+}
+
+// editableFieldNames renvoie les noms des champs de entity dans la langue
+// de l'interface
+func editableFieldNames(entity fdc.Entity) []string {
+	var names []string
+	for name, field := range editableFields[entity] {
+		if i18n.Current() == i18n.English {
+			name = field.english
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupEditField trouve un champ de entity par son nom français ou anglais
+func lookupEditField(entity fdc.Entity, name string) (editableField, bool) {
+	if field, ok := editableFields[entity][name]; ok {
+		return field, true
+	}
+	for _, field := range editableFields[entity] {
+		if field.english == name {
+			return field, true
+		}
+	}
+	return editableField{}, false
+}
+
+func parseEditField(entity fdc.Entity, name, value string) (string, any, error) {
+	field, ok := lookupEditField(entity, strings.ToLower(name))
+	if !ok {
+		return "", nil, i18n.Errorf("champ inconnu : %s (champs : %s)", name, strings.Join(editableFieldNames(entity), ", "))
+	}
+	v, err := field.parse(value)
+	if err != nil {
+		return "", nil, i18n.Errorf("valeur invalide pour %s : %w", name, err)
+	}
+	return field.column, v, nil
+}
+
+func deleteSetup(entity fdc.Entity) func(*flag.FlagSet) func([]string) error {
+	return func(fs *flag.FlagSet) func([]string) error {
+		return func(args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("un identifiant est attendu")
+			}
+			id, err := parseID(args[0])
+			if err != nil {
+				return usageErrorf("%v", err)
+			}
+			if err := fdc.DeleteEntity(entity, id); err != nil {
+				return err
+			}
+			return done("delete", entity, id,
+				i18n.Sprintf("🗑️ %s %d placé dans la corbeille (gofit restore %s %d pour le récupérer)", entity, id, entity, id))
+		}
+	}
+}
+
+func editSetup(entity fdc.Entity) func(*flag.FlagSet) func([]string) error {
+	return func(fs *flag.FlagSet) func([]string) error {
+		return func(args []string) error {
+			if len(args) < 3 {
+				return usageErrorf("un identifiant, un champ et une valeur sont attendus")
+			}
+			id, err := parseID(args[0])
+			if err != nil {
+				return usageErrorf("%v", err)
+			}
+			value := strings.Join(args[2:], " ")
+			column, v, err := parseEditField(entity, args[1], value)
+			if err != nil {
+				return usageErrorf("%v", err)
+			}
+			updates := map[string]any{column: v}
+			switch entity {
+			case fdc.EntityMeasurement:
+				err = fdc.UpdateMeasurement(id, updates)
+			case fdc.EntityMeal:
+				err = fdc.UpdateMeal(id, updates)
+			default:
+				err = fdc.UpdateEntity(entity, id, updates)
+			}
+			if err != nil {
+				return err
+			}
+			return done("update", entity, id, i18n.Sprintf("✅ %s %d modifié : %s = %s", entity, id, args[1], value))
+		}
+	}
+}
+
+// historyFilter construit le filtre de `history` ; la date de fin est incluse.
+// Les dates acceptent les mêmes formats que les autres options (parseDate) et
+// désignent des jours à minuit dans le fuseau local, comme les dates du journal.
+func historyFilter(args []string, from, to string) (fdc.HistoryFilter, error) {
+	var filter fdc.HistoryFilter
+	if from != "" {
+		date, err := parseDate(from)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = localDay(date)
+	}
+	if to != "" {
+		date, err := parseDate(to)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = localDay(date).AddDate(0, 0, 1)
+	}
+
+	if len(args) > 2 {
+		return filter, i18n.Errorf("trop d'arguments")
+	}
+	if len(args) >= 1 {
+		entity := fdc.Entity(args[0])
+		if _, err := fdc.ParseEntity(args[0]); err != nil && entity != fdc.EntityMealItem {
+			return filter, err
+		}
+		filter.Entity = entity
+	}
+	if len(args) == 2 {
+		id, err := parseID(args[1])
+		if err != nil {
+			return filter, err
+		}
+		filter.EntityID = id
+	}
+	return filter, nil
+}
+
+// localDay renvoie le jour date à minuit dans le fuseau local
+func localDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+
+	"github.com/lsoulet/gofit/export"
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// defaultExportDays est la durée de la période de « gofit export » sans --from
+const defaultExportDays = 30
+
+func init() {
+	register(&Command{
+		Name:     "export",
+		Usage:    "export [--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--format <html|pdf>] [--tolerance <%>] <fichier>",
+		Summary:  "Exporter le rapport nutritionnel de l'utilisateur en page HTML autonome ou en PDF, avec le suivi des objectifs et les graphiques",
+		Session:  true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA (30 jours jusqu'à --to par défaut)")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus, jour courant par défaut)")
+			format := fs.String("format", "", "format du fichier : html ou pdf (déduit de l'extension par défaut)")
+			tolerance := fs.Float64("tolerance", 0, "marge autour de l'objectif, en % (tolerance de la configuration, sinon 10)")
+			return func(args []string) error {
+				if len(args) != 1 {
+					return usageErrorf("précisez le fichier à écrire")
+				}
+				filename := args[0]
+				f, ok := export.FormatOf(filename)
+				if *format != "" {
+					var err error
+					if f, err = export.ParseFormat(*format); err != nil {
+						return usageErrorf("--format : %v", err)
+					}
+				} else if !ok {
+					return usageErrorf("%s : extension inconnue, précisez --format html ou pdf", filename)
+				}
+
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				first, end, err := recentPeriod(&user, *from, *to, defaultExportDays)
+				if err != nil {
+					return err
+				}
+				margin, err := adherenceTolerance(*tolerance)
+				if err != nil {
+					return err
+				}
+				report, err := export.Build(user.ID, first, end, margin)
+				if err != nil {
+					return err
+				}
+
+				file, err := os.Create(filename)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la création du fichier : %w", err)
+				}
+				if err := export.Write(file, f, report); err != nil {
+					file.Close()
+					os.Remove(filename)
+					return err
+				}
+				if err := file.Close(); err != nil {
+					os.Remove(filename)
+					return i18n.Errorf("erreur lors de la création du fichier : %w", err)
+				}
+				i18n.Printf("✅ Rapport de %s %s du %s au %s exporté dans %s\n", user.FirstName, user.LastName,
+					i18n.Date(first), i18n.Date(end.AddDate(0, 0, -1)), filename)
+				return nil
+			}
+		},
+	})
+}
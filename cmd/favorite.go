@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// defaultRecentFoods est le nombre d'aliments listés par recent sans --limit
+const defaultRecentFoods = 30
+
+func init() {
+	register(&Command{
+		Name:    "recent",
+		Usage:   "recent [--limit <n>]",
+		Summary: "Lister les aliments consommés récemment par l'utilisateur courant",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			limit := fs.Int("limit", defaultRecentFoods, "nombre maximal d'aliments")
+			return func([]string) error {
+				if *limit <= 0 {
+					return usageErrorf("l'option --limit doit être un entier positif")
+				}
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				recent, err := fdc.RecentFoods(user.ID, *limit)
+				if err != nil {
+					return err
+				}
+				return emit(listOf(recent, newFoodUsageOut), func() error {
+					if len(recent) == 0 {
+						i18n.Printf("Aucun aliment consommé par %s %s.\n", user.FirstName, user.LastName)
+						return nil
+					}
+					i18n.Printf("🕘 Aliments récents de %s %s :\n", user.FirstName, user.LastName)
+					printFoodUsage(&user, recent)
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "favorites",
+		Usage:   "favorites",
+		Summary: "Lister les aliments favoris de l'utilisateur courant",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func([]string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				favorites, err := fdc.FavoriteFoods(user.ID)
+				if err != nil {
+					return err
+				}
+				return emit(listOf(favorites, newFoodUsageOut), func() error {
+					if len(favorites) == 0 {
+						i18n.Printf("Aucun favori pour %s %s (gofit star <fdcId>).\n", user.FirstName, user.LastName)
+						return nil
+					}
+					i18n.Printf("⭐ Aliments favoris de %s %s :\n", user.FirstName, user.LastName)
+					printFoodUsage(&user, favorites)
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "star",
+		Usage:   "star <fdcId | aliment récent>",
+		Summary: "Mettre un aliment dans les favoris de l'utilisateur courant",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				usage, err := fdc.GetFoodUsage(user.ID)
+				if err != nil {
+					return err
+				}
+				id, err := usedFoodArg(0, args, usage)
+				if err != nil {
+					return err
+				}
+				food, err := fdc.StarFood(user.ID, id)
+				if err != nil {
+					return err
+				}
+				return emit(foodOut{food.FdcID, food.Name}, func() error {
+					i18n.Printf("⭐ %s (fdcId %d) ajouté aux favoris de %s %s\n", food.Name, food.FdcID, user.FirstName, user.LastName)
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:     "unstar",
+		Usage:    "unstar <fdcId | aliment favori>",
+		Summary:  "Retirer un aliment des favoris de l'utilisateur courant",
+		Session:  true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				usage, err := fdc.GetFoodUsage(user.ID)
+				if err != nil {
+					return err
+				}
+				id, err := usedFoodArg(0, args, usage)
+				if err != nil {
+					return err
+				}
+				if err := fdc.UnstarFood(user.ID, id); err != nil {
+					return err
+				}
+				i18n.Printf("✅ Aliment %d retiré des favoris de %s %s\n", id, user.FirstName, user.LastName)
+				return nil
+			}
+		},
+	})
+}
+
+// printFoodUsage affiche des aliments consommés, les favoris marqués d'une étoile
+func printFoodUsage(user *models.User, usage []fdc.FoodUsage) {
+	for _, u := range usage {
+		mark := "  "
+		if u.Favorite {
+			mark = "⭐"
+		}
+		if u.Uses == 0 {
+			i18n.Printf("%s %s (fdcId %d) | jamais consommé\n", mark, u.Name, u.FdcID)
+			continue
+		}
+		i18n.Printf("%s %s (fdcId %d) | %.0f g | %d fois | dernier le %s\n",
+			mark, u.Name, u.FdcID, u.LastQuantity, u.Uses, i18n.Date(u.LastUsed.In(user.Location())))
+	}
+}
+
+// usedFoods renvoie les aliments consommés ou favoris de l'utilisateur
+// courant ; nil sans utilisateur courant
+func usedFoods() ([]fdc.FoodUsage, error) {
+	user, ok, err := currentUser()
+	if err != nil || !ok {
+		return nil, err
+	}
+	return fdc.GetFoodUsage(user.ID)
+}
+
+// usedFoodArg lit l'aliment depuis --fdc ou les arguments positionnels : un
+// identifiant FDC, ou le nom d'un des aliments consommés ou favoris usage
+func usedFoodArg(flagValue int, args []string, usage []fdc.FoodUsage) (int, error) {
+	if flagValue > 0 || len(args) == 0 {
+		return fdcIDArg(flagValue, args)
+	}
+	if _, err := parseFdcID(args[0]); err == nil {
+		return fdcIDArg(flagValue, args)
+	}
+	u, err := matchUsedFood(usage, strings.Join(args, " "))
+	return u.FdcID, err
+}
+
+// matchUsedFood trouve parmi usage l'aliment nommé name, ou le seul dont le
+// nom contient name, sans tenir compte de la casse
+func matchUsedFood(usage []fdc.FoodUsage, name string) (fdc.FoodUsage, error) {
+	lower := strings.ToLower(name)
+	var found []fdc.FoodUsage
+	for _, u := range usage {
+		if strings.EqualFold(u.Name, name) {
+			return u, nil
+		}
+		if strings.Contains(strings.ToLower(u.Name), lower) {
+			found = append(found, u)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return fdc.FoodUsage{}, usageErrorf("aucun aliment récent ou favori ne correspond à « %s » : utilisez l'identifiant FDC (gofit search)", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, 0, 3)
+	for _, u := range found[:min(3, len(found))] {
+		names = append(names, i18n.Sprintf("%s (fdcId %d)", u.Name, u.FdcID))
+	}
+	return fdc.FoodUsage{}, usageErrorf("« %s » est ambigu (%s...) : précisez le nom ou l'identifiant FDC", name, strings.Join(names, ", "))
+}
+
+// findUsage renvoie l'utilisation de l'aliment fdcID parmi usage
+func findUsage(usage []fdc.FoodUsage, fdcID int) (fdc.FoodUsage, bool) {
+	for _, u := range usage {
+		if u.FdcID == fdcID {
+			return u, true
+		}
+	}
+	return fdc.FoodUsage{}, false
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+func init() {
+	register(&Command{
+		Name:    "search",
+		Usage:   "search <nom de l'aliment>",
+		Summary: "Rechercher un aliment dans la base FDC",
+		NoDB:    true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) == 0 {
+					return usageErrorf("le nom de l'aliment est attendu")
+				}
+				results, err := fdc.SearchFood(strings.Join(args, " "))
+				if err != nil {
+					return i18n.Errorf("erreur lors de la recherche : %w", err)
+				}
+				doc := listOf(results, func(r fdc.SearchResult) foodOut { return foodOut{r.FdcID, r.Description} })
+				return emit(doc, func() error {
+					if len(results) == 0 {
+						i18n.Println("Aucun résultat trouvé.")
+						return nil
+					}
+					i18n.Println("Résultats trouvés :")
+					for _, r := range results {
+						i18n.Printf("- %s (fdcId: %d)\n", r.Description, r.FdcID)
+					}
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "detail",
+		Usage:   "detail <fdcId> | detail --fdc <fdcId>",
+		Summary: "Voir les détails nutritionnels d'un aliment",
+		NoDB:    true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			fdcFlag := fs.Int("fdc", 0, "identifiant FDC de l'aliment")
+			return func(args []string) error {
+				id, err := fdcIDArg(*fdcFlag, args)
+				if err != nil {
+					return err
+				}
+				name, calories, proteins, carbs, lipids, err := fdc.GetFoodDetails(id)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la récupération : %w", err)
+				}
+				doc := foodDetailOut{id, name, 100, calories, proteins, carbs, lipids}
+				return emit(doc, func() error {
+					i18n.Println("Détails nutritionnels :")
+					i18n.Printf("Nom : %s\n", name)
+					i18n.Printf("Calories : %.2f kcal\n", calories)
+					i18n.Printf("Protéines : %.2f g\n", proteins)
+					i18n.Printf("Glucides : %.2f g\n", carbs)
+					i18n.Printf("Lipides : %.2f g\n", lipids)
+					i18n.Printf("Quantité : %.2f g\n", 100.0)
+					return nil
+				})
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "addfood",
+		Usage:   "addfood [--meal <id repas> | --type <type>] {--fdc <fdcId> | <aliment récent>} [--grams <quantité>]",
+		Summary: "Ajouter un aliment à un repas (dernier repas du jour courant par défaut)",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			mealID := fs.Uint("meal", 0, "identifiant du repas ; défaut : dernier repas de l'utilisateur et du jour courants")
+			mealType := fs.String("type", "", "type du repas du jour courant (breakfast, lunch, dinner, snack)")
+			fdcFlag := fs.Int("fdc", 0, "identifiant FDC de l'aliment")
+			grams := fs.Float64("grams", 0, "quantité en grammes ; défaut : dernière quantité consommée")
+			return func(args []string) error {
+				usage, err := usedFoods()
+				if err != nil {
+					return err
+				}
+				id, err := usedFoodArg(*fdcFlag, args, usage)
+				if err != nil {
+					return err
+				}
+				if *grams < 0 {
+					return usageErrorf("l'option --grams doit être un nombre positif")
+				}
+				if *grams == 0 {
+					u, _ := findUsage(usage, id)
+					if u.LastQuantity == 0 {
+						return usageErrorf("l'option --grams est attendue pour un aliment jamais consommé")
+					}
+					*grams = u.LastQuantity
+				}
+				if *mealType != "" {
+					if _, err := parseMealType(*mealType); err != nil {
+						return usageErrorf("--type : %v", err)
+					}
+				}
+				if *mealID == 0 {
+					user, err := requireUser("--meal")
+					if err != nil {
+						return err
+					}
+					meal, err := currentMeal(&user, *mealType)
+					if err != nil {
+						return err
+					}
+					*mealID = meal.ID
+				}
+				item, err := fdc.AddFoodToMeal(*mealID, id, *grams)
+				if err != nil {
+					return err
+				}
+				return emit(newItemOut(item), func() error {
+					i18n.Printf("✔ Aliment '%s' (%.0f g) ajouté au repas %d\n", item.Name, item.Quantity, item.MealID)
+					return nil
+				})
+			}
+		},
+		Wizard: addFoodWizard,
+	})
+}
+
+// food est un aliment FDC choisi dans un wizard
+type food struct {
+	id   int
+	name string
+}
+
+func lookupFood(s string) (any, error) {
+	id, err := parseFdcID(s)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fdc.GetFood(id)
+	if err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération de l'aliment : %w", err)
+	}
+	return food{id: id, name: f.Name}, nil
+}
+
+// maxQuickFoods borne le nombre d'aliments récents ou favoris proposés par addfood
+const maxQuickFoods = 10
+
+// quickFoodChoices propose les favoris puis les aliments récents, et en
+// dernier le choix d'un autre aliment par son identifiant FDC
+func quickFoodChoices(usage []fdc.FoodUsage) func(wizard.Answers) ([]wizard.Choice, error) {
+	return func(wizard.Answers) ([]wizard.Choice, error) {
+		var choices []wizard.Choice
+		for _, favorite := range []bool{true, false} {
+			for _, u := range usage {
+				if u.Favorite != favorite || len(choices) == maxQuickFoods {
+					continue
+				}
+				label := i18n.Sprintf("%s (fdcId %d)", u.Name, u.FdcID)
+				if u.Favorite {
+					label = "⭐ " + label
+				}
+				choices = append(choices, wizard.Choice{Label: label, Value: food{id: u.FdcID, name: u.Name}})
+			}
+		}
+		return append(choices, wizard.Choice{Label: i18n.T("Autre aliment (identifiant FDC)"), Value: food{}}), nil
+	}
+}
+
+func parseGrams(s string) (any, error) {
+	v, err := parsePositiveFloat(s)
+	if err != nil || v.(float64) == 0 {
+		return nil, i18n.Errorf("quantité invalide : %q n'est pas un nombre positif", s)
+	}
+	return v, nil
+}
+
+func addFoodWizard(in wizard.LineReader, args []string) error {
+	// Avec un utilisateur courant, on propose les repas de son jour courant,
+	// le dernier enregistré par défaut
+	var meals []models.Meal
+	defaultMeal := ""
+	current, ok, err := currentUser()
+	if err != nil {
+		return err
+	}
+	if ok {
+		if meals, _, err = currentMeals(&current); err != nil {
+			return err
+		}
+		if len(meals) > 0 {
+			defaultMeal = strconv.Itoa(latestMeal(meals, "") + 1)
+		}
+	}
+	if len(meals) == 0 {
+		if meals, err = fdc.GetMeals(); err != nil {
+			return i18n.Errorf("erreur lors de la récupération des repas : %w", err)
+		}
+		if len(meals) == 0 {
+			i18n.Println("Aucun repas n'a été créé. Veuillez d'abord créer un repas avec 'gofit newmeal'.")
+			return nil
+		}
+	}
+
+	var usage []fdc.FoodUsage
+	if ok {
+		if usage, err = fdc.GetFoodUsage(current.ID); err != nil {
+			return err
+		}
+	}
+
+	w := wizard.Wizard{Title: i18n.T("Ajout d'un aliment à un repas"), Confirm: true}
+	var selected food
+	if len(args) > 0 {
+		id, err := usedFoodArg(0, args, usage)
+		if err != nil {
+			return err
+		}
+		f, err := lookupFood(strconv.Itoa(id))
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		selected = f.(food)
+		i18n.Printf("\nAliment sélectionné : %s\n", selected.name)
+	} else {
+		// Les aliments récents et favoris se choisissent sans interroger FDC
+		if len(usage) > 0 {
+			w.Steps = append(w.Steps, wizard.Step{
+				Key:     "quick",
+				Prompt:  i18n.T("Choisissez un aliment récent ou favori :"),
+				Label:   i18n.T("Aliment récent ou favori"),
+				Choices: quickFoodChoices(usage),
+			})
+		}
+		w.Steps = append(w.Steps, wizard.Step{
+			Key:    "food",
+			Prompt: i18n.T("Identifiant FDC de l'aliment (voir 'gofit search') :"),
+			Label:  i18n.T("Aliment"),
+			Parse:  parser(lookupFood),
+			Display: func(v any) string {
+				f := v.(food)
+				return i18n.Sprintf("%s (fdcId %d)", f.name, f.id)
+			},
+			Skip: func(a wizard.Answers) bool {
+				f, ok := a["quick"].(food)
+				return ok && f.id != 0
+			},
+		})
+	}
+	// chosen renvoie l'aliment choisi, dans la liste rapide ou par son identifiant
+	chosen := func(a wizard.Answers) food {
+		if f, ok := a["quick"].(food); ok && f.id != 0 {
+			return f
+		}
+		if f, ok := a["food"].(food); ok {
+			return f
+		}
+		return selected
+	}
+	w.Steps = append(w.Steps,
+		wizard.Step{
+			Key:     "meal",
+			Prompt:  i18n.T("Choisissez le repas auquel ajouter cet aliment :"),
+			Label:   i18n.T("Repas"),
+			Choices: mealChoices(meals),
+			Default: func(wizard.Answers) string { return defaultMeal },
+		},
+		wizard.Step{
+			Key:    "grams",
+			Prompt: i18n.T("Quantité en grammes :"),
+			Label:  i18n.T("Quantité (g)"),
+			Parse:  parser(parseGrams),
+			// La dernière quantité consommée est proposée par défaut
+			Default: func(a wizard.Answers) string {
+				if u, ok := findUsage(usage, chosen(a).id); ok && u.LastQuantity > 0 {
+					return formatFloat(round2(u.LastQuantity))
+				}
+				return ""
+			},
+		},
+	)
+
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return err
+	}
+	selected = chosen(answers)
+	meal := answers["meal"].(models.Meal)
+	quantity := answers["grams"].(float64)
+
+	if _, err := fdc.AddFoodToMeal(meal.ID, selected.id, quantity); err != nil {
+		return i18n.Errorf("erreur lors de l'ajout de l'aliment au repas : %w", err)
+	}
+	i18n.Printf("\n✅ %.0fg de %s ajoutés au repas '%s'\n", quantity, selected.name, meal.Description)
+	return nil
+}
+
+// fdcIDArg lit l'identifiant FDC depuis --fdc ou, à défaut, le premier argument positionnel
+func fdcIDArg(flagValue int, args []string) (int, error) {
+	if flagValue > 0 {
+		return flagValue, nil
+	}
+	if len(args) == 0 {
+		return 0, usageErrorf("l'identifiant FDC est attendu")
+	}
+	id, err := parseFdcID(args[0])
+	if err != nil {
+		return 0, usageErrorf("%v", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+func init() {
+	register(&Command{
+		Name:     "help",
+		Usage:    "help [commande]",
+		Summary:  "Afficher l'aide générale ou celle d'une commande",
+		NoDB:     true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) == 0 {
+					printHelp(os.Stdout)
+					return nil
+				}
+				c, ok := Lookup(args[0])
+				if !ok {
+					return usageErrorf("commande inconnue : %s", args[0])
+				}
+				i18n.Println(c.Summary)
+				fs, _, _ := newFlagSet(c)
+				printUsage(os.Stdout, c, fs)
+				return nil
+			}
+		},
+	})
+}
+
+func printHelp(w io.Writer) {
+	i18n.Fprintln(w, "Usage : gofit <commande> [options]")
+	i18n.Fprintln(w, "        gofit repl    (mode interactif, aussi lancé sans argument)")
+	i18n.Fprintln(w, "\nCommandes :")
+	for _, c := range Commands() {
+		fmt.Fprintf(w, "  %-16s %s\n", c.Name, i18n.T(c.Summary))
+	}
+	i18n.Fprintln(w, "\nOptions communes : --output table|json|csv|yaml (format de sortie, table par défaut), --lang fr|en (langue)")
+	i18n.Fprintln(w, "\nCodes de sortie : 0 succès, 1 erreur, 2 utilisation incorrecte")
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/i18n"
+)
+
+func init() {
+	register(&Command{
+		Name:     "lang",
+		Usage:    "lang [fr|en]",
+		Summary:  "Afficher ou enregistrer la langue de l'interface",
+		NoDB:     true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) == 0 {
+					fmt.Println(i18n.Current())
+					return nil
+				}
+				if len(args) > 1 {
+					return usageErrorf("trop d'arguments")
+				}
+				locale, err := i18n.ParseLocale(args[0])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				cfg.Lang = string(locale)
+				if err := config.Save(cfg); err != nil {
+					return err
+				}
+				i18n.SetLocale(locale)
+				i18n.Printf("✅ Langue de l'interface : %s\n", locale)
+				return nil
+			}
+		},
+	})
+}
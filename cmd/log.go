@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/quicklog"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+// maxFoodChoices borne le nombre d'aliments proposés pour lever une ambiguïté
+const maxFoodChoices = 5
+
+func init() {
+	register(&Command{
+		Name:    "log",
+		Usage:   "log [--type <breakfast|lunch|dinner|snack>] <aliments, ex. 150g chicken breast, 1 cup rice for lunch>",
+		Summary: "Noter des aliments en langage naturel dans le menu du jour courant",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			mealType := fs.String("type", "", "type de repas, s'il n'est pas dans la saisie (breakfast, lunch, dinner, snack)")
+			return func(args []string) error {
+				// Depuis un terminal, les ambiguïtés sont levées par des questions
+				var in wizard.LineReader
+				if lineedit.IsTerminal(os.Stdin) {
+					in = &lineReader{r: bufio.NewReader(os.Stdin), out: os.Stdout}
+				}
+				return logFoods(in, strings.Join(args, " "), *mealType)
+			}
+		},
+		Wizard: logWizard,
+	})
+}
+
+// loggedFood est un aliment de la saisie, rapproché d'un aliment FDC
+type loggedFood struct {
+	entry   quicklog.Entry
+	results []fdc.SearchResult
+	// choice est l'indice de l'aliment retenu dans results, -1 s'il faut le demander
+	choice int
+	// grams est le poids de l'aliment, 0 s'il faut le demander
+	grams float64
+}
+
+func logWizard(in wizard.LineReader, args []string) error {
+	text := strings.Join(args, " ")
+	if text == "" {
+		w := wizard.Wizard{
+			Title: i18n.T("Saisie rapide"),
+			Steps: []wizard.Step{{
+				Key:    "text",
+				Prompt: i18n.T("Qu'avez-vous mangé ? (ex. 150g chicken breast, 1 cup rice for lunch)"),
+				Parse:  parser(parseNonEmpty),
+			}},
+		}
+		answers, err := w.Run(in, os.Stdout)
+		if err != nil {
+			return err
+		}
+		text = answers["text"].(string)
+	}
+	return logFoods(in, text, "")
+}
+
+// logFoods ajoute les aliments décrits par text au repas du type voulu dans
+// le menu du jour courant. Les questions ne sont posées que si un aliment est
+// ambigu ou son poids inconnu ; sans in, ces cas sont des erreurs.
+func logFoods(in wizard.LineReader, text, mealType string) error {
+	if strings.TrimSpace(text) == "" {
+		return usageErrorf("les aliments sont attendus, ex. « 150g chicken breast, 1 cup rice for lunch »")
+	}
+	parsed, err := quicklog.Parse(text)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	if mealType != "" {
+		t, err := parseMealType(mealType)
+		if err != nil {
+			return usageErrorf("--type : %v", err)
+		}
+		parsed.MealType = t.(models.MealType)
+	}
+
+	user, err := requireUser("--user")
+	if err != nil {
+		return err
+	}
+	if parsed.MealType == "" {
+		parsed.MealType = quicklog.MealTypeAt(user.Now())
+	}
+
+	foods, err := resolveFoods(in, &user, parsed.Entries)
+	if err != nil {
+		return err
+	}
+
+	menu, err := currentMenu(&user)
+	if err != nil {
+		return err
+	}
+	// Le repas manquant est créé avec les aliments, pour ne rien garder d'un
+	// ajout qui échoue
+	meal := fdc.NewMenuMeal(menu.ID, parsed.MealType)
+	if i := latestMeal(menu.Meals, string(parsed.MealType)); i >= 0 {
+		meal = menu.Meals[i]
+	}
+	quantities := make([]fdc.FoodQuantity, len(foods))
+	for i, f := range foods {
+		quantities[i] = fdc.FoodQuantity{FdcID: f.results[f.choice].FdcID, Quantity: f.grams}
+	}
+	meal, items, err := fdc.AddFoodsToMeal(meal, quantities)
+	if err != nil {
+		return err
+	}
+
+	return emit(listOf(items, newItemOut), func() error {
+		total := 0.0
+		for _, item := range items {
+			i18n.Printf("✔ %.0f g de %s (%.0f kcal)\n", item.Quantity, item.Name, item.Calories)
+			total += item.Calories
+		}
+		i18n.Printf("✅ %d aliment(s) ajouté(s) au repas %s du %s (id %d) : %.0f kcal\n",
+			len(items), meal.Type, i18n.Date(menu.Date), meal.ID, total)
+		return nil
+	})
+}
+
+// resolveFoods cherche chaque aliment dans FDC. Un résultat est retenu
+// d'office s'il est le seul, s'il porte exactement le nom saisi ou si
+// l'utilisateur l'a déjà mangé ou mis en favori ; sinon la question est posée.
+func resolveFoods(in wizard.LineReader, user *models.User, entries []quicklog.Entry) ([]loggedFood, error) {
+	usage, err := fdc.GetFoodUsage(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	known := map[int]bool{}
+	for _, u := range usage {
+		known[u.FdcID] = true
+	}
+
+	foods := make([]loggedFood, len(entries))
+	portions := map[int][]fdc.Portion{}
+	w := wizard.Wizard{Title: i18n.T("Quelques précisions")}
+	for i, entry := range entries {
+		results, err := fdc.SearchFood(entry.Food)
+		if err != nil {
+			return nil, i18n.Errorf("erreur lors de la recherche : %w", err)
+		}
+		if len(results) == 0 {
+			return nil, usageErrorf("aucun aliment trouvé pour « %s »", entry.Food)
+		}
+		if len(results) > maxFoodChoices {
+			results = results[:maxFoodChoices]
+		}
+		f := &foods[i]
+		*f = loggedFood{entry: entry, results: results, choice: pickFood(results, entry.Food, known)}
+		key := strconv.Itoa(i)
+
+		if f.choice < 0 {
+			if in == nil {
+				return nil, usageErrorf("« %s » est ambigu (%s...) : précisez le nom ou utilisez gofit addfood --fdc",
+					entry.Food, describeResults(results[:min(3, len(results))]))
+			}
+			w.Steps = append(w.Steps, wizard.Step{
+				Key:     "food" + key,
+				Prompt:  i18n.Sprintf("Quel aliment correspond à « %s » ?", entry.Text),
+				Label:   entry.Text,
+				Choices: resultChoices(results),
+			})
+		}
+
+		if grams, ok := entry.Grams(); ok {
+			f.grams = grams
+			continue
+		}
+		// Le poids d'une mesure ménagère dépend de l'aliment : il n'est
+		// demandé que si FDC ne le donne pas
+		if f.choice >= 0 {
+			grams, err := portionGrams(entry, results[f.choice].FdcID, portions)
+			if err != nil {
+				return nil, err
+			}
+			if f.grams = grams; grams > 0 {
+				continue
+			}
+			if in == nil {
+				return nil, usageErrorf("poids inconnu pour « %s » : précisez-le en grammes", entry.Text)
+			}
+		}
+		step := wizard.Step{
+			Key:    "grams" + key,
+			Prompt: i18n.Sprintf("Quantité en grammes pour « %s » :", entry.Text),
+			Label:  i18n.Sprintf("Quantité (g) de « %s »", entry.Text),
+			Parse:  parser(parseGrams),
+		}
+		if f.choice < 0 {
+			// Une fois l'aliment choisi, ses portions suffisent peut-être ;
+			// une erreur de l'API revient à demander le poids
+			step.Skip = func(a wizard.Answers) bool {
+				f.grams, _ = portionGrams(entry, a["food"+key].(fdc.SearchResult).FdcID, portions)
+				return f.grams > 0
+			}
+		}
+		w.Steps = append(w.Steps, step)
+	}
+	if len(w.Steps) == 0 {
+		return foods, nil
+	}
+
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	for i := range foods {
+		f, key := &foods[i], strconv.Itoa(i)
+		if f.choice < 0 {
+			f.choice = indexOf(f.results, answers["food"+key].(fdc.SearchResult))
+		}
+		if f.grams == 0 {
+			f.grams = answers["grams"+key].(float64)
+		}
+	}
+	return foods, nil
+}
+
+// pickFood renvoie l'indice du résultat à retenir sans poser de question, ou -1
+func pickFood(results []fdc.SearchResult, name string, known map[int]bool) int {
+	if len(results) == 1 {
+		return 0
+	}
+	for i, r := range results {
+		if strings.EqualFold(r.Description, name) {
+			return i
+		}
+	}
+	for i, r := range results {
+		if known[r.FdcID] {
+			return i
+		}
+	}
+	return -1
+}
+
+// portionWords sont les mots qui désignent chaque unité dans les portions FDC
+var portionWords = map[quicklog.Unit][]string{
+	quicklog.Cup:        {"cup"},
+	quicklog.Tablespoon: {"tbsp", "tablespoon"},
+	quicklog.Teaspoon:   {"tsp", "teaspoon"},
+	quicklog.Slice:      {"slice"},
+	quicklog.Serving:    {"serving"},
+	quicklog.Piece:      {"piece", "medium", "large", "small", "whole", "each", "unit", "item"},
+}
+
+// portionGrams convertit une mesure ménagère en grammes avec les portions
+// FDC de l'aliment, mises en cache dans cache ; 0 si elles ne suffisent pas
+func portionGrams(entry quicklog.Entry, fdcID int, cache map[int][]fdc.Portion) (float64, error) {
+	portions, ok := cache[fdcID]
+	if !ok {
+		var err error
+		if portions, err = fdc.GetFoodPortions(fdcID); err != nil {
+			return 0, i18n.Errorf("erreur lors de la récupération de l'aliment : %w", err)
+		}
+		cache[fdcID] = portions
+	}
+	for _, word := range portionWords[entry.Unit] {
+		for _, p := range portions {
+			if strings.Contains(strings.ToLower(p.Description), word) {
+				return entry.Amount * p.GramWeight / p.Amount, nil
+			}
+		}
+	}
+	if ml, ok := quicklog.Milliliters[entry.Unit]; ok {
+		return entry.Amount * ml, nil
+	}
+	if (entry.Unit == quicklog.Piece || entry.Unit == quicklog.Serving) && len(portions) > 0 {
+		return entry.Amount * portions[0].GramWeight / portions[0].Amount, nil
+	}
+	return 0, nil
+}
+
+func resultChoices(results []fdc.SearchResult) func(wizard.Answers) ([]wizard.Choice, error) {
+	return func(wizard.Answers) ([]wizard.Choice, error) {
+		choices := make([]wizard.Choice, len(results))
+		for i, r := range results {
+			choices[i] = wizard.Choice{Label: i18n.Sprintf("%s (fdcId %d)", r.Description, r.FdcID), Value: r}
+		}
+		return choices, nil
+	}
+}
+
+func describeResults(results []fdc.SearchResult) string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = i18n.Sprintf("%s (fdcId %d)", r.Description, r.FdcID)
+	}
+	return strings.Join(names, ", ")
+}
+
+func indexOf(results []fdc.SearchResult, r fdc.SearchResult) int {
+	for i := range results {
+		if results[i].FdcID == r.FdcID {
+			return i
+		}
+	}
+	return 0
+}
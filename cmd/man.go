@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+func init() {
+	register(&Command{
+		Name:     "man",
+		Usage:    "man [--dir <dossier>]",
+		Summary:  "Générer les pages de manuel (gofit.1 et une page par commande)",
+		NoDB:     true,
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			dir := fs.String("dir", ".", "dossier où écrire les pages")
+			return func(args []string) error {
+				if len(args) != 0 {
+					return usageErrorf("trop d'arguments")
+				}
+				if err := os.MkdirAll(*dir, 0o755); err != nil {
+					return i18n.Errorf("erreur lors de l'écriture des pages de manuel : %w", err)
+				}
+				pages := map[string][]byte{"gofit.1": mainManPage()}
+				for _, c := range Commands() {
+					pages["gofit-"+c.Name+".1"] = commandManPage(c)
+				}
+				for name, page := range pages {
+					if err := os.WriteFile(filepath.Join(*dir, name), page, 0o644); err != nil {
+						return i18n.Errorf("erreur lors de l'écriture des pages de manuel : %w", err)
+					}
+				}
+				i18n.Printf("✅ %d pages de manuel écrites dans %s\n", len(pages), *dir)
+				return nil
+			}
+		},
+	})
+}
+
+// manPage écrit une page de manuel au format roff
+type manPage struct {
+	bytes.Buffer
+}
+
+func newManPage(title string) *manPage {
+	p := &manPage{}
+	fmt.Fprintf(p, ".TH %s 1 \"\" \"gofit\" \"%s\"\n", strings.ToUpper(title), roff(i18n.T("Manuel de gofit")))
+	return p
+}
+
+// section ouvre une section ; son titre est traduit
+func (p *manPage) section(title string) {
+	fmt.Fprintf(p, ".SH %s\n", roff(i18n.T(title)))
+}
+
+// text écrit un paragraphe déjà traduit
+func (p *manPage) text(s string) {
+	fmt.Fprintf(p, "%s\n", roff(s))
+}
+
+// item écrit un terme en gras suivi de sa description
+func (p *manPage) item(term, description string) {
+	fmt.Fprintf(p, ".TP\n.B %s\n%s\n", roff(term), roff(description))
+}
+
+// roff protège le texte : barres obliques inverses, tirets et points en début de ligne
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func mainManPage() []byte {
+	p := newManPage("gofit")
+	p.section("NOM")
+	p.text("gofit - " + i18n.T("assistant de suivi nutritionnel"))
+	p.section("SYNOPSIS")
+	p.text("gofit " + i18n.T("<commande> [options]"))
+	p.WriteString(".br\n")
+	p.text("gofit repl")
+	p.section("DESCRIPTION")
+	p.text(i18n.T("GoFit suit l'alimentation quotidienne à partir de la base FoodData Central. Chaque commande s'exécute puis se termine ; sans argument ou avec repl, gofit lance la boucle interactive."))
+	p.section("COMMANDES")
+	for _, c := range Commands() {
+		p.item(c.Name, i18n.T(c.Summary))
+	}
+	p.section("OPTIONS COMMUNES")
+	p.item("--output table|json|csv|yaml", i18n.T("format de sortie : table, json, csv ou yaml"))
+	p.item("--lang fr|en", i18n.T("langue de l'interface : fr ou en"))
+	writeExitStatus(p)
+	p.section("ENVIRONNEMENT")
+	p.item("FDC_API_KEY", i18n.T("clé de l'API FoodData Central"))
+	p.item("GOFIT_LANG", i18n.T("langue de l'interface, prioritaire sur le fichier de configuration"))
+	p.item("GOFIT_CONFIG", i18n.T("chemin du fichier de configuration"))
+	p.item("GOFIT_ACTOR", i18n.T("auteur des modifications inscrit dans le journal d'audit"))
+	p.section("VOIR AUSSI")
+	var refs []string
+	for _, c := range Commands() {
+		refs = append(refs, `\fBgofit-`+roff(c.Name)+`\fR(1)`)
+	}
+	p.WriteString(strings.Join(refs, ",\n") + "\n")
+	return p.Bytes()
+}
+
+func commandManPage(c *Command) []byte {
+	p := newManPage("gofit-" + c.Name)
+	p.section("NOM")
+	p.text("gofit-" + c.Name + " - " + i18n.T(c.Summary))
+	p.section("SYNOPSIS")
+	usage := "gofit " + c.Name
+	if args := strings.TrimSpace(strings.TrimPrefix(c.Usage, c.Name)); args != "" {
+		usage += " " + i18n.T(args)
+	}
+	p.text(usage)
+	p.section("OPTIONS")
+	fs, _, _ := newFlagSet(c)
+	fs.VisitAll(func(f *flag.Flag) {
+		p.item("--"+f.Name, i18n.T(f.Usage))
+	})
+	writeExitStatus(p)
+	p.section("VOIR AUSSI")
+	p.WriteString(`\fBgofit\fR(1)` + "\n")
+	return p.Bytes()
+}
+
+func writeExitStatus(p *manPage) {
+	p.section("CODES DE SORTIE")
+	p.item("0", i18n.T("succès"))
+	p.item("1", i18n.T("erreur d'exécution"))
+	p.item("2", i18n.T("arguments invalides"))
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+func init() {
+	register(&Command{
+		Name:    "newmeal",
+		Usage:   "newmeal --type <breakfast|lunch|dinner|snack> --description <texte>",
+		Summary: "Créer un nouveau repas type",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			mealType := fs.String("type", "", "type de repas (breakfast, lunch, dinner, snack)")
+			description := fs.String("description", "", "description du repas")
+			return func(args []string) error {
+				t, err := parseMealType(*mealType)
+				if err != nil {
+					return usageErrorf("--type : %v", err)
+				}
+				desc := *description
+				if desc == "" {
+					desc = strings.Join(args, " ")
+				}
+				if strings.TrimSpace(desc) == "" {
+					return usageErrorf("l'option --description est obligatoire")
+				}
+
+				meal, err := fdc.AddMeal(models.Meal{Type: t.(models.MealType), Description: desc})
+				if err != nil {
+					return i18n.Errorf("erreur lors de la sauvegarde du repas : %w", err)
+				}
+				return emit(newMealOut(meal), func() error {
+					i18n.Printf("✔ Repas '%s' (%s) ajouté avec succès ! (id %d)\n", desc, meal.Type, meal.ID)
+					return nil
+				})
+			}
+		},
+		Wizard: newMealWizard,
+	})
+
+	register(&Command{
+		Name:    "addmeal",
+		Usage:   "addmeal [--menu <id menu>] --meal <id repas type>",
+		Summary: "Ajouter un repas type à un menu journalier (menu du jour courant par défaut)",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			menuID := fs.Uint("menu", 0, "identifiant du menu journalier ; défaut : menu de l'utilisateur et du jour courants")
+			mealID := fs.Uint("meal", 0, "identifiant du repas type")
+			return func(args []string) error {
+				if *mealID == 0 {
+					return usageErrorf("l'option --meal est obligatoire")
+				}
+				meal, err := fdc.GetMeal(*mealID)
+				if err != nil {
+					return err
+				}
+				if meal.DailyMenuID != nil {
+					return usageErrorf("le repas %d n'est pas un repas type", *mealID)
+				}
+				if *menuID == 0 {
+					user, err := requireUser("--menu")
+					if err != nil {
+						return err
+					}
+					menu, err := currentMenu(&user)
+					if err != nil {
+						return err
+					}
+					*menuID = menu.ID
+				}
+				added, err := fdc.AddMealToDailyMenu(*menuID, meal.ID)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la création du repas : %w", err)
+				}
+				return emit(newMealOut(added), func() error {
+					i18n.Printf("✅ Repas '%s' (%s) ajouté au menu %d (id %d)\n", added.Description, added.Type, *menuID, added.ID)
+					return nil
+				})
+			}
+		},
+		Wizard: addMealWizard,
+	})
+}
+
+// mealChoices propose les repas types
+func mealChoices(meals []models.Meal) func(wizard.Answers) ([]wizard.Choice, error) {
+	return func(wizard.Answers) ([]wizard.Choice, error) {
+		choices := make([]wizard.Choice, len(meals))
+		for i, meal := range meals {
+			choices[i] = wizard.Choice{Label: i18n.Sprintf("%s (%s)", meal.Description, meal.Type), Value: meal}
+		}
+		return choices, nil
+	}
+}
+
+func newMealWizard(in wizard.LineReader, args []string) error {
+	types := []models.MealType{models.Breakfast, models.Lunch, models.Dinner, models.Snack}
+	typeChoices := make([]wizard.Choice, len(types))
+	for i, t := range types {
+		typeChoices[i] = wizard.Choice{Label: string(t), Value: t}
+	}
+
+	w := wizard.Wizard{
+		Title: i18n.T("Création d'un repas type"),
+		Steps: []wizard.Step{
+			{
+				Key:     "type",
+				Prompt:  i18n.T("Quel type de repas souhaitez-vous ajouter ?"),
+				Label:   i18n.T("Type"),
+				Choices: func(wizard.Answers) ([]wizard.Choice, error) { return typeChoices, nil },
+			},
+			{
+				Key:    "description",
+				Prompt: i18n.T("Description du repas (ex : \"Déjeuner du mardi\") :"),
+				Label:  i18n.T("Description"),
+				Parse:  parser(parseNonEmpty),
+			},
+		},
+		Confirm: true,
+	}
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	meal := models.Meal{
+		Type:        answers["type"].(models.MealType),
+		Description: answers["description"].(string),
+	}
+	if _, err := fdc.AddMeal(meal); err != nil {
+		return i18n.Errorf("erreur lors de la sauvegarde du repas : %w", err)
+	}
+	i18n.Printf("✔ Repas '%s' (%s) ajouté avec succès !\n", meal.Description, meal.Type)
+	return nil
+}
+
+func addMealWizard(in wizard.LineReader, args []string) error {
+	meals, err := fdc.GetMeals()
+	if err != nil {
+		return i18n.Errorf("erreur lors de la récupération des repas : %w", err)
+	}
+	if len(meals) == 0 {
+		i18n.Println("Aucun repas type enregistré. Veuillez d'abord créer un repas avec 'gofit newmeal'.")
+		return nil
+	}
+	// Avec un utilisateur courant, le repas va dans son menu du jour courant
+	current, hasCurrent, err := currentUser()
+	if err != nil {
+		return err
+	}
+	menus, err := fdc.GetDailyMenus()
+	if err != nil {
+		return i18n.Errorf("erreur lors de la récupération des menus : %w", err)
+	}
+	if len(menus) == 0 && !hasCurrent {
+		i18n.Println("Aucun menu journalier enregistré. Veuillez d'abord créer un menu avec 'gofit addmenu'.")
+		return nil
+	}
+
+	w := wizard.Wizard{
+		Title: i18n.T("Ajout d'un repas type à un menu journalier"),
+		Steps: []wizard.Step{
+			{
+				Key:    "menu",
+				Prompt: i18n.T("Choisissez le menu auquel ajouter ce repas :"),
+				Label:  i18n.T("Menu"),
+				Skip:   func(wizard.Answers) bool { return hasCurrent },
+				Choices: func(wizard.Answers) ([]wizard.Choice, error) {
+					choices := make([]wizard.Choice, len(menus))
+					for i, menu := range menus {
+						label := i18n.Sprintf("%s %s - %s", menu.User.FirstName, menu.User.LastName, i18n.Date(menu.Date))
+						choices[i] = wizard.Choice{Label: label, Value: menu}
+					}
+					return choices, nil
+				},
+			},
+			{
+				Key:     "meal",
+				Prompt:  i18n.T("Choisissez un repas type :"),
+				Label:   i18n.T("Repas"),
+				Choices: mealChoices(meals),
+			},
+		},
+		Confirm: true,
+	}
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	var menu models.DailyMenu
+	if hasCurrent {
+		if menu, err = currentMenu(&current); err != nil {
+			return err
+		}
+	} else {
+		menu = answers["menu"].(models.DailyMenu)
+	}
+	meal := answers["meal"].(models.Meal)
+	if _, err := fdc.AddMealToDailyMenu(menu.ID, meal.ID); err != nil {
+		return i18n.Errorf("erreur lors de la création du repas : %w", err)
+	}
+	i18n.Printf("\n✅ Repas '%s' (%s) ajouté au menu de %s %s le %s\n",
+		meal.Description, meal.Type, menu.User.FirstName, menu.User.LastName, i18n.Date(menu.Date))
+	return nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+func init() {
+	register(&Command{
+		Name:    "addmenu",
+		Usage:   "addmenu [--user <utilisateur>] [--date JJ/MM/AAAA]",
+		Summary: "Créer le menu journalier d'un utilisateur (jour courant par défaut)",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				day, err := currentDay(&user)
+				if err != nil {
+					return err
+				}
+
+				menu, created, err := fdc.GetOrCreateDailyMenu(user.ID, day)
+				if err != nil {
+					return i18n.Errorf("erreur lors de la création du menu : %w", err)
+				}
+				menu.User = user
+				return emit(addMenuOut{newMenuOut(menu), created}, func() error {
+					printMenuCreated(user, menu, created)
+					return nil
+				})
+			}
+		},
+		Wizard: addMenuWizard,
+	})
+
+	register(&Command{
+		Name:    "mergemenus",
+		Usage:   "mergemenus",
+		Summary: "Fusionner les menus en double (même utilisateur, même jour)",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func([]string) error {
+				merged, err := fdc.MergeDuplicateDailyMenus()
+				if err != nil {
+					return i18n.Errorf("erreur lors de la fusion des menus : %w", err)
+				}
+				return emit(mergeOut{merged}, func() error {
+					if merged == 0 {
+						i18n.Println("Aucun menu en double.")
+						return nil
+					}
+					i18n.Printf("✅ %d menu(s) en double fusionné(s)\n", merged)
+					return nil
+				})
+			}
+		},
+	})
+}
+
+func addMenuWizard(in wizard.LineReader, args []string) error {
+	// Avec un utilisateur courant, il n'y a plus rien à demander
+	current, ok, err := currentUser()
+	if err != nil {
+		return err
+	}
+	if ok {
+		day, err := currentDay(&current)
+		if err != nil {
+			return err
+		}
+		menu, created, err := fdc.GetOrCreateDailyMenu(current.ID, day)
+		if err != nil {
+			return i18n.Errorf("erreur lors de la création du menu : %w", err)
+		}
+		printMenuCreated(current, menu, created)
+		return nil
+	}
+
+	users, err := fdc.GetUsers()
+	if err != nil {
+		return i18n.Errorf("erreur lors de la récupération des utilisateurs : %w", err)
+	}
+	if len(users) == 0 {
+		i18n.Println("Aucun utilisateur enregistré. Veuillez d'abord créer un utilisateur.")
+		return nil
+	}
+
+	w := wizard.Wizard{
+		Title: i18n.T("Création d'un menu journalier"),
+		Steps: []wizard.Step{
+			{
+				Key:    "user",
+				Prompt: i18n.T("Choisissez l'utilisateur pour ce menu :"),
+				Label:  i18n.T("Utilisateur"),
+				Choices: func(wizard.Answers) ([]wizard.Choice, error) {
+					choices := make([]wizard.Choice, len(users))
+					for i := range users {
+						u := &users[i]
+						choices[i] = wizard.Choice{Label: u.FirstName + " " + u.LastName, Value: u}
+					}
+					return choices, nil
+				},
+			},
+			{
+				Key:    "date",
+				Prompt: i18n.T("Date du menu (JJ/MM/AAAA) :"),
+				Label:  i18n.T("Date"),
+				Default: func(a wizard.Answers) string {
+					return i18n.Date(a["user"].(*models.User).Now())
+				},
+				Parse: func(input string, a wizard.Answers) (any, error) {
+					day, err := a["user"].(*models.User).ParseDay(i18n.DateLayout(), input)
+					if err != nil {
+						return nil, i18n.Errorf("format de date invalide : utilisez le format JJ/MM/AAAA")
+					}
+					return day, nil
+				},
+			},
+		},
+		Confirm: true,
+	}
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	user := answers["user"].(*models.User)
+	menu, created, err := fdc.GetOrCreateDailyMenu(user.ID, answers["date"].(time.Time))
+	if err != nil {
+		return i18n.Errorf("erreur lors de la création du menu : %w", err)
+	}
+	fmt.Println()
+	printMenuCreated(*user, menu, created)
+	return nil
+}
+
+func printMenuCreated(user models.User, menu models.DailyMenu, created bool) {
+	if !created {
+		i18n.Printf("ℹ️ %s %s a déjà un menu le %s (id %d)\n",
+			user.FirstName, user.LastName, i18n.Date(menu.Date), menu.ID)
+		return
+	}
+	i18n.Printf("✅ Menu journalier créé pour %s %s le %s (id %d)\n",
+		user.FirstName, user.LastName, i18n.Date(menu.Date), menu.ID)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/lsoulet/gofit/fdc"
+)
+
+func init() {
+	register(&Command{
+		Name:    "micronutrients",
+		Usage:   "micronutrients [--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--detail]",
+		Summary: "Comparer les apports en vitamines et minéraux aux apports de référence de l'âge et du sexe de l'utilisateur",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA (7 jours jusqu'à --to par défaut)")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus, jour courant par défaut)")
+			detail := fs.Bool("detail", false, "afficher les apports de chaque jour")
+			return func([]string) error {
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				first, end, err := recentPeriod(&user, *from, *to, defaultAdherenceDays)
+				if err != nil {
+					return err
+				}
+				report, err := fdc.GetMicronutrients(user.ID, first, end)
+				if err != nil {
+					return err
+				}
+				return emit(newMicronutrientsOut(report), func() error {
+					fdc.PrintMicronutrients(report, *detail, colorOutput())
+					return nil
+				})
+			}
+		},
+	})
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+func parseID(s string) (uint, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, i18n.Errorf("identifiant invalide : %s", s)
+	}
+	return uint(id), nil
+}
+
+func parseNonEmpty(s string) (any, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, i18n.Errorf("la valeur ne peut pas être vide")
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func parsePositiveInt(s string) (any, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v <= 0 {
+		return nil, i18n.Errorf("%q n'est pas un entier positif", s)
+	}
+	return v, nil
+}
+
+func parsePositiveFloat(s string) (any, error) {
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || v < 0 {
+		return nil, i18n.Errorf("%q n'est pas un nombre positif", s)
+	}
+	return v, nil
+}
+
+func parseGender(s string) (any, error) {
+	switch strings.ToLower(s) {
+	case "homme", string(models.Male):
+		return models.Male, nil
+	case "femme", string(models.Female):
+		return models.Female, nil
+	}
+	return nil, i18n.Errorf("%q : utilisez homme ou femme", s)
+}
+
+func parseGoal(s string) (any, error) {
+	switch g := models.Goal(s); g {
+	case models.WeightLoss, models.Maintenance, models.MuscleGain:
+		return g, nil
+	}
+	return nil, i18n.Errorf("%q : utilisez %s, %s ou %s", s, models.WeightLoss, models.Maintenance, models.MuscleGain)
+}
+
+func parseMealType(s string) (any, error) {
+	switch t := models.MealType(s); t {
+	case models.Breakfast, models.Lunch, models.Dinner, models.Snack:
+		return t, nil
+	}
+	return nil, i18n.Errorf("%q : utilisez %s, %s, %s ou %s", s, models.Breakfast, models.Lunch, models.Dinner, models.Snack)
+}
+
+func parsePeriod(s string) (fdc.Period, error) {
+	switch p := fdc.Period(s); p {
+	case fdc.PeriodWeek, fdc.PeriodMonth:
+		return p, nil
+	}
+	return "", i18n.Errorf("%q : utilisez %s ou %s", s, fdc.PeriodWeek, fdc.PeriodMonth)
+}
+
+func parseTimeZone(s string) (any, error) {
+	if _, err := time.LoadLocation(s); err != nil || s == "" {
+		return nil, i18n.Errorf("fuseau horaire inconnu : %q", s)
+	}
+	return s, nil
+}
+
+// parseDate lit une date JJ/MM/AAAA, ou AAAA-MM-JJ dans toutes les langues ;
+// le résultat désigne le jour calendaire saisi
+func parseDate(s string) (time.Time, error) {
+	date, err := time.Parse(i18n.DateLayout(), s)
+	if err != nil {
+		if date, err = time.Parse(config.DateLayout, s); err != nil {
+			return time.Time{}, i18n.Errorf("date invalide : %q (format JJ/MM/AAAA)", s)
+		}
+	}
+	return date, nil
+}
+
+func parseFdcID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, i18n.Errorf("fdcId invalide : %s", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+// replReader lit les lignes de la boucle interactive et des wizards, qui
+// partagent le même lecteur ; seules les commandes vont dans l'historique
+type replReader interface {
+	wizard.LineReader
+	AddHistory(line string)
+}
+
+// lineReader lit les lignes d'une entrée qui n'est pas un terminal (fichier,
+// tube) : ni édition, ni historique
+type lineReader struct {
+	r   *bufio.Reader
+	out io.Writer
+}
+
+func (l *lineReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(l.out, prompt)
+	line, err := l.r.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (l *lineReader) AddHistory(string) {}
+
+// wizardReader fait d'un Ctrl-C pendant un wizard une annulation
+type wizardReader struct {
+	wizard.LineReader
+}
+
+func (r wizardReader) ReadLine(prompt string) (string, error) {
+	line, err := r.LineReader.ReadLine(prompt)
+	if errors.Is(err, lineedit.ErrInterrupted) {
+		err = io.EOF
+	}
+	return line, err
+}
+
+// REPL exécute la boucle interactive : chaque ligne « gofit <commande> ... »
+// est exécutée jusqu'au bout avant de lire la suivante. Sur un terminal, la
+// ligne est éditable, l'historique est conservé entre les sessions et Tab
+// complète commandes et arguments. La boucle se termine sur « exit » ou à la
+// fin de l'entrée.
+func REPL(in io.Reader, out io.Writer) error {
+	detectLocale()
+	var lr replReader = &lineReader{r: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok && lineedit.IsTerminal(f) {
+		editor := lineedit.New(f, out)
+		editor.Complete = completeLine
+		if path, err := config.HistoryPath(); err == nil {
+			if err := editor.LoadHistory(path); err != nil {
+				i18n.Fprintf(out, "Erreur : %v\n", err)
+			}
+			defer func() {
+				if err := editor.SaveHistory(path); err != nil {
+					i18n.Fprintf(out, "Erreur : %v\n", err)
+				}
+			}()
+		}
+		lr = editor
+	}
+
+	i18n.Fprintln(out, "Tapez « help » pour la liste des commandes, « exit » pour quitter.")
+	for {
+		line, err := lr.ReadLine("> ")
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(out)
+			return nil
+		}
+		if err != nil {
+			return i18n.Errorf("erreur lors de la lecture : %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lr.AddHistory(line)
+		if !execLine(lr, line, out) {
+			return nil
+		}
+	}
+}
+
+// execLine exécute une ligne de la boucle interactive et renvoie false quand
+// elle demande à quitter. « help » et « exit » s'utilisent avec ou sans « gofit ».
+func execLine(lr wizard.LineReader, line string, out io.Writer) bool {
+	parts, err := SplitLine(line)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return true
+	}
+	switch parts[0] {
+	case "help", "exit", "quit":
+		parts = append([]string{"gofit"}, parts...)
+	}
+	if parts[0] != "gofit" {
+		i18n.Fprintln(out, "Toutes les commandes doivent commencer par 'gofit'")
+		return true
+	}
+	if len(parts) < 2 {
+		i18n.Fprintln(out, "Commande incomplète.")
+		return true
+	}
+	if parts[1] == "exit" || parts[1] == "quit" {
+		return false
+	}
+
+	c, ok := Lookup(parts[1])
+	if !ok {
+		i18n.Fprintf(out, "Commande inconnue : %s\n", parts[1])
+		return true
+	}
+	args := parts[2:]
+	if c.Wizard == nil || hasFlags(args) {
+		Run(c, args, out)
+		return true
+	}
+
+	fdc.SetAuditCommand(strings.TrimSpace("gofit " + c.Name + " " + strings.Join(args, " ")))
+	err = c.Wizard(wizardReader{lr}, args)
+	var usageErr *UsageError
+	switch {
+	case errors.Is(err, wizard.ErrCancelled):
+		i18n.Fprintln(out, "Opération annulée.")
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(out, usageErr.msg)
+		printUsage(out, c, nil)
+	case err != nil:
+		i18n.Fprintf(out, "Erreur : %v\n", err)
+	}
+	return true
+}
+
+func hasFlags(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// parser adapte une fonction de parse.go à une question de wizard
+func parser(parse func(string) (any, error)) func(string, wizard.Answers) (any, error) {
+	return func(input string, _ wizard.Answers) (any, error) {
+		return parse(input)
+	}
+}
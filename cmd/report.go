@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"flag"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/output"
+)
+
+func init() {
+	register(&Command{
+		Name:    "report",
+		Usage:   "report [--user <utilisateur>] [--date JJ/MM/AAAA | --from JJ/MM/AAAA --to JJ/MM/AAAA] [--meal-type <type>] [--by week|month] [--trend] [--all]",
+		Summary: "Générer un rapport nutritionnel (utilisateur et jour courants, ou tous les repas)",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			all := fs.Bool("all", false, "tous les utilisateurs et tous les jours, même avec un utilisateur courant")
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus)")
+			mealType := fs.String("meal-type", "", "ne compter que les repas de ce type (breakfast, lunch, dinner, snack)")
+			by := fs.String("by", "", "regrouper les jours par semaine (week) ou par mois (month)")
+			trend := fs.Bool("trend", false, "ajouter les sparklines des apports de chaque utilisateur sur la période")
+			return func([]string) error {
+				filter, err := reportFilter(*all, *from, *to, *mealType)
+				if err != nil {
+					return err
+				}
+				if *by != "" {
+					period, err := parsePeriod(*by)
+					if err != nil {
+						return usageErrorf("--by : %v", err)
+					}
+					return periodReport(periodFilter(filter, period, *from != "" || *to != ""), period)
+				}
+				if outputFormat != output.Table {
+					meals, err := fdc.GetMeals()
+					if err != nil {
+						return err
+					}
+					report, err := fdc.GetNutritionalReport(filter)
+					if err != nil {
+						return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+					}
+					return emit(reportOut{listOf(meals, newMealOut), listOf(report, newDayOut),
+						listOf(fdc.SummarizeByUser(report), newUserSummaryOut)}, nil)
+				}
+
+				// Le rapport d'un seul jour n'a pas besoin de la liste des repas types
+				if filter.UserID == 0 {
+					if err := fdc.ListMeals(); err != nil {
+						return err
+					}
+				}
+				i18n.Println("Génération du bilan nutritionnel journalier...")
+				if err := fdc.GenerateNutritionalReport(filter); err != nil {
+					return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+				}
+				if *trend {
+					report, err := fdc.GetNutritionalReport(filter)
+					if err != nil {
+						return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+					}
+					fdc.PrintReportTrends(report, fdc.DefaultTrendWidth)
+				}
+				return nil
+			}
+		},
+	})
+}
+
+// reportFilter restreint le rapport à l'utilisateur courant et à la période
+// de --from à --to, ou à défaut au jour courant. Avec --all, ni l'utilisateur
+// ni le jour courants ne filtrent ; sans utilisateur courant, --date seul
+// garde tous les utilisateurs.
+func reportFilter(all bool, from, to, mealType string) (fdc.ReportFilter, error) {
+	var filter fdc.ReportFilter
+	if mealType != "" {
+		t, err := parseMealType(mealType)
+		if err != nil {
+			return filter, usageErrorf("--meal-type : %v", err)
+		}
+		filter.MealType = t.(models.MealType)
+	}
+
+	period := from != "" || to != ""
+	if period && override.date != "" {
+		return filter, usageErrorf("--date ne peut pas être combinée avec --from ou --to")
+	}
+	if from != "" {
+		day, err := parseDate(from)
+		if err != nil {
+			return filter, usageErrorf("--from : %v", err)
+		}
+		filter.From = day
+	}
+	if to != "" {
+		day, err := parseDate(to)
+		if err != nil {
+			return filter, usageErrorf("--to : %v", err)
+		}
+		filter.To = day.AddDate(0, 0, 1)
+	}
+	if period && !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, usageErrorf("--to doit être postérieur ou égal à --from")
+	}
+	if all {
+		return filter, nil
+	}
+
+	user, ok, err := currentUser()
+	if err != nil {
+		return filter, err
+	}
+	if ok {
+		filter.UserID = user.ID
+	}
+	if period {
+		return filter, nil
+	}
+	if !ok {
+		if override.date == "" {
+			return filter, nil
+		}
+		day, err := parseDate(override.date)
+		if err != nil {
+			return filter, usageErrorf("%v", err)
+		}
+		filter.From, filter.To = day, day.AddDate(0, 0, 1)
+		return filter, nil
+	}
+	day, err := currentDay(&user)
+	if err != nil {
+		return filter, err
+	}
+	filter.From = user.Day(day)
+	filter.To = filter.From.AddDate(0, 0, 1)
+	return filter, nil
+}
+
+// periodFilter étend le filtre du rapport par période : sans --from ni --to,
+// à la semaine ou au mois de --date, sinon à tous les jours
+func periodFilter(filter fdc.ReportFilter, by fdc.Period, period bool) fdc.ReportFilter {
+	if period {
+		return filter
+	}
+	if override.date != "" && !filter.From.IsZero() {
+		filter.From = by.Start(filter.From)
+		filter.To = by.Next(filter.From)
+		return filter
+	}
+	filter.From, filter.To = time.Time{}, time.Time{}
+	return filter
+}
+
+// periodReport affiche ou écrit le rapport regroupé par période
+func periodReport(filter fdc.ReportFilter, by fdc.Period) error {
+	if outputFormat == output.Table {
+		i18n.Println("Génération du bilan nutritionnel par période...")
+		if err := fdc.GeneratePeriodReport(filter, by); err != nil {
+			return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+		}
+		return nil
+	}
+	periods, err := fdc.GetPeriodReport(filter, by)
+	if err != nil {
+		return i18n.Errorf("erreur lors de la génération du rapport : %w", err)
+	}
+	return emit(periodReportOut{
+		listOf(periods, func(p fdc.PeriodTotals) periodOut { return newPeriodOut(p, by) }),
+		listOf(fdc.CorrelateWeight(periods), newWeightCorrelationOut),
+	}, nil)
+}
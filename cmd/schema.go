@@ -0,0 +1,868 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/output"
+)
+
+// Schémas des sorties json, csv et yaml. Les noms de champs font partie du
+// contrat avec les scripts : on en ajoute, on n'en renomme pas.
+//
+// Dates : "AAAA-MM-JJ" pour un jour calendaire, RFC 3339 pour un instant.
+
+// outputFormat est le format demandé par --output pour la commande en cours
+var outputFormat = output.Table
+
+// emit écrit doc au format demandé ; en mode table, c'est table qui affiche le résultat
+func emit(doc any, table func() error) error {
+	if outputFormat == output.Table {
+		return table()
+	}
+	return output.Write(os.Stdout, outputFormat, doc)
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func formatUint(v uint) string { return strconv.FormatUint(uint64(v), 10) }
+
+func formatDay(t time.Time) string { return t.Format("2006-01-02") }
+
+// timestamp renvoie nil pour un instant non renseigné
+func timestamp(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+type foodOut struct {
+	FdcID       int    `json:"fdc_id" yaml:"fdc_id"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func (foodOut) CSVHeader() []string { return []string{"fdc_id", "description"} }
+func (f foodOut) CSVRow() []string  { return []string{strconv.Itoa(f.FdcID), f.Description} }
+
+type foodDetailOut struct {
+	FdcID         int     `json:"fdc_id" yaml:"fdc_id"`
+	Name          string  `json:"name" yaml:"name"`
+	QuantityG     float64 `json:"quantity_g" yaml:"quantity_g"`
+	Calories      float64 `json:"calories" yaml:"calories"`
+	Proteins      float64 `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64 `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64 `json:"lipids" yaml:"lipids"`
+}
+
+func (foodDetailOut) CSVHeader() []string {
+	return []string{"fdc_id", "name", "quantity_g", "calories", "proteins", "carbohydrates", "lipids"}
+}
+
+func (f foodDetailOut) CSVRow() []string {
+	return []string{strconv.Itoa(f.FdcID), f.Name, formatFloat(f.QuantityG),
+		formatFloat(f.Calories), formatFloat(f.Proteins), formatFloat(f.Carbohydrates), formatFloat(f.Lipids)}
+}
+
+type foodUsageOut struct {
+	FdcID         int     `json:"fdc_id" yaml:"fdc_id"`
+	Name          string  `json:"name" yaml:"name"`
+	Uses          int     `json:"uses" yaml:"uses"`
+	LastQuantityG float64 `json:"last_quantity_g" yaml:"last_quantity_g"`
+	LastUsed      *string `json:"last_used" yaml:"last_used"`
+	Favorite      bool    `json:"favorite" yaml:"favorite"`
+}
+
+func newFoodUsageOut(u fdc.FoodUsage) foodUsageOut {
+	return foodUsageOut{u.FdcID, u.Name, u.Uses, round2(u.LastQuantity), timestamp(u.LastUsed), u.Favorite}
+}
+
+func (foodUsageOut) CSVHeader() []string {
+	return []string{"fdc_id", "name", "uses", "last_quantity_g", "last_used", "favorite"}
+}
+
+func (u foodUsageOut) CSVRow() []string {
+	return []string{strconv.Itoa(u.FdcID), u.Name, strconv.Itoa(u.Uses), formatFloat(u.LastQuantityG),
+		deref(u.LastUsed), strconv.FormatBool(u.Favorite)}
+}
+
+type userOut struct {
+	ID                uint    `json:"id" yaml:"id"`
+	FirstName         string  `json:"first_name" yaml:"first_name"`
+	LastName          string  `json:"last_name" yaml:"last_name"`
+	Age               int     `json:"age" yaml:"age"`
+	Gender            string  `json:"gender" yaml:"gender"`
+	Goal              string  `json:"goal" yaml:"goal"`
+	TimeZone          string  `json:"time_zone" yaml:"time_zone"`
+	CalorieNeeds      float64 `json:"calorie_needs" yaml:"calorie_needs"`
+	ProteinNeeds      float64 `json:"protein_needs" yaml:"protein_needs"`
+	CarbohydrateNeeds float64 `json:"carbohydrate_needs" yaml:"carbohydrate_needs"`
+	LipidNeeds        float64 `json:"lipid_needs" yaml:"lipid_needs"`
+}
+
+func newUserOut(u models.User) userOut {
+	return userOut{u.ID, u.FirstName, u.LastName, u.Age, string(u.Gender), string(u.Goal), u.TimeZone,
+		round2(u.CalorieNeeds), round2(u.ProteinNeeds), round2(u.CarohydratesNeeds), round2(u.LipidNeeds)}
+}
+
+func (userOut) CSVHeader() []string {
+	return []string{"id", "first_name", "last_name", "age", "gender", "goal", "time_zone",
+		"calorie_needs", "protein_needs", "carbohydrate_needs", "lipid_needs"}
+}
+
+func (u userOut) CSVRow() []string {
+	return []string{formatUint(u.ID), u.FirstName, u.LastName, strconv.Itoa(u.Age), u.Gender, u.Goal, u.TimeZone,
+		formatFloat(u.CalorieNeeds), formatFloat(u.ProteinNeeds), formatFloat(u.CarbohydrateNeeds), formatFloat(u.LipidNeeds)}
+}
+
+type itemOut struct {
+	ID            uint    `json:"id" yaml:"id"`
+	MealID        uint    `json:"meal_id" yaml:"meal_id"`
+	FdcID         int     `json:"fdc_id" yaml:"fdc_id"`
+	Name          string  `json:"name" yaml:"name"`
+	QuantityG     float64 `json:"quantity_g" yaml:"quantity_g"`
+	Calories      float64 `json:"calories" yaml:"calories"`
+	Proteins      float64 `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64 `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64 `json:"lipids" yaml:"lipids"`
+	LoggedAt      *string `json:"logged_at" yaml:"logged_at"`
+}
+
+func newItemOut(i models.MealItem) itemOut {
+	return itemOut{i.ID, i.MealID, i.FdcID, i.Name, round2(i.Quantity),
+		round2(i.Calories), round2(i.Proteins), round2(i.Carbohydrates), round2(i.Lipids), timestamp(i.LoggedAt)}
+}
+
+func (itemOut) CSVHeader() []string {
+	return []string{"id", "meal_id", "fdc_id", "name", "quantity_g", "calories", "proteins", "carbohydrates", "lipids", "logged_at"}
+}
+
+func (i itemOut) CSVRow() []string {
+	return []string{formatUint(i.ID), formatUint(i.MealID), strconv.Itoa(i.FdcID), i.Name, formatFloat(i.QuantityG),
+		formatFloat(i.Calories), formatFloat(i.Proteins), formatFloat(i.Carbohydrates), formatFloat(i.Lipids), deref(i.LoggedAt)}
+}
+
+type mealOut struct {
+	ID            uint      `json:"id" yaml:"id"`
+	DailyMenuID   *uint     `json:"daily_menu_id" yaml:"daily_menu_id"`
+	Type          string    `json:"type" yaml:"type"`
+	Description   string    `json:"description" yaml:"description"`
+	Calories      float64   `json:"calories" yaml:"calories"`
+	Proteins      float64   `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64   `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64   `json:"lipids" yaml:"lipids"`
+	LoggedAt      *string   `json:"logged_at" yaml:"logged_at"`
+	Items         []itemOut `json:"items" yaml:"items"`
+}
+
+func newMealOut(m models.Meal) mealOut {
+	out := mealOut{m.ID, m.DailyMenuID, string(m.Type), m.Description,
+		round2(m.Calories), round2(m.Proteins), round2(m.Carbohydrates), round2(m.Lipids), timestamp(m.LoggedAt),
+		make([]itemOut, 0, len(m.Items))}
+	for _, item := range m.Items {
+		out.Items = append(out.Items, newItemOut(item))
+	}
+	return out
+}
+
+// En CSV, un repas tient sur une ligne : ses aliments ne sont que comptés
+func (mealOut) CSVHeader() []string {
+	return []string{"id", "daily_menu_id", "type", "description", "calories", "proteins", "carbohydrates", "lipids", "logged_at", "items"}
+}
+
+func (m mealOut) CSVRow() []string {
+	menuID := ""
+	if m.DailyMenuID != nil {
+		menuID = formatUint(*m.DailyMenuID)
+	}
+	return []string{formatUint(m.ID), menuID, m.Type, m.Description, formatFloat(m.Calories), formatFloat(m.Proteins),
+		formatFloat(m.Carbohydrates), formatFloat(m.Lipids), deref(m.LoggedAt), strconv.Itoa(len(m.Items))}
+}
+
+type menuOut struct {
+	ID            uint      `json:"id" yaml:"id"`
+	UserID        uint      `json:"user_id" yaml:"user_id"`
+	User          string    `json:"user" yaml:"user"`
+	Date          string    `json:"date" yaml:"date"`
+	Calories      float64   `json:"calories" yaml:"calories"`
+	Proteins      float64   `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64   `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64   `json:"lipids" yaml:"lipids"`
+	Meals         []mealOut `json:"meals" yaml:"meals"`
+}
+
+func newMenuOut(m models.DailyMenu) menuOut {
+	out := menuOut{ID: m.ID, UserID: m.UserID, User: m.User.FirstName + " " + m.User.LastName,
+		Date: formatDay(m.Date), Meals: make([]mealOut, 0, len(m.Meals))}
+	for _, meal := range m.Meals {
+		out.Calories += meal.Calories
+		out.Proteins += meal.Proteins
+		out.Carbohydrates += meal.Carbohydrates
+		out.Lipids += meal.Lipids
+		out.Meals = append(out.Meals, newMealOut(meal))
+	}
+	out.Calories, out.Proteins = round2(out.Calories), round2(out.Proteins)
+	out.Carbohydrates, out.Lipids = round2(out.Carbohydrates), round2(out.Lipids)
+	return out
+}
+
+// En CSV, un menu tient sur une ligne : ses repas ne sont que comptés
+func (menuOut) CSVHeader() []string {
+	return []string{"id", "user_id", "user", "date", "calories", "proteins", "carbohydrates", "lipids", "meals"}
+}
+
+func (m menuOut) CSVRow() []string {
+	return []string{formatUint(m.ID), formatUint(m.UserID), m.User, m.Date, formatFloat(m.Calories), formatFloat(m.Proteins),
+		formatFloat(m.Carbohydrates), formatFloat(m.Lipids), strconv.Itoa(len(m.Meals))}
+}
+
+// addMenuOut est le résultat de addmenu : created vaut false si le menu existait déjà
+type addMenuOut struct {
+	menuOut `yaml:",inline"`
+	Created bool `json:"created" yaml:"created"`
+}
+
+func (addMenuOut) CSVHeader() []string { return append(menuOut{}.CSVHeader(), "created") }
+func (m addMenuOut) CSVRow() []string {
+	return append(m.menuOut.CSVRow(), strconv.FormatBool(m.Created))
+}
+
+type measurementOut struct {
+	ID      uint    `json:"id" yaml:"id"`
+	UserID  uint    `json:"user_id" yaml:"user_id"`
+	Date    *string `json:"date" yaml:"date"`
+	Weight  float64 `json:"weight" yaml:"weight"`
+	Height  float64 `json:"height" yaml:"height"`
+	BMI     float64 `json:"bmi" yaml:"bmi"`
+	BodyFat float64 `json:"body_fat" yaml:"body_fat"`
+}
+
+func newMeasurementOut(m models.Measurement) measurementOut {
+	return measurementOut{m.ID, m.UserID, timestamp(m.Date), round2(m.Weight), round2(m.Height), round2(m.BMI), round2(m.BodyFat)}
+}
+
+func (measurementOut) CSVHeader() []string {
+	return []string{"id", "user_id", "date", "weight", "height", "bmi", "body_fat"}
+}
+
+func (m measurementOut) CSVRow() []string {
+	return []string{formatUint(m.ID), formatUint(m.UserID), deref(m.Date), formatFloat(m.Weight), formatFloat(m.Height),
+		formatFloat(m.BMI), formatFloat(m.BodyFat)}
+}
+
+type trashOut struct {
+	Entity    string  `json:"entity" yaml:"entity"`
+	ID        uint    `json:"id" yaml:"id"`
+	Label     string  `json:"label" yaml:"label"`
+	DeletedAt *string `json:"deleted_at" yaml:"deleted_at"`
+}
+
+func newTrashOut(e fdc.TrashEntry) trashOut {
+	return trashOut{string(e.Entity), e.ID, e.Label, timestamp(e.DeletedAt)}
+}
+
+func (trashOut) CSVHeader() []string { return []string{"entity", "id", "label", "deleted_at"} }
+func (t trashOut) CSVRow() []string {
+	return []string{t.Entity, formatUint(t.ID), t.Label, deref(t.DeletedAt)}
+}
+
+type historyOut struct {
+	ID       uint           `json:"id" yaml:"id"`
+	Date     *string        `json:"date" yaml:"date"`
+	Actor    string         `json:"actor" yaml:"actor"`
+	Command  string         `json:"command" yaml:"command"`
+	Action   string         `json:"action" yaml:"action"`
+	Entity   string         `json:"entity" yaml:"entity"`
+	EntityID uint           `json:"entity_id" yaml:"entity_id"`
+	Before   map[string]any `json:"before" yaml:"before"`
+	After    map[string]any `json:"after" yaml:"after"`
+}
+
+func newHistoryOut(l models.AuditLog) historyOut {
+	out := historyOut{ID: l.ID, Date: timestamp(l.CreatedAt), Actor: l.Actor, Command: l.Command,
+		Action: string(l.Action), Entity: l.Entity, EntityID: l.EntityID}
+	_ = json.Unmarshal([]byte(l.Before), &out.Before)
+	_ = json.Unmarshal([]byte(l.After), &out.After)
+	return out
+}
+
+// En CSV, les états avant et après sont écrits en JSON
+func (historyOut) CSVHeader() []string {
+	return []string{"id", "date", "actor", "command", "action", "entity", "entity_id", "before", "after"}
+}
+
+func (h historyOut) CSVRow() []string {
+	before, _ := json.Marshal(h.Before)
+	after, _ := json.Marshal(h.After)
+	return []string{formatUint(h.ID), deref(h.Date), h.Actor, h.Command, h.Action, h.Entity, formatUint(h.EntityID),
+		string(before), string(after)}
+}
+
+type dayOut struct {
+	MenuID        uint    `json:"menu_id" yaml:"menu_id"`
+	Date          string  `json:"date" yaml:"date"`
+	UserID        uint    `json:"user_id" yaml:"user_id"`
+	User          string  `json:"user" yaml:"user"`
+	Calories      float64 `json:"calories" yaml:"calories"`
+	Proteins      float64 `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64 `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64 `json:"lipids" yaml:"lipids"`
+}
+
+func newDayOut(d fdc.DailyTotals) dayOut {
+	return dayOut{d.MenuID, formatDay(d.Date), d.User.ID, d.User.FirstName + " " + d.User.LastName,
+		round2(d.Calories), round2(d.Proteins), round2(d.Carbohydrates), round2(d.Lipids)}
+}
+
+func (dayOut) CSVHeader() []string {
+	return []string{"menu_id", "date", "user_id", "user", "calories", "proteins", "carbohydrates", "lipids"}
+}
+
+func (d dayOut) CSVRow() []string {
+	return []string{formatUint(d.MenuID), d.Date, formatUint(d.UserID), d.User,
+		formatFloat(d.Calories), formatFloat(d.Proteins), formatFloat(d.Carbohydrates), formatFloat(d.Lipids)}
+}
+
+type nutrientsOut struct {
+	Calories      float64 `json:"calories" yaml:"calories"`
+	Proteins      float64 `json:"proteins" yaml:"proteins"`
+	Carbohydrates float64 `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        float64 `json:"lipids" yaml:"lipids"`
+}
+
+func newNutrientsOut(n fdc.Nutrients) nutrientsOut {
+	return nutrientsOut{round2(n.Calories), round2(n.Proteins), round2(n.Carbohydrates), round2(n.Lipids)}
+}
+
+func (n nutrientsOut) csv() []string {
+	return []string{formatFloat(n.Calories), formatFloat(n.Proteins), formatFloat(n.Carbohydrates), formatFloat(n.Lipids)}
+}
+
+// userSummaryOut est le sous-total et la moyenne journalière d'un utilisateur
+type userSummaryOut struct {
+	UserID  uint         `json:"user_id" yaml:"user_id"`
+	User    string       `json:"user" yaml:"user"`
+	Days    int          `json:"days" yaml:"days"`
+	Total   nutrientsOut `json:"total" yaml:"total"`
+	Average nutrientsOut `json:"average" yaml:"average"`
+}
+
+func newUserSummaryOut(s fdc.UserSummary) userSummaryOut {
+	return userSummaryOut{s.User.ID, s.User.FirstName + " " + s.User.LastName, s.Days,
+		newNutrientsOut(s.Total), newNutrientsOut(s.Average)}
+}
+
+func (userSummaryOut) CSVHeader() []string {
+	return []string{"user_id", "user", "days", "total_calories", "total_proteins", "total_carbohydrates", "total_lipids",
+		"average_calories", "average_proteins", "average_carbohydrates", "average_lipids"}
+}
+
+func (s userSummaryOut) CSVRow() []string {
+	row := append([]string{formatUint(s.UserID), s.User, strconv.Itoa(s.Days)}, s.Total.csv()...)
+	return append(row, s.Average.csv()...)
+}
+
+// reportOut est le résultat de report ; en CSV, seuls les jours sont écrits
+type reportOut struct {
+	Meals output.List[mealOut]        `json:"meals" yaml:"meals"`
+	Days  output.List[dayOut]         `json:"days" yaml:"days"`
+	Users output.List[userSummaryOut] `json:"users" yaml:"users"`
+}
+
+func (r reportOut) CSV() ([]string, [][]string) { return r.Days.CSV() }
+
+// periodOut contient les apports d'un utilisateur sur une semaine ou un mois ;
+// end est le dernier jour de la période. Les écarts avec la période
+// précédente sont absents quand elle n'a pas de jour ou de mesure.
+type periodOut struct {
+	Period      string        `json:"period" yaml:"period"`
+	Start       string        `json:"start" yaml:"start"`
+	End         string        `json:"end" yaml:"end"`
+	UserID      uint          `json:"user_id" yaml:"user_id"`
+	User        string        `json:"user" yaml:"user"`
+	Days        int           `json:"days" yaml:"days"`
+	Total       nutrientsOut  `json:"total" yaml:"total"`
+	Average     nutrientsOut  `json:"average" yaml:"average"`
+	Min         nutrientsOut  `json:"min" yaml:"min"`
+	Max         nutrientsOut  `json:"max" yaml:"max"`
+	Delta       *nutrientsOut `json:"delta" yaml:"delta"`
+	Weight      *float64      `json:"weight" yaml:"weight"`
+	WeightDelta *float64      `json:"weight_delta" yaml:"weight_delta"`
+}
+
+func newPeriodOut(p fdc.PeriodTotals, by fdc.Period) periodOut {
+	out := periodOut{by.Label(p.Start), formatDay(p.Start), formatDay(p.End.AddDate(0, 0, -1)),
+		p.User.ID, p.User.FirstName + " " + p.User.LastName, p.Days,
+		newNutrientsOut(p.Total), newNutrientsOut(p.Average), newNutrientsOut(p.Min), newNutrientsOut(p.Max),
+		nil, nil, nil}
+	if p.Delta != nil {
+		delta := newNutrientsOut(*p.Delta)
+		out.Delta = &delta
+	}
+	if p.Weight > 0 {
+		weight := round2(p.Weight)
+		out.Weight = &weight
+	}
+	if p.WeightDelta != nil {
+		weightDelta := round2(*p.WeightDelta)
+		out.WeightDelta = &weightDelta
+	}
+	return out
+}
+
+func (periodOut) CSVHeader() []string {
+	header := []string{"period", "start", "end", "user_id", "user", "days"}
+	for _, prefix := range []string{"total_", "average_", "min_", "max_", "delta_"} {
+		for _, n := range fdc.TrackedNutrients {
+			header = append(header, prefix+string(n))
+		}
+	}
+	return append(header, "weight", "weight_delta")
+}
+
+func (p periodOut) CSVRow() []string {
+	row := []string{p.Period, p.Start, p.End, formatUint(p.UserID), p.User, strconv.Itoa(p.Days)}
+	row = append(row, p.Total.csv()...)
+	row = append(row, p.Average.csv()...)
+	row = append(row, p.Min.csv()...)
+	row = append(row, p.Max.csv()...)
+	if p.Delta != nil {
+		row = append(row, p.Delta.csv()...)
+	} else {
+		row = append(row, "", "", "", "")
+	}
+	return append(row, optionalFloat(p.Weight), optionalFloat(p.WeightDelta))
+}
+
+// optionalFloat renvoie une cellule vide pour une valeur absente
+func optionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatFloat(*v)
+}
+
+// weightCorrelationOut est la corrélation entre l'apport calorique moyen d'un
+// utilisateur et la variation de son poids, d'une période à l'autre
+type weightCorrelationOut struct {
+	UserID  uint    `json:"user_id" yaml:"user_id"`
+	User    string  `json:"user" yaml:"user"`
+	R       float64 `json:"r" yaml:"r"`
+	Periods int     `json:"periods" yaml:"periods"`
+}
+
+func newWeightCorrelationOut(c fdc.WeightCorrelation) weightCorrelationOut {
+	return weightCorrelationOut{c.User.ID, c.User.FirstName + " " + c.User.LastName, round2(c.R), c.Periods}
+}
+
+func (weightCorrelationOut) CSVHeader() []string { return []string{"user_id", "user", "r", "periods"} }
+
+func (c weightCorrelationOut) CSVRow() []string {
+	return []string{formatUint(c.UserID), c.User, formatFloat(c.R), strconv.Itoa(c.Periods)}
+}
+
+// periodReportOut est le résultat de report --by ; en CSV, seules les
+// périodes sont écrites
+type periodReportOut struct {
+	Periods      output.List[periodOut]            `json:"periods" yaml:"periods"`
+	Correlations output.List[weightCorrelationOut] `json:"correlations" yaml:"correlations"`
+}
+
+func (r periodReportOut) CSV() ([]string, [][]string) { return r.Periods.CSV() }
+
+// mealTypeShareOut contient les apports cumulés des repas d'un type et leur
+// part, en %, dans ceux de la période
+type mealTypeShareOut struct {
+	Type      string       `json:"type" yaml:"type"`
+	Meals     int          `json:"meals" yaml:"meals"`
+	Nutrients nutrientsOut `json:"nutrients" yaml:"nutrients"`
+	Share     nutrientsOut `json:"share" yaml:"share"`
+}
+
+// shareOf renvoie la part, en %, de chaque apport de n dans total
+func shareOf(n, total fdc.Nutrients) nutrientsOut {
+	return newNutrientsOut(fdc.Nutrients{
+		Calories: fdc.Share(n.Calories, total.Calories), Proteins: fdc.Share(n.Proteins, total.Proteins),
+		Carbohydrates: fdc.Share(n.Carbohydrates, total.Carbohydrates), Lipids: fdc.Share(n.Lipids, total.Lipids)})
+}
+
+// itemShareOut est un aliment d'un repas et sa part dans les apports du jour
+type itemShareOut struct {
+	FdcID     int          `json:"fdc_id" yaml:"fdc_id"`
+	Name      string       `json:"name" yaml:"name"`
+	Quantity  float64      `json:"quantity" yaml:"quantity"`
+	Nutrients nutrientsOut `json:"nutrients" yaml:"nutrients"`
+	Share     nutrientsOut `json:"share" yaml:"share"`
+}
+
+// mealShareOut est un repas, sa part dans les apports du jour et ses aliments
+type mealShareOut struct {
+	ID          uint           `json:"id" yaml:"id"`
+	Type        string         `json:"type" yaml:"type"`
+	Description string         `json:"description" yaml:"description"`
+	Nutrients   nutrientsOut   `json:"nutrients" yaml:"nutrients"`
+	Share       nutrientsOut   `json:"share" yaml:"share"`
+	Items       []itemShareOut `json:"items" yaml:"items"`
+}
+
+// dayBreakdownOut contient les repas d'un menu journalier
+type dayBreakdownOut struct {
+	MenuID    uint           `json:"menu_id" yaml:"menu_id"`
+	Date      string         `json:"date" yaml:"date"`
+	Nutrients nutrientsOut   `json:"nutrients" yaml:"nutrients"`
+	Meals     []mealShareOut `json:"meals" yaml:"meals"`
+}
+
+func newDayBreakdownOut(d fdc.DayBreakdown) dayBreakdownOut {
+	out := dayBreakdownOut{d.MenuID, formatDay(d.Date), newNutrientsOut(d.Nutrients), []mealShareOut{}}
+	for _, meal := range d.Meals {
+		n := fdc.Nutrients{Calories: meal.Calories, Proteins: meal.Proteins, Carbohydrates: meal.Carbohydrates, Lipids: meal.Lipids}
+		m := mealShareOut{meal.ID, string(meal.Type), meal.Description, newNutrientsOut(n), shareOf(n, d.Nutrients), []itemShareOut{}}
+		for _, item := range meal.Items {
+			n := fdc.Nutrients{Calories: item.Calories, Proteins: item.Proteins, Carbohydrates: item.Carbohydrates, Lipids: item.Lipids}
+			m.Items = append(m.Items, itemShareOut{item.FdcID, item.Name, round2(item.Quantity), newNutrientsOut(n), shareOf(n, d.Nutrients)})
+		}
+		out.Meals = append(out.Meals, m)
+	}
+	return out
+}
+
+// foodContributionOut est un aliment du classement : amount est dans l'unité
+// du nutriment classé, share sa part en % du total de la période
+type foodContributionOut struct {
+	Rank     int     `json:"rank" yaml:"rank"`
+	FdcID    int     `json:"fdc_id" yaml:"fdc_id"`
+	Name     string  `json:"name" yaml:"name"`
+	Uses     int     `json:"uses" yaml:"uses"`
+	Quantity float64 `json:"quantity" yaml:"quantity"`
+	Amount   float64 `json:"amount" yaml:"amount"`
+	Share    float64 `json:"share" yaml:"share"`
+}
+
+func (foodContributionOut) CSVHeader() []string {
+	return []string{"rank", "fdc_id", "name", "uses", "quantity", "amount", "share"}
+}
+
+func (c foodContributionOut) CSVRow() []string {
+	return []string{strconv.Itoa(c.Rank), strconv.Itoa(c.FdcID), c.Name, strconv.Itoa(c.Uses),
+		formatFloat(c.Quantity), formatFloat(c.Amount), formatFloat(c.Share)}
+}
+
+// breakdownOut est le résultat de breakdown ; en CSV, seul le classement des
+// aliments est écrit
+type breakdownOut struct {
+	Total     nutrientsOut                     `json:"total" yaml:"total"`
+	MealTypes []mealTypeShareOut               `json:"meal_types" yaml:"meal_types"`
+	Days      []dayBreakdownOut                `json:"days" yaml:"days"`
+	Rank      string                           `json:"rank" yaml:"rank"`
+	Unit      string                           `json:"unit" yaml:"unit"`
+	RankTotal float64                          `json:"rank_total" yaml:"rank_total"`
+	Foods     output.List[foodContributionOut] `json:"foods" yaml:"foods"`
+	// MissingFoods compte les aliments sans détails nutritionnels, absents du classement
+	MissingFoods int `json:"missing_foods" yaml:"missing_foods"`
+}
+
+func newBreakdownOut(b fdc.Breakdown, nutrient fdc.Nutrient, top []fdc.FoodContribution, total float64, missing int) breakdownOut {
+	out := breakdownOut{Total: newNutrientsOut(b.Total), MealTypes: []mealTypeShareOut{}, Rank: string(nutrient),
+		Unit: nutrient.Unit(), RankTotal: round2(total), Foods: output.List[foodContributionOut]{}, MissingFoods: missing}
+	for _, t := range b.MealTypes {
+		out.MealTypes = append(out.MealTypes, mealTypeShareOut{string(t.Type), t.Meals, newNutrientsOut(t.Nutrients), shareOf(t.Nutrients, b.Total)})
+	}
+	out.Days = make([]dayBreakdownOut, 0, len(b.Days))
+	for _, d := range b.Days {
+		out.Days = append(out.Days, newDayBreakdownOut(d))
+	}
+	for i, c := range top {
+		out.Foods = append(out.Foods, foodContributionOut{i + 1, c.FdcID, c.Name, c.Uses, round2(c.Quantity),
+			round2(c.Amount), round2(fdc.Share(c.Amount, total))})
+	}
+	return out
+}
+
+func (b breakdownOut) CSV() ([]string, [][]string) { return b.Foods.CSV() }
+
+// nutrientFlagsOut indique, pour chaque apport, s'il est dans la marge de tolérance
+type nutrientFlagsOut struct {
+	Calories      bool `json:"calories" yaml:"calories"`
+	Proteins      bool `json:"proteins" yaml:"proteins"`
+	Carbohydrates bool `json:"carbohydrates" yaml:"carbohydrates"`
+	Lipids        bool `json:"lipids" yaml:"lipids"`
+}
+
+func (f nutrientFlagsOut) csv() []string {
+	return []string{strconv.FormatBool(f.Calories), strconv.FormatBool(f.Proteins),
+		strconv.FormatBool(f.Carbohydrates), strconv.FormatBool(f.Lipids)}
+}
+
+// adherenceDayOut compare les apports d'un jour aux objectifs : en % de
+// l'objectif, et en excédent (positif) ou déficit (négatif)
+type adherenceDayOut struct {
+	MenuID  uint             `json:"menu_id" yaml:"menu_id"`
+	Date    string           `json:"date" yaml:"date"`
+	Intake  nutrientsOut     `json:"intake" yaml:"intake"`
+	Percent nutrientsOut     `json:"percent" yaml:"percent"`
+	Gap     nutrientsOut     `json:"gap" yaml:"gap"`
+	Within  nutrientFlagsOut `json:"within" yaml:"within"`
+}
+
+func newAdherenceDayOut(r fdc.AdherenceReport, d fdc.AdherenceDay) adherenceDayOut {
+	a := func(n fdc.Nutrient) fdc.Adherence { return r.Adherence(d, n) }
+	return adherenceDayOut{d.MenuID, formatDay(d.Date), newNutrientsOut(d.Intake),
+		newNutrientsOut(fdc.Nutrients{
+			Calories: a(fdc.NutrientCalories).Percent(), Proteins: a(fdc.NutrientProteins).Percent(),
+			Carbohydrates: a(fdc.NutrientCarbohydrates).Percent(), Lipids: a(fdc.NutrientLipids).Percent()}),
+		newNutrientsOut(fdc.Nutrients{
+			Calories: a(fdc.NutrientCalories).Gap(), Proteins: a(fdc.NutrientProteins).Gap(),
+			Carbohydrates: a(fdc.NutrientCarbohydrates).Gap(), Lipids: a(fdc.NutrientLipids).Gap()}),
+		nutrientFlagsOut{
+			a(fdc.NutrientCalories).Within(r.Tolerance), a(fdc.NutrientProteins).Within(r.Tolerance),
+			a(fdc.NutrientCarbohydrates).Within(r.Tolerance), a(fdc.NutrientLipids).Within(r.Tolerance)}}
+}
+
+func (adherenceDayOut) CSVHeader() []string {
+	header := []string{"menu_id", "date"}
+	for _, prefix := range []string{"", "percent_", "gap_", "within_"} {
+		for _, n := range fdc.TrackedNutrients {
+			header = append(header, prefix+string(n))
+		}
+	}
+	return header
+}
+
+func (d adherenceDayOut) CSVRow() []string {
+	row := append([]string{formatUint(d.MenuID), d.Date}, d.Intake.csv()...)
+	row = append(row, d.Percent.csv()...)
+	row = append(row, d.Gap.csv()...)
+	return append(row, d.Within.csv()...)
+}
+
+// nutrientAdherenceOut résume le suivi d'un apport sur la période
+type nutrientAdherenceOut struct {
+	Nutrient      string  `json:"nutrient" yaml:"nutrient"`
+	Intake        float64 `json:"intake" yaml:"intake"`
+	Target        float64 `json:"target" yaml:"target"`
+	Percent       float64 `json:"percent" yaml:"percent"`
+	Gap           float64 `json:"gap" yaml:"gap"`
+	DaysWithin    int     `json:"days_within" yaml:"days_within"`
+	CurrentStreak int     `json:"current_streak" yaml:"current_streak"`
+	LongestStreak int     `json:"longest_streak" yaml:"longest_streak"`
+}
+
+func newNutrientAdherenceOut(n fdc.NutrientAdherence) nutrientAdherenceOut {
+	return nutrientAdherenceOut{string(n.Nutrient), round2(n.Period.Intake), round2(n.Period.Target),
+		round2(n.Period.Percent()), round2(n.Period.Gap()), n.DaysWithin, n.CurrentStreak, n.LongestStreak}
+}
+
+func (nutrientAdherenceOut) CSVHeader() []string {
+	return []string{"nutrient", "intake", "target", "percent", "gap", "days_within", "current_streak", "longest_streak"}
+}
+
+func (n nutrientAdherenceOut) CSVRow() []string {
+	return []string{n.Nutrient, formatFloat(n.Intake), formatFloat(n.Target), formatFloat(n.Percent), formatFloat(n.Gap),
+		strconv.Itoa(n.DaysWithin), strconv.Itoa(n.CurrentStreak), strconv.Itoa(n.LongestStreak)}
+}
+
+// adherenceOut est le résultat de adherence ; to est inclus dans la période.
+// En CSV, seuls les jours sont écrits.
+type adherenceOut struct {
+	UserID    uint                              `json:"user_id" yaml:"user_id"`
+	User      string                            `json:"user" yaml:"user"`
+	From      string                            `json:"from" yaml:"from"`
+	To        string                            `json:"to" yaml:"to"`
+	Tolerance float64                           `json:"tolerance" yaml:"tolerance"`
+	Target    nutrientsOut                      `json:"target" yaml:"target"`
+	Days      output.List[adherenceDayOut]      `json:"days" yaml:"days"`
+	Nutrients output.List[nutrientAdherenceOut] `json:"nutrients" yaml:"nutrients"`
+}
+
+func newAdherenceOut(r fdc.AdherenceReport) adherenceOut {
+	return adherenceOut{r.User.ID, r.User.FirstName + " " + r.User.LastName,
+		formatDay(r.From), formatDay(r.To.AddDate(0, 0, -1)), r.Tolerance, newNutrientsOut(r.Target),
+		listOf(r.Days, func(d fdc.AdherenceDay) adherenceDayOut { return newAdherenceDayOut(r, d) }),
+		listOf(r.Nutrients, newNutrientAdherenceOut)}
+}
+
+func (a adherenceOut) CSV() ([]string, [][]string) { return a.Days.CSV() }
+
+// micronutrientDayOut contient les apports en micronutriments d'un jour, par nutriment
+type micronutrientDayOut struct {
+	MenuID  uint               `json:"menu_id" yaml:"menu_id"`
+	Date    string             `json:"date" yaml:"date"`
+	Amounts map[string]float64 `json:"amounts" yaml:"amounts"`
+}
+
+func newMicronutrientDayOut(d fdc.MicronutrientDay) micronutrientDayOut {
+	amounts := map[string]float64{}
+	for n, v := range d.Amounts {
+		amounts[string(n)] = round2(v)
+	}
+	return micronutrientDayOut{d.MenuID, formatDay(d.Date), amounts}
+}
+
+// micronutrientIntakeOut compare l'apport moyen d'un micronutriment à sa
+// référence ; upper vaut 0 sans apport maximal tolérable. La limite s'applique
+// à l'apport moyen en upper_nutrient, upper_average : le rétinol pour la
+// vitamine A, le nutriment lui-même sinon.
+type micronutrientIntakeOut struct {
+	Nutrient       string  `json:"nutrient" yaml:"nutrient"`
+	Unit           string  `json:"unit" yaml:"unit"`
+	Average        float64 `json:"average" yaml:"average"`
+	Reference      float64 `json:"reference" yaml:"reference"`
+	Adequate       bool    `json:"adequate_intake" yaml:"adequate_intake"`
+	Percent        float64 `json:"percent" yaml:"percent"`
+	Upper          float64 `json:"upper" yaml:"upper"`
+	UpperNutrient  string  `json:"upper_nutrient" yaml:"upper_nutrient"`
+	UpperAverage   float64 `json:"upper_average" yaml:"upper_average"`
+	DaysAboveUpper int     `json:"days_above_upper" yaml:"days_above_upper"`
+	Status         string  `json:"status" yaml:"status"`
+}
+
+func newMicronutrientIntakeOut(m fdc.MicronutrientIntake) micronutrientIntakeOut {
+	return micronutrientIntakeOut{string(m.Nutrient), m.Nutrient.Unit(), round2(m.Average), m.Intake, m.Adequate,
+		round2(m.Percent()), m.Upper, string(m.UpperOf), round2(m.UpperAverage), m.DaysAboveUpper, string(m.Status())}
+}
+
+func (micronutrientIntakeOut) CSVHeader() []string {
+	return []string{"nutrient", "unit", "average", "reference", "adequate_intake", "percent", "upper",
+		"upper_nutrient", "upper_average", "days_above_upper", "status"}
+}
+
+func (m micronutrientIntakeOut) CSVRow() []string {
+	return []string{m.Nutrient, m.Unit, formatFloat(m.Average), formatFloat(m.Reference), strconv.FormatBool(m.Adequate),
+		formatFloat(m.Percent), formatFloat(m.Upper), m.UpperNutrient, formatFloat(m.UpperAverage),
+		strconv.Itoa(m.DaysAboveUpper), m.Status}
+}
+
+// micronutrientsOut est le résultat de micronutrients ; to est inclus dans la
+// période. En CSV, seuls les apports moyens sont écrits.
+type micronutrientsOut struct {
+	UserID    uint                                `json:"user_id" yaml:"user_id"`
+	User      string                              `json:"user" yaml:"user"`
+	Age       int                                 `json:"age" yaml:"age"`
+	Gender    string                              `json:"gender" yaml:"gender"`
+	From      string                              `json:"from" yaml:"from"`
+	To        string                              `json:"to" yaml:"to"`
+	Days      []micronutrientDayOut               `json:"days" yaml:"days"`
+	Nutrients output.List[micronutrientIntakeOut] `json:"nutrients" yaml:"nutrients"`
+	// MissingFoods compte les aliments sans détails nutritionnels, non comptés
+	MissingFoods int `json:"missing_foods" yaml:"missing_foods"`
+}
+
+func newMicronutrientsOut(r fdc.MicronutrientReport) micronutrientsOut {
+	days := []micronutrientDayOut{}
+	for _, d := range r.Days {
+		days = append(days, newMicronutrientDayOut(d))
+	}
+	return micronutrientsOut{r.User.ID, r.User.FirstName + " " + r.User.LastName, r.User.Age, string(r.User.Gender),
+		formatDay(r.From), formatDay(r.To.AddDate(0, 0, -1)), days,
+		listOf(r.Nutrients, newMicronutrientIntakeOut), len(r.Missing)}
+}
+
+func (m micronutrientsOut) CSV() ([]string, [][]string) { return m.Nutrients.CSV() }
+
+// trendPointOut est un point d'une série de tendance
+type trendPointOut struct {
+	Date  string  `json:"date" yaml:"date"`
+	Value float64 `json:"value" yaml:"value"`
+}
+
+// trendSeriesOut est une série de tendance, sans les jours sans menu ;
+// target vaut 0 sans objectif
+type trendSeriesOut struct {
+	Series string          `json:"series" yaml:"series"`
+	Unit   string          `json:"unit" yaml:"unit"`
+	Target float64         `json:"target" yaml:"target"`
+	Points []trendPointOut `json:"points" yaml:"points"`
+}
+
+func newTrendSeriesOut(s fdc.TrendSeries) trendSeriesOut {
+	points := []trendPointOut{}
+	for i, v := range s.Values {
+		if !math.IsNaN(v) {
+			points = append(points, trendPointOut{formatDay(s.Dates[i]), round2(v)})
+		}
+	}
+	return trendSeriesOut{s.Name, s.Unit, round2(s.Target), points}
+}
+
+// trendOut est le résultat de trend ; to est inclus dans la période. En CSV,
+// chaque point est une ligne.
+type trendOut struct {
+	UserID uint             `json:"user_id" yaml:"user_id"`
+	User   string           `json:"user" yaml:"user"`
+	From   string           `json:"from" yaml:"from"`
+	To     string           `json:"to" yaml:"to"`
+	Series []trendSeriesOut `json:"series" yaml:"series"`
+}
+
+func newTrendOut(t fdc.Trend) trendOut {
+	series := []trendSeriesOut{}
+	for _, s := range t.Series() {
+		series = append(series, newTrendSeriesOut(s))
+	}
+	return trendOut{t.User.ID, t.User.FirstName + " " + t.User.LastName,
+		formatDay(t.From), formatDay(t.To.AddDate(0, 0, -1)), series}
+}
+
+func (t trendOut) CSV() ([]string, [][]string) {
+	var rows [][]string
+	for _, s := range t.Series {
+		for _, p := range s.Points {
+			rows = append(rows, []string{s.Series, s.Unit, p.Date, formatFloat(p.Value)})
+		}
+	}
+	return []string{"series", "unit", "date", "value"}, rows
+}
+
+type mergeOut struct {
+	Merged int `json:"merged" yaml:"merged"`
+}
+
+func (mergeOut) CSVHeader() []string { return []string{"merged"} }
+func (m mergeOut) CSVRow() []string  { return []string{strconv.Itoa(m.Merged)} }
+
+// actionOut décrit le résultat d'une commande qui modifie un enregistrement existant
+type actionOut struct {
+	Action  string `json:"action" yaml:"action"`
+	Entity  string `json:"entity" yaml:"entity"`
+	ID      uint   `json:"id" yaml:"id"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (actionOut) CSVHeader() []string { return []string{"action", "entity", "id", "message"} }
+func (a actionOut) CSVRow() []string {
+	return []string{a.Action, a.Entity, formatUint(a.ID), a.Message}
+}
+
+// done écrit le résultat d'une modification : message en mode table, actionOut sinon
+func done(action string, entity fdc.Entity, id uint, message string) error {
+	return emit(actionOut{action, string(entity), id, message}, func() error {
+		fmt.Println(message)
+		return nil
+	})
+}
+
+// listOf convertit une liste de modèles en liste d'enregistrements de sortie
+func listOf[M any, T output.Record](items []M, convert func(M) T) output.List[T] {
+	list := make(output.List[T], 0, len(items))
+	for _, item := range items {
+		list = append(list, convert(item))
+	}
+	return list
+}
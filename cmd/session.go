@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/config"
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// sessionOverride contient les options --user et --date de la commande en cours
+type sessionOverride struct {
+	user string
+	date string
+}
+
+// override remplace l'utilisateur et le jour courants le temps d'une commande
+var override sessionOverride
+
+func init() {
+	register(&Command{
+		Name:     "use",
+		Usage:    "use [<utilisateur>] [--date JJ/MM/AAAA | --today] [--clear]",
+		Summary:  "Choisir l'utilisateur et le jour courants, utilisés par défaut par les autres commandes",
+		NoOutput: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			date := fs.String("date", "", "jour courant JJ/MM/AAAA")
+			today := fs.Bool("today", false, "revenir à la date du jour")
+			clear := fs.Bool("clear", false, "oublier l'utilisateur et le jour courants")
+			return func(args []string) error {
+				if len(args) > 1 {
+					return usageErrorf("un seul utilisateur est attendu")
+				}
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				if *clear {
+					cfg.User, cfg.Date = 0, ""
+					if err := config.Save(cfg); err != nil {
+						return err
+					}
+					i18n.Println("✅ Plus d'utilisateur ni de jour courants")
+					return nil
+				}
+				if len(args) == 0 && *date == "" && !*today {
+					return showSession(cfg)
+				}
+
+				if len(args) == 1 {
+					user, err := resolveUser(args[0])
+					if err != nil {
+						return err
+					}
+					cfg.User = user.ID
+				}
+				if *today {
+					cfg.Date = ""
+				}
+				if *date != "" {
+					day, err := parseDate(*date)
+					if err != nil {
+						return usageErrorf("%v", err)
+					}
+					cfg.Date = day.Format(config.DateLayout)
+				}
+				if err := config.Save(cfg); err != nil {
+					return err
+				}
+				return showSession(cfg)
+			}
+		},
+	})
+}
+
+// showSession affiche l'utilisateur et le jour courants
+func showSession(cfg config.Config) error {
+	if cfg.User == 0 {
+		i18n.Println("Aucun utilisateur courant (gofit use <utilisateur>).")
+		return nil
+	}
+	user, err := fdc.GetUser(cfg.User)
+	if err != nil {
+		return err
+	}
+	i18n.Printf("👤 Utilisateur courant : %s %s (id %d)\n", user.FirstName, user.LastName, user.ID)
+	if cfg.Date == "" {
+		i18n.Printf("📅 Jour courant : aujourd'hui (%s)\n", i18n.Date(user.Now()))
+		return nil
+	}
+	day, err := user.ParseDay(config.DateLayout, cfg.Date)
+	if err != nil {
+		return i18n.Errorf("erreur lors de la lecture de la configuration : %w", err)
+	}
+	i18n.Printf("📅 Jour courant : %s\n", i18n.Date(day))
+	return nil
+}
+
+// resolveUser trouve un utilisateur par son identifiant ou par son nom :
+// prénom, nom ou les deux, sans tenir compte de la casse
+func resolveUser(s string) (models.User, error) {
+	if id, err := parseID(s); err == nil {
+		return fdc.GetUser(id)
+	}
+	users, err := fdc.GetUsers()
+	if err != nil {
+		return models.User{}, err
+	}
+	name := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	var found []models.User
+	for _, u := range users {
+		first, last := strings.ToLower(u.FirstName), strings.ToLower(u.LastName)
+		if name == first || name == last || name == first+" "+last || name == last+" "+first {
+			found = append(found, u)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.User{}, usageErrorf("utilisateur inconnu : %s", s)
+	case 1:
+		return found[0], nil
+	}
+	return models.User{}, usageErrorf("plusieurs utilisateurs correspondent à %q : précisez l'identifiant", s)
+}
+
+// currentUser renvoie l'utilisateur de --user, sinon l'utilisateur courant ;
+// ok est faux si aucun des deux n'est défini
+func currentUser() (user models.User, ok bool, err error) {
+	if override.user != "" {
+		user, err = resolveUser(override.user)
+		return user, err == nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.User == 0 {
+		return user, false, err
+	}
+	user, err = fdc.GetUser(cfg.User)
+	if err != nil {
+		return user, false, i18n.Errorf("utilisateur courant introuvable (gofit use --clear pour l'oublier) : %w", err)
+	}
+	return user, true, nil
+}
+
+// requireUser renvoie l'utilisateur de currentUser ; sans utilisateur, l'erreur
+// d'utilisation cite option, l'option qui permet de s'en passer
+func requireUser(option string) (models.User, error) {
+	user, ok, err := currentUser()
+	if err == nil && !ok {
+		err = usageErrorf("l'option %s est obligatoire sans utilisateur courant (gofit use <utilisateur>)", option)
+	}
+	return user, err
+}
+
+// currentDay renvoie le jour de --date, sinon le jour courant, sinon la date
+// du jour dans le fuseau de user
+func currentDay(user *models.User) (time.Time, error) {
+	if override.date != "" {
+		// parseDate accepte aussi le format ISO, comme --from et --to ; le
+		// jour est ensuite ramené à minuit dans le fuseau de user
+		date, err := parseDate(override.date)
+		if err != nil {
+			return date, usageErrorf("%v", err)
+		}
+		return user.ParseDay(config.DateLayout, date.Format(config.DateLayout))
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if cfg.Date != "" {
+		if day, err := user.ParseDay(config.DateLayout, cfg.Date); err == nil {
+			return day, nil
+		}
+	}
+	return user.Now(), nil
+}
+
+// currentMenu renvoie le menu de user pour le jour courant, créé s'il n'existe pas encore
+func currentMenu(user *models.User) (models.DailyMenu, error) {
+	day, err := currentDay(user)
+	if err != nil {
+		return models.DailyMenu{}, err
+	}
+	menu, _, err := fdc.GetOrCreateDailyMenu(user.ID, day)
+	if err != nil {
+		return menu, i18n.Errorf("erreur lors de la création du menu : %w", err)
+	}
+	menu.User = *user
+	return menu, nil
+}
+
+// currentMeals renvoie les repas du menu de user pour le jour courant
+func currentMeals(user *models.User) ([]models.Meal, time.Time, error) {
+	day, err := currentDay(user)
+	if err != nil {
+		return nil, day, err
+	}
+	meals, _ := user.GetMealsByDate(day)
+	return meals, day, nil
+}
+
+// latestMeal renvoie l'indice du dernier repas enregistré parmi meals dont le
+// type est mealType (n'importe lequel si vide), ou -1
+func latestMeal(meals []models.Meal, mealType string) int {
+	latest := -1
+	for i, meal := range meals {
+		if mealType != "" && string(meal.Type) != mealType {
+			continue
+		}
+		if latest < 0 || !meal.LoggedAt.Before(meals[latest].LoggedAt) {
+			latest = i
+		}
+	}
+	return latest
+}
+
+// currentMeal renvoie le dernier repas du type mealType (n'importe lequel si
+// vide) enregistré dans le menu de user pour le jour courant
+func currentMeal(user *models.User, mealType string) (models.Meal, error) {
+	meals, day, err := currentMeals(user)
+	if err != nil {
+		return models.Meal{}, err
+	}
+	i := latestMeal(meals, mealType)
+	switch {
+	case i < 0 && mealType != "":
+		return models.Meal{}, usageErrorf("aucun repas %s le %s : ajoutez-en un avec « gofit addmeal » ou précisez --meal", mealType, i18n.Date(day))
+	case i < 0:
+		return models.Meal{}, usageErrorf("aucun repas le %s : ajoutez-en un avec « gofit addmeal » ou précisez --meal", i18n.Date(day))
+	}
+	return meals[i], nil
+}
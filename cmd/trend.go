@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/lsoulet/gofit/fdc"
+)
+
+// defaultTrendDays est la durée de la période de « gofit trend » sans --from
+const defaultTrendDays = 30
+
+func init() {
+	register(&Command{
+		Name:    "trend",
+		Usage:   "trend [--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--width <caractères>] [--bars <série>]",
+		Summary: "Afficher dans le terminal l'évolution des apports et des mesures de l'utilisateur en sparklines et en barres",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			from := fs.String("from", "", "premier jour de la période JJ/MM/AAAA (30 jours jusqu'à --to par défaut)")
+			to := fs.String("to", "", "dernier jour de la période JJ/MM/AAAA (inclus, jour courant par défaut)")
+			width := fs.Int("width", fdc.DefaultTrendWidth, "largeur des sparklines et des barres, en caractères")
+			bars := fs.String("bars", "", "série à détailler en barres : calories, proteins, carbohydrates, lipids, weight, body_fat ou bmi")
+			return func([]string) error {
+				if *width <= 0 {
+					return usageErrorf("--width doit être un entier positif")
+				}
+				var series string
+				if *bars != "" {
+					var err error
+					if series, err = fdc.ParseTrendSeries(*bars); err != nil {
+						return usageErrorf("--bars : %v", err)
+					}
+				}
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				first, end, err := recentPeriod(&user, *from, *to, defaultTrendDays)
+				if err != nil {
+					return err
+				}
+				trend, err := fdc.GetTrend(user.ID, first, end)
+				if err != nil {
+					return err
+				}
+				return emit(newTrendOut(trend), func() error {
+					fdc.PrintTrend(trend, *width, series)
+					return nil
+				})
+			}
+		},
+	})
+}
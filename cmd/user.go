@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+func init() {
+	register(&Command{
+		Name:    "adduser",
+		Usage:   "adduser --first <prénom> --last <nom> --age <âge> --gender <male|female> --goal <weight_loss|maintenance|muscle_gain> [--timezone <fuseau>]",
+		Summary: "Créer un nouvel utilisateur",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			first := fs.String("first", "", "prénom")
+			last := fs.String("last", "", "nom")
+			age := fs.Int("age", 0, "âge")
+			gender := fs.String("gender", "", "genre : male (homme) ou female (femme)")
+			goal := fs.String("goal", "", "objectif : weight_loss, maintenance ou muscle_gain")
+			timeZone := fs.String("timezone", "", "fuseau horaire IANA (défaut "+models.DefaultTimeZone+")")
+			return func(args []string) error {
+				if *first == "" || *last == "" {
+					return usageErrorf("les options --first et --last sont obligatoires")
+				}
+				if *age <= 0 {
+					return usageErrorf("l'option --age doit être un nombre positif")
+				}
+				g, err := parseGender(*gender)
+				if err != nil {
+					return usageErrorf("--gender : %v", err)
+				}
+				o, err := parseGoal(*goal)
+				if err != nil {
+					return usageErrorf("--goal : %v", err)
+				}
+				if *timeZone != "" {
+					if _, err := parseTimeZone(*timeZone); err != nil {
+						return usageErrorf("--timezone : %v", err)
+					}
+				}
+
+				user, err := fdc.CreateUser(*first, *last, *age, g.(models.Gender), o.(models.Goal), *timeZone)
+				if err != nil {
+					return err
+				}
+				return emit(newUserOut(user), func() error {
+					i18n.Printf("✅ Utilisateur %s %s créé avec succès ! (id %d)\n", user.FirstName, user.LastName, user.ID)
+					return nil
+				})
+			}
+		},
+		Wizard: addUserWizard,
+	})
+
+	register(&Command{
+		Name:    "timezone",
+		Usage:   "timezone <id utilisateur> <fuseau IANA, ex. Europe/Paris>",
+		Summary: "Définir le fuseau horaire d'un utilisateur",
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				if len(args) != 2 {
+					return usageErrorf("un identifiant d'utilisateur et un fuseau sont attendus")
+				}
+				userID, err := parseID(args[0])
+				if err != nil {
+					return usageErrorf("%v", err)
+				}
+				if err := fdc.SetUserTimeZone(userID, args[1]); err != nil {
+					return err
+				}
+				return done("update", fdc.EntityUser, userID,
+					i18n.Sprintf("✅ Fuseau horaire de l'utilisateur %d : %s", userID, args[1]))
+			}
+		},
+	})
+
+	register(&Command{
+		Name:    "addmeasurement",
+		Usage:   "addmeasurement [--user <utilisateur>] <poids kg> <taille cm> [tour de taille] [tour de cou] [tour de hanches]",
+		Summary: "Enregistrer une mesure (poids, taille, tours pour la masse grasse)",
+		Session: true,
+		Setup: func(fs *flag.FlagSet) func([]string) error {
+			return func(args []string) error {
+				// L'utilisateur vient de --user ou de l'utilisateur courant : tous
+				// les arguments sont des mesures, jamais un identifiant
+				user, err := requireUser("--user")
+				if err != nil {
+					return err
+				}
+				if len(args) < 2 || len(args) > 5 {
+					return usageErrorf("un poids et une taille sont attendus")
+				}
+				values := make([]float64, 5)
+				for i, arg := range args {
+					v, err := parsePositiveFloat(arg)
+					if err != nil || v.(float64) == 0 {
+						return usageErrorf("valeur invalide : %s", arg)
+					}
+					values[i] = v.(float64)
+				}
+				m, err := fdc.AddMeasurement(user.ID, values[0], values[1], values[2], values[3], values[4])
+				if err != nil {
+					return err
+				}
+				return emit(newMeasurementOut(m), func() error {
+					i18n.Printf("✅ Mesure %d enregistrée : %.1f kg, IMC %.2f\n", m.ID, m.Weight, m.BMI)
+					return nil
+				})
+			}
+		},
+	})
+}
+
+func addUserWizard(in wizard.LineReader, args []string) error {
+	w := wizard.Wizard{
+		Title: i18n.T("Création d'un utilisateur"),
+		Steps: []wizard.Step{
+			{Key: "first", Prompt: i18n.T("Prénom de l'utilisateur :"), Label: i18n.T("Prénom"), Parse: parser(parseNonEmpty)},
+			{Key: "last", Prompt: i18n.T("Nom de l'utilisateur :"), Label: i18n.T("Nom"), Parse: parser(parseNonEmpty)},
+			{Key: "age", Prompt: i18n.T("Âge de l'utilisateur :"), Label: i18n.T("Âge"), Parse: parser(parsePositiveInt)},
+			{
+				Key:    "gender",
+				Prompt: i18n.T("Choisissez le genre :"),
+				Label:  i18n.T("Genre"),
+				Choices: func(wizard.Answers) ([]wizard.Choice, error) {
+					return []wizard.Choice{
+						{Label: models.Male.Label(), Value: models.Male},
+						{Label: models.Female.Label(), Value: models.Female},
+					}, nil
+				},
+			},
+			{
+				Key:    "goal",
+				Prompt: i18n.T("Choisissez l'objectif :"),
+				Label:  i18n.T("Objectif"),
+				Choices: func(wizard.Answers) ([]wizard.Choice, error) {
+					return []wizard.Choice{
+						{Label: models.WeightLoss.Label(), Value: models.WeightLoss},
+						{Label: models.Maintenance.Label(), Value: models.Maintenance},
+						{Label: models.MuscleGain.Label(), Value: models.MuscleGain},
+					}, nil
+				},
+			},
+			{
+				Key:     "timezone",
+				Prompt:  i18n.T("Fuseau horaire :"),
+				Label:   i18n.T("Fuseau horaire"),
+				Default: func(wizard.Answers) string { return models.DefaultTimeZone },
+				Parse:   parser(parseTimeZone),
+			},
+		},
+		Confirm: true,
+	}
+	answers, err := w.Run(in, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	first, last := answers["first"].(string), answers["last"].(string)
+	user, err := fdc.CreateUser(first, last, answers["age"].(int), answers["gender"].(models.Gender),
+		answers["goal"].(models.Goal), answers["timezone"].(string))
+	if err != nil {
+		return i18n.Errorf("erreur lors de la création de l'utilisateur : %w", err)
+	}
+	i18n.Printf("\n✅ Utilisateur %s %s créé avec succès ! (id %d)\n", first, last, user.ID)
+	return nil
+}
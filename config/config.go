@@ -0,0 +1,91 @@
+// Package config lit et écrit les préférences de l'utilisateur, stockées
+// dans un fichier YAML propre au compte système.
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lsoulet/gofit/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Config regroupe les préférences enregistrées
+type Config struct {
+	// Lang est la langue de l'interface (fr, en) ; vide pour suivre l'environnement
+	Lang string `yaml:"lang,omitempty"`
+	// User est l'utilisateur courant choisi par « gofit use » ; 0 si aucun
+	User uint `yaml:"user,omitempty"`
+	// Date est le jour courant (AAAA-MM-JJ) ; vide pour suivre la date du jour
+	Date string `yaml:"date,omitempty"`
+	// Tolerance est la marge par défaut de « gofit adherence », en pourcentage
+	// de l'objectif ; 0 pour la marge par défaut
+	Tolerance float64 `yaml:"tolerance,omitempty"`
+}
+
+// DateLayout est le format de Config.Date, indépendant de la langue
+const DateLayout = "2006-01-02"
+
+// Path renvoie le chemin du fichier de configuration : GOFIT_CONFIG, sinon
+// gofit/config.yaml dans le dossier de configuration du système
+func Path() (string, error) {
+	if path := os.Getenv("GOFIT_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofit", "config.yaml"), nil
+}
+
+// HistoryPath renvoie le chemin de l'historique de la boucle interactive,
+// rangé à côté du fichier de configuration
+func HistoryPath() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "history"), nil
+}
+
+// Load lit la configuration ; un fichier absent donne une configuration vide
+func Load() (Config, error) {
+	var cfg Config
+	path, err := Path()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, i18n.Errorf("erreur lors de la lecture de la configuration : %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, i18n.Errorf("erreur lors de la lecture de la configuration : %w", err)
+	}
+	return cfg, nil
+}
+
+// Save écrit la configuration, en créant son dossier si besoin
+func Save(cfg Config) error {
+	path, err := Path()
+	if err != nil {
+		return i18n.Errorf("erreur lors de l'écriture de la configuration : %w", err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return i18n.Errorf("erreur lors de l'écriture de la configuration : %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return i18n.Errorf("erreur lors de l'écriture de la configuration : %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return i18n.Errorf("erreur lors de l'écriture de la configuration : %w", err)
+	}
+	return nil
+}
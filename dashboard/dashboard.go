@@ -0,0 +1,176 @@
+// Package dashboard affiche le tableau de bord plein écran de gofit : menu du
+// jour par type de repas, progression vers les objectifs, apports restants et
+// tendance du poids, avec des touches pour ajouter des aliments et changer de
+// jour ou d'utilisateur.
+package dashboard
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/lineedit"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/tui"
+	"github.com/lsoulet/gofit/wizard"
+)
+
+// WeightDays est la période de la tendance du poids, en jours jusqu'au jour affiché
+const WeightDays = 30
+
+// Options règle le tableau de bord
+type Options struct {
+	// UserID est l'utilisateur affiché au démarrage
+	UserID uint
+	// Day est le jour affiché au démarrage, dans le fuseau de l'utilisateur
+	Day time.Time
+	// Tolerance est la marge, en pourcentage de l'objectif, dans laquelle un
+	// apport est considéré comme atteint
+	Tolerance float64
+	// Color colore les barres de progression selon l'écart à l'objectif
+	Color bool
+	// AddFood ajoute les aliments décrits par text au menu du jour day de
+	// l'utilisateur userID, day étant minuit dans son fuseau ; les questions
+	// éventuelles sont lues sur in. nil désactive la touche a.
+	AddFood func(in wizard.LineReader, userID uint, day time.Time, text string) error
+}
+
+// dashboard est l'état du tableau de bord : l'utilisateur et le jour
+// affichés, et les données chargées pour eux
+type dashboard struct {
+	opts Options
+	// day est le jour affiché, à minuit UTC comme models.CalendarDay
+	day  time.Time
+	user models.User
+	menu models.DailyMenu
+	// weight est l'évolution du poids sur les WeightDays jours jusqu'à day
+	weight fdc.TrendSeries
+	// status est le message affiché au-dessus des touches
+	status string
+}
+
+// Run affiche le tableau de bord sur le terminal in jusqu'à ce que
+// l'utilisateur le quitte. L'écran est redessiné après chaque touche ; r le
+// redessine après un redimensionnement du terminal.
+func Run(in *os.File, out io.Writer, opts Options) error {
+	d := &dashboard{opts: opts, day: models.CalendarDay(opts.Day)}
+	if err := d.load(opts.UserID); err != nil {
+		return err
+	}
+	screen, err := tui.Start(in, out)
+	if err != nil {
+		return err
+	}
+	defer screen.Stop()
+
+	for {
+		screen.Draw(d.render(screen.Size()))
+		key, err := screen.ReadKey()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		d.status = ""
+		switch {
+		case key.Special == tui.Interrupt, key.Special == tui.Escape, key.Rune == 'q':
+			return nil
+		case key.Special == tui.Left, key.Rune == 'h':
+			d.day = d.day.AddDate(0, 0, -1)
+		case key.Special == tui.Right, key.Rune == 'l':
+			d.day = d.day.AddDate(0, 0, 1)
+		case key.Rune == 't':
+			d.day = models.CalendarDay(d.user.Now())
+		case key.Rune == 'u':
+			d.switchUser(1)
+			continue
+		case key.Rune == 'U':
+			d.switchUser(-1)
+			continue
+		case key.Rune == 'a':
+			if err := d.addFood(screen, lineedit.NewShared(in, screen.Input(), out), out); err != nil {
+				return err
+			}
+		case key.Rune == 'r':
+		default:
+			continue
+		}
+		d.reload()
+	}
+}
+
+// load charge l'utilisateur userID, son menu du jour affiché et la tendance
+// de son poids ; ses autres menus et mesures ne sont pas lus
+func (d *dashboard) load(userID uint) error {
+	user, weight, err := fdc.GetWeightTrend(userID, d.day.AddDate(0, 0, 1-WeightDays), d.day.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	menu, err := fdc.GetDailyMenu(user.ID, d.day)
+	if err != nil {
+		return err
+	}
+	d.user, d.menu, d.weight = user, menu, weight
+	return nil
+}
+
+// reload recharge l'utilisateur affiché ; une erreur est affichée dans la
+// ligne d'état sans quitter le tableau de bord
+func (d *dashboard) reload() {
+	if err := d.load(d.user.ID); err != nil {
+		d.status = i18n.Sprintf("Erreur : %v", err)
+	}
+}
+
+// localDay renvoie le jour affiché à minuit dans le fuseau de l'utilisateur
+func (d *dashboard) localDay() time.Time {
+	return time.Date(d.day.Year(), d.day.Month(), d.day.Day(), 0, 0, 0, 0, d.user.Location())
+}
+
+// switchUser passe à l'utilisateur suivant (1) ou précédent (-1), par ordre
+// d'identifiant, en gardant le jour affiché
+func (d *dashboard) switchUser(delta int) {
+	ids, err := fdc.GetUserIDs()
+	if err != nil {
+		d.status = i18n.Sprintf("Erreur : %v", err)
+		return
+	}
+	if len(ids) < 2 {
+		d.status = i18n.T("Aucun autre utilisateur.")
+		return
+	}
+	i := slices.Index(ids, d.user.ID)
+	next := ids[(i+delta+len(ids))%len(ids)]
+	if err := d.load(next); err != nil {
+		d.status = i18n.Sprintf("Erreur : %v", err)
+	}
+}
+
+// addFood quitte le plein écran le temps de demander les aliments à ajouter
+// au jour affiché, puis de laisser lire le résultat de l'ajout. editor lit le
+// terminal à travers le tampon de screen, pour toutes les questions.
+func (d *dashboard) addFood(screen *tui.Screen, editor *lineedit.Editor, out io.Writer) error {
+	if d.opts.AddFood == nil {
+		return nil
+	}
+	screen.Suspend()
+	i18n.Fprintf(out, "Ajout au menu de %s %s du %s (ex. 150g chicken breast, 1 cup rice for lunch)\n",
+		d.user.FirstName, d.user.LastName, i18n.Date(d.day))
+	text, err := editor.ReadLine(i18n.T("Aliments : "))
+	if err == nil && strings.TrimSpace(text) != "" {
+		if err := d.opts.AddFood(editor, d.user.ID, d.localDay(), text); err != nil {
+			i18n.Fprintf(out, "Erreur : %v\n", err)
+			d.status = i18n.T("Aucun aliment ajouté.")
+		} else {
+			d.status = i18n.T("Aliments ajoutés.")
+		}
+		editor.ReadLine(i18n.T("Appuyez sur Entrée pour revenir au tableau de bord."))
+	}
+	return screen.Resume()
+}
@@ -0,0 +1,173 @@
+package dashboard
+
+import (
+	"math"
+	"strings"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/textchart"
+	"github.com/lsoulet/gofit/tui"
+)
+
+// mealTypes est l'ordre des repas dans le menu du jour
+var mealTypes = []models.MealType{models.Breakfast, models.Lunch, models.Dinner, models.Snack}
+
+// Couleurs des barres de progression
+const (
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	red    = "\x1b[31m"
+	bold   = "\x1b[1m"
+	reset  = "\x1b[0m"
+)
+
+// labelWidth est la largeur des noms de repas et d'apports
+const labelWidth = 15
+
+// render renvoie les lignes de l'écran de width × height caractères : les
+// sections en haut, la ligne d'état et les touches en bas. Les sections trop
+// longues pour la hauteur sont coupées.
+func (d *dashboard) render(width, height int) []string {
+	var lines []string
+	lines = append(lines, d.header(width)...)
+	lines = append(lines, "")
+	lines = append(lines, d.progress(width)...)
+	lines = append(lines, "")
+	lines = append(lines, d.meals()...)
+	lines = append(lines, "")
+	lines = append(lines, d.weightTrend(width)...)
+
+	keys := i18n.T("←/→ jour  t aujourd'hui  a ajouter  u/U utilisateur  r actualiser  q quitter")
+	if d.opts.AddFood == nil {
+		keys = i18n.T("←/→ jour  t aujourd'hui  u/U utilisateur  r actualiser  q quitter")
+	}
+	footer := []string{d.status, d.style(keys, bold)}
+	if room := height - len(footer); len(lines) > room {
+		lines = lines[:max(room, 0)]
+	}
+	for len(lines) < height-len(footer) {
+		lines = append(lines, "")
+	}
+	return append(lines, footer...)
+}
+
+// header renvoie le titre : l'utilisateur et le jour affichés
+func (d *dashboard) header(width int) []string {
+	title := i18n.Sprintf("gofit · %s %s · %s", d.user.FirstName, d.user.LastName, i18n.Date(d.day))
+	if d.day.Equal(models.CalendarDay(d.user.Now())) {
+		title += i18n.T(" (aujourd'hui)")
+	}
+	return []string{d.style(title, bold), strings.Repeat("─", min(width, tui.Width(title)+10))}
+}
+
+// progress renvoie une barre par apport suivi : l'apport du jour par rapport
+// à l'objectif, en pourcentage, et ce qu'il reste à consommer
+func (d *dashboard) progress(width int) []string {
+	lines := []string{d.style(i18n.T("Objectifs du jour"), bold)}
+	c, p, g, l, _ := d.menu.GetDailyMacroSummary()
+	intake := fdc.Nutrients{Calories: c, Proteins: p, Carbohydrates: g, Lipids: l}
+	target := fdc.Targets(d.user)
+	if target.Calories <= 0 {
+		lines = append(lines, i18n.Sprintf("aucun objectif nutritionnel pour %s %s : enregistrez une mesure (gofit addmeasurement)",
+			d.user.FirstName, d.user.LastName))
+		for _, n := range fdc.TrackedNutrients {
+			lines = append(lines, tui.Pad(n.Label(), labelWidth)+i18n.Sprintf("%s %s", i18n.Float(intake.Get(n), 0), n.Unit()))
+		}
+		return lines
+	}
+
+	// La barre prend la place laissée par le nom, les valeurs et le reste
+	barWidth := min(max(width-labelWidth-48, 10), 40)
+	for _, n := range fdc.TrackedNutrients {
+		a := fdc.Adherence{Intake: intake.Get(n), Target: target.Get(n)}
+		values := i18n.Sprintf("%s / %s %s", i18n.Float(a.Intake, 0), i18n.Float(a.Target, 0), n.Unit())
+		var rest string
+		if gap := a.Gap(); gap <= 0 {
+			rest = i18n.Sprintf("reste %s %s", i18n.Float(-gap, 0), n.Unit())
+		} else {
+			rest = i18n.Sprintf("dépassé de %s %s", i18n.Float(gap, 0), n.Unit())
+		}
+		lines = append(lines, tui.Pad(n.Label(), labelWidth)+
+			d.style(textchart.Bar(a.Intake, a.Target, barWidth), d.progressColor(a))+" "+
+			tui.Pad(values, 20)+tui.Pad(i18n.Sprintf("%s %%", i18n.Float(a.Percent(), 0)), 7)+rest)
+	}
+	return lines
+}
+
+// progressColor renvoie la couleur de la barre d'un apport : vert dans la
+// marge de tolérance, jaune puis rouge au-delà de l'objectif ; un apport
+// encore en dessous, la journée n'étant pas finie, n'est pas coloré
+func (d *dashboard) progressColor(a fdc.Adherence) string {
+	switch {
+	case a.Within(d.opts.Tolerance):
+		return green
+	case a.Gap() < 0:
+		return ""
+	case a.Percent()-100 <= 2*d.opts.Tolerance:
+		return yellow
+	}
+	return red
+}
+
+// meals renvoie le menu du jour, repas par repas dans l'ordre de la journée,
+// avec les calories de chaque repas et de chacun de ses aliments
+func (d *dashboard) meals() []string {
+	lines := []string{d.style(i18n.T("Menu du jour"), bold)}
+	if len(d.menu.Meals) == 0 {
+		return append(lines, i18n.T("Aucun repas ce jour-là."))
+	}
+	for _, t := range mealTypes {
+		var kcal float64
+		var items []string
+		for _, meal := range d.menu.Meals {
+			if meal.Type != t {
+				continue
+			}
+			kcal += meal.Calories
+			for _, item := range meal.Items {
+				items = append(items, "  "+tui.Pad(item.Name, 40)+
+					i18n.Sprintf("%s g · %s kcal", i18n.Float(item.Quantity, 0), i18n.Float(item.Calories, 0)))
+			}
+			// Un repas copié d'un repas type n'a pas d'aliments détaillés
+			if len(meal.Items) == 0 && meal.Description != "" {
+				items = append(items, "  "+tui.Pad(meal.Description, 40)+
+					i18n.Sprintf("%s kcal", i18n.Float(meal.Calories, 0)))
+			}
+		}
+		if len(items) == 0 {
+			lines = append(lines, tui.Pad(string(t), labelWidth)+"-")
+			continue
+		}
+		lines = append(lines, tui.Pad(string(t), labelWidth)+i18n.Sprintf("%s kcal", i18n.Float(kcal, 0)))
+		lines = append(lines, items...)
+	}
+	return lines
+}
+
+// weightTrend renvoie la sparkline du poids sur les WeightDays derniers jours,
+// avec la dernière mesure et son écart à la première
+func (d *dashboard) weightTrend(width int) []string {
+	lines := []string{d.style(i18n.Sprintf("Poids sur %d jours", WeightDays), bold)}
+	st := d.weight.Stats()
+	if st.Count == 0 {
+		return append(lines, i18n.T("Aucune mesure sur la période."))
+	}
+	spark := textchart.Sparkline(d.weight.Values, min(max(width-40, 10), fdc.DefaultTrendWidth))
+	change := st.Last - st.First
+	sign := "+"
+	if change < 0 {
+		sign = "-"
+	}
+	return append(lines, spark+"  "+i18n.Sprintf("%s kg (%s%s kg depuis le %s)", i18n.Float(st.Last, 1),
+		sign, i18n.Float(math.Abs(change), 1), i18n.Date(d.weight.Dates[0])))
+}
+
+// style entoure s de la séquence de couleur code, si les couleurs sont actives
+func (d *dashboard) style(s, code string) string {
+	if !d.opts.Color || code == "" {
+		return s
+	}
+	return code + s + reset
+}
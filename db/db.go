@@ -1,28 +1,58 @@
 package db
 
 import (
-	"log"
-
+	"github.com/lsoulet/gofit/i18n"
 	"github.com/lsoulet/gofit/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
+// TimeZone=UTC : les jours calendaires (colonnes date) sont échangés à minuit UTC
+const dsn = "host=localhost user=postgres password=postgres dbname=gofitdb port=5432 sslmode=disable TimeZone=UTC"
+
 func InitDatabase() error {
-	dsn := "host=localhost user=postgres password=postgres dbname=gofitdb port=5432 sslmode=disable"
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
-		log.Fatalf("Erreur lors de la connexion à la base de données : %v", err)
-		return err
+		return i18n.Errorf("erreur lors de la connexion à la base de données : %w", err)
 	}
 
-	err = DB.AutoMigrate(&models.User{}, &models.DailyMenu{}, &models.Meal{})
-	if err != nil {
-		return err
-	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := prepareLegacyRelations(tx); err != nil {
+			return err
+		}
+
+		newFoodCache := !tx.Migrator().HasTable(&models.Food{})
+		if err := tx.AutoMigrate(&models.User{}, &models.DailyMenu{}, &models.Meal{}, &models.MealItem{}, &models.Measurement{}, &models.AuditLog{},
+			&models.Food{}, &models.FoodNutrient{}, &models.FavoriteFood{}); err != nil {
+			return err
+		}
+
+		if newFoodCache {
+			if err := seedFoodCache(tx); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		if err := migrateLegacyRelations(tx); err != nil {
+			return err
+		}
+
+		if err := ensureAuditAppendOnly(tx); err != nil {
+			return err
+		}
+
+		return EnsureDailyMenuUniqueIndex(tx)
+	})
+}
+
+// Open se connecte à la base sans migration ni journal des requêtes, pour les
+// lectures qui doivent rester rapides et silencieuses comme la complétion du shell
+func Open() error {
+	var err error
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	return err
 }
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"log"
+
+	"github.com/lsoulet/gofit/i18n"
+	"gorm.io/gorm"
+)
+
+// prepareLegacyRelations nettoie les données avant l'AutoMigrate : les repas
+// types étaient enregistrés avec daily_menu_id = 0, ce qui empêcherait la
+// création de la clé étrangère vers daily_menus.
+func prepareLegacyRelations(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("meals", "daily_menu_id") {
+		return nil
+	}
+	if err := tx.Exec("UPDATE meals SET daily_menu_id = NULL WHERE daily_menu_id = 0").Error; err != nil {
+		return i18n.Errorf("erreur lors du nettoyage des repas types : %w", err)
+	}
+	return nil
+}
+
+// migrateLegacyRelations reprend le contenu des anciennes tables de jointure
+// (user_dailymenus, dailymenu_meals) puis les supprime : un menu appartient
+// désormais à un seul utilisateur et un repas à un seul menu.
+func migrateLegacyRelations(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	if m.HasTable("user_dailymenus") {
+		if err := tx.Exec(`UPDATE daily_menus SET user_id = ud.user_id
+			FROM user_dailymenus ud
+			WHERE ud.daily_menu_id = daily_menus.id
+			AND (daily_menus.user_id IS NULL OR daily_menus.user_id = 0)`).Error; err != nil {
+			return i18n.Errorf("erreur lors de la migration de user_dailymenus : %w", err)
+		}
+		if err := m.DropTable("user_dailymenus"); err != nil {
+			return i18n.Errorf("erreur lors de la suppression de user_dailymenus : %w", err)
+		}
+	}
+
+	if m.HasTable("dailymenu_meals") {
+		var links []struct {
+			DailyMenuID uint
+			MealID      uint
+		}
+		if err := tx.Raw("SELECT daily_menu_id, meal_id FROM dailymenu_meals ORDER BY meal_id, daily_menu_id").
+			Scan(&links).Error; err != nil {
+			return i18n.Errorf("erreur lors de la lecture de dailymenu_meals : %w", err)
+		}
+
+		// Le premier menu d'un repas garde la ligne existante, les suivants
+		// reçoivent une copie pour que chaque menu conserve ses valeurs.
+		assigned := make(map[uint]bool)
+		for _, link := range links {
+			if !assigned[link.MealID] {
+				if err := tx.Exec("UPDATE meals SET daily_menu_id = ? WHERE id = ?", link.DailyMenuID, link.MealID).Error; err != nil {
+					return i18n.Errorf("erreur lors du rattachement du repas %d : %w", link.MealID, err)
+				}
+				assigned[link.MealID] = true
+				continue
+			}
+			if err := tx.Exec(`INSERT INTO meals (daily_menu_id, type, description, calories, proteins, carbohydrates, lipids)
+				SELECT ?, type, description, calories, proteins, carbohydrates, lipids FROM meals WHERE id = ?`,
+				link.DailyMenuID, link.MealID).Error; err != nil {
+				return i18n.Errorf("erreur lors de la copie du repas %d : %w", link.MealID, err)
+			}
+		}
+
+		if err := m.DropTable("dailymenu_meals"); err != nil {
+			return i18n.Errorf("erreur lors de la suppression de dailymenu_meals : %w", err)
+		}
+	}
+
+	return nil
+}
+
+// EnsureDailyMenuUniqueIndex crée l'index garantissant un seul menu actif par
+// utilisateur et par jour ; les menus dans la corbeille n'en font pas partie.
+// Tant que des doublons existent, l'index n'est pas créé et un avertissement
+// invite à lancer `gofit mergemenus`.
+func EnsureDailyMenuUniqueIndex(tx *gorm.DB) error {
+	m := tx.Migrator()
+	if m.HasIndex("daily_menus", "idx_daily_menus_user_day_active") {
+		return nil
+	}
+
+	// Ancien index, antérieur à la suppression logique des menus
+	if m.HasIndex("daily_menus", "idx_daily_menus_user_day") {
+		if err := m.DropIndex("daily_menus", "idx_daily_menus_user_day"); err != nil {
+			return i18n.Errorf("erreur lors de la suppression de l'ancien index des menus : %w", err)
+		}
+	}
+
+	var duplicates int64
+	if err := tx.Raw(`SELECT COUNT(*) FROM (
+		SELECT user_id, date FROM daily_menus WHERE deleted_at IS NULL
+		GROUP BY user_id, date HAVING COUNT(*) > 1
+	) d`).Scan(&duplicates).Error; err != nil {
+		return i18n.Errorf("erreur lors de la recherche des menus en double : %w", err)
+	}
+	if duplicates > 0 {
+		log.Print(i18n.Sprintf("⚠️ %d jour(s) ont plusieurs menus pour un même utilisateur, lancez 'gofit mergemenus' pour les fusionner", duplicates))
+		return nil
+	}
+
+	if err := tx.Exec(`CREATE UNIQUE INDEX idx_daily_menus_user_day_active
+		ON daily_menus (user_id, date) WHERE deleted_at IS NULL`).Error; err != nil {
+		return i18n.Errorf("erreur lors de la création de l'index des menus : %w", err)
+	}
+	return nil
+}
+
+// ensureAuditAppendOnly installe le trigger qui interdit de modifier ou de
+// supprimer une ligne du journal d'audit
+func ensureAuditAppendOnly(tx *gorm.DB) error {
+	if err := tx.Exec(`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs est en ajout seul';
+		END;
+		$$ LANGUAGE plpgsql`).Error; err != nil {
+		return i18n.Errorf("erreur lors de la création de la fonction d'audit : %w", err)
+	}
+	if err := tx.Exec(`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`).Error; err != nil {
+		return i18n.Errorf("erreur lors de la création du trigger d'audit : %w", err)
+	}
+	if err := tx.Exec(`CREATE TRIGGER audit_logs_append_only
+		BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`).Error; err != nil {
+		return i18n.Errorf("erreur lors de la création du trigger d'audit : %w", err)
+	}
+	return nil
+}
+
+// seedFoodCache remplit le cache des aliments à sa création avec les aliments
+// déjà consommés : leurs valeurs pour 100 g se déduisent du dernier ajout.
+func seedFoodCache(tx *gorm.DB) error {
+	if err := tx.Exec(`INSERT INTO foods (fdc_id, name, calories, proteins, carbohydrates, lipids, fetched_at)
+		SELECT fdc_id, name, calories * 100 / quantity, proteins * 100 / quantity,
+			carbohydrates * 100 / quantity, lipids * 100 / quantity, logged_at
+		FROM meal_items mi
+		WHERE id = (SELECT MAX(id) FROM meal_items WHERE fdc_id = mi.fdc_id AND quantity > 0)
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		return i18n.Errorf("erreur lors de la création du cache des aliments : %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package export met en page le rapport nutritionnel d'un utilisateur pour
+// le partager : une page HTML autonome ou un document PDF, avec les tableaux
+// des apports, le suivi des objectifs et les graphiques.
+package export
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/fdc"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// Format est un format d'export
+type Format string
+
+const (
+	HTML Format = "html"
+	PDF  Format = "pdf"
+)
+
+// ParseFormat valide un nom de format
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case HTML, PDF:
+		return f, nil
+	}
+	return "", i18n.Errorf("format d'export inconnu : %q (html ou pdf)", s)
+}
+
+// FormatOf déduit le format de l'extension de filename (.html, .htm ou .pdf)
+func FormatOf(filename string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".html", ".htm":
+		return HTML, true
+	case ".pdf":
+		return PDF, true
+	}
+	return "", false
+}
+
+// Report rassemble les données du rapport exporté
+type Report struct {
+	User models.User
+	From time.Time
+	// To est exclu de la période
+	To        time.Time
+	Generated time.Time
+	Days      []fdc.DailyTotals
+	// Adherence est nil si l'utilisateur n'a pas d'objectif nutritionnel
+	Adherence *fdc.AdherenceReport
+	// NutritionChart et BodyChart sont des images PNG, vides s'il y a trop
+	// peu de jours ou de mesures sur la période
+	NutritionChart []byte
+	BodyChart      []byte
+}
+
+// Build rassemble le rapport de l'utilisateur userID sur la période de from
+// à to (exclu) ; tolerance est la marge du suivi des objectifs, en %
+func Build(userID uint, from, to time.Time, tolerance float64) (Report, error) {
+	user, err := fdc.GetUser(userID)
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{User: user, From: from, To: to, Generated: user.Now()}
+	if r.Days, err = fdc.GetNutritionalReport(fdc.ReportFilter{UserID: user.ID, From: from, To: to}); err != nil {
+		return r, err
+	}
+	if fdc.Targets(user).Calories > 0 {
+		adherence, err := fdc.GetAdherence(user.ID, from, to, tolerance)
+		if err != nil {
+			return r, err
+		}
+		r.Adherence = &adherence
+	}
+
+	// Les graphiques ne portent que sur la période et ne sont tracés qu'à
+	// partir de deux jours ou de deux mesures
+	opts := models.ChartOptions{From: from, To: to}
+	if r.NutritionChart, err = render(&user, models.ChartNutrition, opts); err != nil {
+		return r, i18n.Errorf("erreur lors du tracé du graphique des apports : %w", err)
+	}
+	if r.BodyChart, err = render(&user, models.ChartBody, opts); err != nil {
+		return r, i18n.Errorf("erreur lors du tracé du graphique des mesures : %w", err)
+	}
+	return r, nil
+}
+
+// render renvoie le graphique PNG kind de user ; nil s'il n'a pas assez de points
+func render(user *models.User, kind models.ChartKind, opts models.ChartOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := user.RenderChart(&buf, kind, opts); errors.Is(err, models.ErrChartData) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write écrit le rapport dans w au format f
+func Write(w io.Writer, f Format, r Report) error {
+	doc := newDocument(r)
+	if f == PDF {
+		return writePDF(w, doc)
+	}
+	return writeHTML(w, doc)
+}
+
+// document est la mise en page du rapport, commune aux deux formats ; ses
+// textes sont déjà traduits
+type document struct {
+	Title    string
+	Subtitle []string
+	Sections []section
+}
+
+// section contient un titre suivi de paragraphes, d'un tableau et d'une image
+type section struct {
+	Title string
+	Text  []string
+	Table *table
+	// Image est une image PNG
+	Image []byte
+}
+
+// table est un tableau dont la première colonne est un libellé et les
+// suivantes des nombres, alignés à droite
+type table struct {
+	Header []string
+	Rows   [][]string
+	// Footer sont les lignes de total, mises en évidence
+	Footer [][]string
+}
+
+func newDocument(r Report) document {
+	user := r.User
+	doc := document{
+		Title: i18n.Sprintf("Rapport nutritionnel de %s %s", user.FirstName, user.LastName),
+		Subtitle: []string{
+			i18n.Sprintf("Du %s au %s, généré le %s", i18n.Date(r.From), i18n.Date(r.To.AddDate(0, 0, -1)),
+				i18n.DateTime(r.Generated)),
+			profile(user),
+		},
+	}
+
+	days := section{Title: i18n.T("Apports journaliers")}
+	if len(r.Days) == 0 {
+		days.Text = []string{i18n.T("Aucun menu journalier enregistré.")}
+	} else {
+		t := &table{Header: []string{i18n.T("Date"), i18n.T("Calories (kcal)"), i18n.T("Protéines (g)"),
+			i18n.T("Glucides (g)"), i18n.T("Lipides (g)")}}
+		for _, day := range r.Days {
+			t.Rows = append(t.Rows, append([]string{i18n.Date(day.Date)}, nutrientCells(day.Nutrients)...))
+		}
+		for _, s := range fdc.SummarizeByUser(r.Days) {
+			t.Footer = append(t.Footer,
+				append([]string{i18n.T("Total")}, nutrientCells(s.Total)...),
+				append([]string{i18n.T("Moyenne / jour")}, nutrientCells(s.Average)...))
+		}
+		days.Table = t
+	}
+	doc.Sections = append(doc.Sections, days)
+
+	goals := section{Title: i18n.T("Suivi des objectifs")}
+	switch a := r.Adherence; {
+	case a == nil:
+		goals.Text = []string{i18n.T("Aucun objectif nutritionnel : enregistrez une mesure pour le calculer.")}
+	case len(a.Days) == 0:
+		goals.Text = []string{i18n.T("Aucun menu journalier enregistré.")}
+	default:
+		goals.Text = []string{i18n.Sprintf("%d jour(s) enregistré(s), marge de tolérance ± %s %%",
+			len(a.Days), i18n.Float(a.Tolerance, 0))}
+		t := &table{Header: []string{i18n.T("Apport"), i18n.T("Objectif / jour"), i18n.T("Total"), i18n.T("Objectif"),
+			i18n.T("Atteint"), i18n.T("Jours dans la marge"), i18n.T("Meilleure série")}}
+		for _, n := range a.Nutrients {
+			unit := n.Nutrient.Unit()
+			t.Rows = append(t.Rows, []string{
+				n.Nutrient.Label(),
+				i18n.Sprintf("%s %s", i18n.Float(a.Target.Get(n.Nutrient), 0), unit),
+				i18n.Sprintf("%s %s", i18n.Float(n.Period.Intake, 0), unit),
+				i18n.Sprintf("%s %s", i18n.Float(n.Period.Target, 0), unit),
+				i18n.Sprintf("%s %%", i18n.Float(n.Period.Percent(), 0)),
+				i18n.Sprintf("%d / %d", n.DaysWithin, len(a.Days)),
+				i18n.Sprintf("%d j", n.LongestStreak),
+			})
+		}
+		goals.Table = t
+	}
+	doc.Sections = append(doc.Sections, goals)
+
+	if len(r.NutritionChart) > 0 {
+		doc.Sections = append(doc.Sections, section{Title: i18n.T("Évolution des apports"), Image: r.NutritionChart})
+	}
+	if len(r.BodyChart) > 0 {
+		doc.Sections = append(doc.Sections, section{Title: i18n.T("Évolution de l'IMC et de la masse grasse"), Image: r.BodyChart})
+	}
+	return doc
+}
+
+// profile décrit l'utilisateur : âge, sexe, objectif et dernière mesure
+func profile(user models.User) string {
+	parts := []string{}
+	if user.Age > 0 {
+		parts = append(parts, i18n.Sprintf("%d ans", user.Age))
+	}
+	if user.Gender != "" {
+		parts = append(parts, strings.ToLower(user.Gender.Label()))
+	}
+	if user.Goal != "" {
+		parts = append(parts, i18n.Sprintf("objectif %s", strings.ToLower(user.Goal.Label())))
+	}
+	if n := len(user.Measurements); n > 0 {
+		m := user.Measurements[n-1]
+		parts = append(parts, i18n.Sprintf("%s kg, %s cm le %s", i18n.Float(m.Weight, 1), i18n.Float(m.Height, 0),
+			i18n.Date(m.Date.In(user.Location()))))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func nutrientCells(n fdc.Nutrients) []string {
+	return []string{i18n.Float(n.Calories, 0), i18n.Float(n.Proteins, 1), i18n.Float(n.Carbohydrates, 1),
+		i18n.Float(n.Lipids, 1)}
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"encoding/base64"
+	"html/template"
+	"io"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// page est une page HTML autonome : styles en ligne et images incluses en
+// data URI, sans ressource externe
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"png": func(b []byte) template.URL {
+		return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(b))
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Doc.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { font-size: 1.6rem; margin-bottom: .3rem; }
+h2 { font-size: 1.2rem; margin-top: 2rem; border-bottom: 1px solid #ccc; padding-bottom: .2rem; }
+.subtitle { color: #666; margin: .1rem 0; }
+table { border-collapse: collapse; width: 100%; margin: .8rem 0; font-size: .9rem; }
+th, td { border: 1px solid #ddd; padding: .3rem .6rem; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f2f2f2; }
+tfoot td { font-weight: bold; background: #fafafa; }
+img { max-width: 100%; }
+@media print { body { margin: 0; max-width: none; } h2 { break-after: avoid; } img, table { break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{.Doc.Title}}</h1>
+{{range .Doc.Subtitle}}{{if .}}<p class="subtitle">{{.}}</p>
+{{end}}{{end}}
+{{- range .Doc.Sections}}
+<h2>{{.Title}}</h2>
+{{range .Text}}<p>{{.}}</p>
+{{end}}
+{{- with .Table}}<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+{{- if .Footer}}
+<tfoot>
+{{range .Footer}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tfoot>
+{{- end}}
+</table>
+{{end}}
+{{- if .Image}}<img src="{{png .Image}}" alt="{{.Title}}">
+{{end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// writeHTML écrit doc sous la forme d'une page HTML autonome
+func writeHTML(w io.Writer, doc document) error {
+	if err := page.Execute(w, struct {
+		Lang string
+		Doc  document
+	}{string(i18n.Current()), doc}); err != nil {
+		return i18n.Errorf("erreur lors de l'écriture du rapport HTML : %w", err)
+	}
+	return nil
+}
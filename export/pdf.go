@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// Mise en page du PDF, en millimètres
+const (
+	pdfMargin    = 15
+	pdfLineSize  = 6
+	pdfCellSize  = 6
+	pdfCellInset = 2
+)
+
+// writePDF écrit doc sous la forme d'un document PDF A4. Les polices
+// standard du PDF ne couvrent que l'alphabet latin (cp1252).
+func writePDF(w io.Writer, doc document) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetCreator("gofit", true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 5)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, strconv.Itoa(pdf.PageNo())+" / {nb}", "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*pdfMargin
+	bottom := pageHeight - pdfMargin
+	// ensure passe à la page suivante s'il reste moins de h millimètres
+	ensure := func(h float64) bool {
+		if pdf.GetY()+h <= bottom {
+			return false
+		}
+		pdf.AddPage()
+		return true
+	}
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.MultiCell(0, 9, tr(doc.Title), "", "L", false)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	for _, line := range doc.Subtitle {
+		if line != "" {
+			pdf.MultiCell(0, 5, tr(line), "", "L", false)
+		}
+	}
+	pdf.SetTextColor(0, 0, 0)
+
+	for i, s := range doc.Sections {
+		// Le titre reste sur la même page que l'image ou le début du tableau
+		keep := float64(3 * pdfLineSize)
+		name := "chart" + strconv.Itoa(i)
+		var imageHeight float64
+		if len(s.Image) > 0 {
+			info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(s.Image))
+			if pdf.Err() {
+				break
+			}
+			imageHeight = width * info.Height() / info.Width()
+			keep = 2*pdfLineSize + imageHeight
+		}
+		pdf.Ln(pdfLineSize)
+		ensure(keep)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(0, 8, tr(s.Title), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, text := range s.Text {
+			pdf.MultiCell(0, 5, tr(text), "", "L", false)
+		}
+		if s.Table != nil {
+			pdf.Ln(2)
+			pdfTable(pdf, tr, s.Table, width, ensure)
+		}
+		if imageHeight > 0 {
+			ensure(imageHeight)
+			pdf.ImageOptions(name, pdfMargin, pdf.GetY(), width, imageHeight, true, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		}
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return i18n.Errorf("erreur lors de l'écriture du rapport PDF : %w", err)
+	}
+	return nil
+}
+
+// pdfTable dessine t sur toute la largeur width, en répétant l'en-tête en
+// haut de chaque page
+func pdfTable(pdf *gofpdf.Fpdf, tr func(string) string, t *table, width float64, ensure func(float64) bool) {
+	pdf.SetFont("Helvetica", "B", 9)
+	widths := make([]float64, len(t.Header))
+	for i, h := range t.Header {
+		widths[i] = pdf.GetStringWidth(tr(h)) + 2*pdfCellInset
+	}
+	pdf.SetFont("Helvetica", "", 9)
+	for _, row := range append(append([][]string{}, t.Rows...), t.Footer...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], pdf.GetStringWidth(tr(cell))+2*pdfCellInset)
+		}
+	}
+	// Les colonnes se partagent toute la largeur, en proportion de leur contenu
+	var total float64
+	for _, w := range widths {
+		total += w
+	}
+	for i := range widths {
+		widths[i] *= width / total
+	}
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(235, 235, 235)
+		for i, h := range t.Header {
+			pdf.CellFormat(widths[i], pdfCellSize, tr(h), "1", 0, align(i), true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	row := func(cells []string, style string, fill bool) {
+		if ensure(pdfCellSize) {
+			header()
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		pdf.SetFillColor(248, 248, 248)
+		for i, cell := range cells {
+			pdf.CellFormat(widths[i], pdfCellSize, tr(cell), "1", 0, align(i), fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	ensure(3 * pdfCellSize)
+	header()
+	for _, cells := range t.Rows {
+		row(cells, "", false)
+	}
+	for _, cells := range t.Footer {
+		row(cells, "B", true)
+	}
+}
+
+// align aligne le libellé de la première colonne à gauche et les nombres à droite
+func align(column int) string {
+	if column == 0 {
+		return "L"
+	}
+	return "R"
+}
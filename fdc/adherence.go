@@ -0,0 +1,226 @@
+package fdc
+
+import (
+	"math"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// DefaultTolerance est la marge, en pourcentage de l'objectif, dans laquelle
+// un apport est considéré comme atteint
+const DefaultTolerance = 10
+
+// Targets renvoie les besoins journaliers enregistrés de user, ou à défaut
+// ceux calculés à partir de sa dernière mesure ; zéro sans mesure
+func Targets(user models.User) Nutrients {
+	if user.CalorieNeeds <= 0 {
+		user.UpdateNutritionGoals()
+	}
+	return Nutrients{user.CalorieNeeds, user.ProteinNeeds, user.CarohydratesNeeds, user.LipidNeeds}
+}
+
+// Adherence compare un apport à son objectif
+type Adherence struct {
+	Intake float64
+	Target float64
+}
+
+// Percent renvoie l'apport en pourcentage de l'objectif ; 0 sans objectif
+func (a Adherence) Percent() float64 {
+	if a.Target <= 0 {
+		return 0
+	}
+	return a.Intake / a.Target * 100
+}
+
+// Gap renvoie l'excédent (positif) ou le déficit (négatif) par rapport à l'objectif
+func (a Adherence) Gap() float64 { return a.Intake - a.Target }
+
+// Within indique si l'apport est à moins de tolerance % de l'objectif
+func (a Adherence) Within(tolerance float64) bool {
+	return a.Target > 0 && math.Abs(a.Percent()-100) <= tolerance
+}
+
+// AdherenceDay contient les apports d'un jour enregistré de la période
+type AdherenceDay struct {
+	MenuID uint
+	Date   time.Time
+	Intake Nutrients
+}
+
+// NutrientAdherence résume le suivi d'un apport sur la période
+type NutrientAdherence struct {
+	Nutrient Nutrient
+	// Period compare le total des jours enregistrés à l'objectif de ces jours
+	Period Adherence
+	// DaysWithin est le nombre de jours dans la marge de tolérance
+	DaysWithin int
+	// CurrentStreak est la série de jours consécutifs dans la marge qui se
+	// termine au dernier jour enregistré ; LongestStreak la plus longue
+	CurrentStreak int
+	LongestStreak int
+}
+
+// AdherenceReport compare les apports d'un utilisateur à ses besoins
+type AdherenceReport struct {
+	User models.User
+	From time.Time
+	// To est exclu de la période
+	To        time.Time
+	Tolerance float64
+	Target    Nutrients
+	// Days ne contient que les jours enregistrés, par date
+	Days      []AdherenceDay
+	Nutrients []NutrientAdherence
+}
+
+// Adherence renvoie la comparaison de l'apport nutrient du jour day à l'objectif
+func (r AdherenceReport) Adherence(day AdherenceDay, nutrient Nutrient) Adherence {
+	return Adherence{day.Intake.Get(nutrient), r.Target.Get(nutrient)}
+}
+
+// GetAdherence compare, jour par jour et sur la période de from à to (exclu),
+// les apports de l'utilisateur userID à ses besoins. Les jours sans menu ne
+// comptent ni dans le total de la période ni dans les jours atteints, et
+// interrompent les séries.
+func GetAdherence(userID uint, from, to time.Time, tolerance float64) (AdherenceReport, error) {
+	user, err := GetUser(userID)
+	if err != nil {
+		return AdherenceReport{}, err
+	}
+	report := AdherenceReport{User: user, From: from, To: to, Tolerance: tolerance, Target: Targets(user)}
+	if report.Target.Calories <= 0 {
+		return report, i18n.Errorf("aucun objectif nutritionnel pour %s %s : enregistrez une mesure (gofit addmeasurement)",
+			user.FirstName, user.LastName)
+	}
+
+	totals, err := GetNutritionalReport(ReportFilter{UserID: user.ID, From: from, To: to})
+	if err != nil {
+		return report, err
+	}
+	for _, day := range totals {
+		report.Days = append(report.Days, AdherenceDay{day.MenuID, models.CalendarDay(day.Date), day.Nutrients})
+	}
+
+	for _, nutrient := range TrackedNutrients {
+		summary := NutrientAdherence{Nutrient: nutrient}
+		streak := 0
+		for i, day := range report.Days {
+			a := report.Adherence(day, nutrient)
+			summary.Period.Intake += a.Intake
+			summary.Period.Target += a.Target
+			if i > 0 && !report.Days[i-1].Date.AddDate(0, 0, 1).Equal(day.Date) {
+				streak = 0
+			}
+			if a.Within(tolerance) {
+				summary.DaysWithin++
+				streak++
+			} else {
+				streak = 0
+			}
+			summary.LongestStreak = max(summary.LongestStreak, streak)
+		}
+		summary.CurrentStreak = streak
+		report.Nutrients = append(report.Nutrients, summary)
+	}
+	return report, nil
+}
+
+// PrintAdherence affiche le suivi des objectifs : un tableau des jours, puis
+// un résumé par apport. Avec color, les pourcentages sont en vert dans la
+// marge, en jaune jusqu'au double de la marge et en rouge au-delà.
+func PrintAdherence(report AdherenceReport, color bool) {
+	user := report.User
+	last := report.To.AddDate(0, 0, -1)
+	i18n.Printf("\n🎯 Suivi des objectifs de %s %s du %s au %s (marge ± %s %%) :\n",
+		user.FirstName, user.LastName, i18n.Date(report.From), i18n.Date(last), i18n.Float(report.Tolerance, 0))
+	if len(report.Days) == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
+		return
+	}
+
+	cell := func(a Adherence) string {
+		return i18n.Sprintf("%s (%s %%)", i18n.Float(a.Intake, 0), i18n.Float(a.Percent(), 0))
+	}
+	colors := func(as ...Adherence) []tablewriter.Colors {
+		cs := []tablewriter.Colors{{}}
+		for _, a := range as {
+			cs = append(cs, adherenceColor(a, report.Tolerance, color))
+		}
+		return append(cs, tablewriter.Colors{})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{i18n.T("Date")}
+	for _, nutrient := range TrackedNutrients {
+		header = append(header, i18n.Sprintf("%s (%s)", nutrient.Label(), nutrient.Unit()))
+	}
+	table.SetHeader(append(header, i18n.T("Écart (kcal)")))
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, day := range report.Days {
+		row := []string{i18n.Date(day.Date)}
+		var as []Adherence
+		for _, nutrient := range TrackedNutrients {
+			a := report.Adherence(day, nutrient)
+			row = append(row, cell(a))
+			as = append(as, a)
+		}
+		row = append(row, signedFloat(as[0].Gap(), 0))
+		table.Rich(row, colors(as...))
+	}
+	row := []string{i18n.T("Objectif / jour")}
+	for _, nutrient := range TrackedNutrients {
+		row = append(row, i18n.Float(report.Target.Get(nutrient), 0))
+	}
+	table.Append(append(row, ""))
+	table.Render()
+
+	i18n.Printf("\n📈 Bilan sur %d jour(s) enregistré(s) :\n", len(report.Days))
+	summary := tablewriter.NewWriter(os.Stdout)
+	summary.SetHeader([]string{i18n.T("Apport"), i18n.T("Total"), i18n.T("Objectif"), i18n.T("Atteint"),
+		i18n.T("Excédent / déficit"), i18n.T("Jours dans la marge"), i18n.T("Série en cours"), i18n.T("Meilleure série")})
+	summary.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, n := range report.Nutrients {
+		unit := n.Nutrient.Unit()
+		summary.Rich([]string{
+			n.Nutrient.Label(),
+			i18n.Sprintf("%s %s", i18n.Float(n.Period.Intake, 0), unit),
+			i18n.Sprintf("%s %s", i18n.Float(n.Period.Target, 0), unit),
+			i18n.Sprintf("%s %%", i18n.Float(n.Period.Percent(), 0)),
+			i18n.Sprintf("%s %s", signedFloat(n.Period.Gap(), 0), unit),
+			i18n.Sprintf("%d / %d", n.DaysWithin, len(report.Days)),
+			i18n.Sprintf("%d j", n.CurrentStreak),
+			i18n.Sprintf("%d j", n.LongestStreak),
+		}, []tablewriter.Colors{{}, {}, {}, adherenceColor(n.Period, report.Tolerance, color)})
+	}
+	summary.Render()
+}
+
+// adherenceColor renvoie la couleur d'un apport selon son écart à l'objectif
+func adherenceColor(a Adherence, tolerance float64, color bool) tablewriter.Colors {
+	switch {
+	case !color || a.Target <= 0:
+		return tablewriter.Colors{}
+	case a.Within(tolerance):
+		return tablewriter.Colors{tablewriter.FgGreenColor}
+	case math.Abs(a.Percent()-100) <= 2*tolerance:
+		return tablewriter.Colors{tablewriter.FgYellowColor}
+	}
+	return tablewriter.Colors{tablewriter.FgRedColor}
+}
+
+// signedFloat formate v avec son signe, pour un excédent ou un déficit
+func signedFloat(v float64, prec int) string {
+	s := i18n.Float(v, prec)
+	if math.Round(v*math.Pow10(prec)) > 0 {
+		return "+" + s
+	}
+	return s
+}
@@ -0,0 +1,209 @@
+package fdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+var (
+	// auditActor identifie la personne qui utilise la CLI : GOFIT_ACTOR, sinon
+	// le compte système
+	auditActor = defaultActor()
+
+	// auditCommand est la commande en cours, renseignée par la CLI avant chaque exécution
+	auditCommand string
+)
+
+func defaultActor() string {
+	if actor := os.Getenv("GOFIT_ACTOR"); actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "inconnu"
+}
+
+// SetAuditCommand mémorise la commande saisie pour l'associer aux modifications qu'elle provoque
+func SetAuditCommand(command string) {
+	auditCommand = command
+}
+
+// snapshot lit l'état complet d'un enregistrement, corbeille comprise.
+// Elle renvoie nil si l'enregistrement n'existe pas.
+func snapshot(tx *gorm.DB, entity Entity, id uint) (map[string]any, error) {
+	model, err := entityModel(entity)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	if err := tx.Unscoped().Model(model).Where("id = ?", id).Limit(1).Find(&rows).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture de %s %d : %w", entity, id, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// recordChange journalise une modification : before est l'état lu avant le
+// changement (nil pour une création), l'état après est relu dans tx
+func recordChange(tx *gorm.DB, action models.AuditAction, entity Entity, id uint, before map[string]any) error {
+	after, err := snapshot(tx, entity, id)
+	if err != nil {
+		return err
+	}
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	entry := models.AuditLog{
+		Entity:   string(entity),
+		EntityID: id,
+		Action:   action,
+		Before:   string(beforeJSON),
+		After:    string(afterJSON),
+		Actor:    auditActor,
+		Command:  auditCommand,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return i18n.Errorf("erreur lors de l'écriture du journal d'audit : %w", err)
+	}
+	return nil
+}
+
+// auditedUpdate applique change à un enregistrement existant et journalise son état avant et après
+func auditedUpdate(tx *gorm.DB, action models.AuditAction, entity Entity, id uint, change func() error) error {
+	before, err := snapshot(tx, entity, id)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return recordChange(tx, action, entity, id, before)
+}
+
+// auditedHardDelete supprime définitivement un enregistrement en journalisant son dernier état
+func auditedHardDelete(tx *gorm.DB, entity Entity, id uint) error {
+	model, err := entityModel(entity)
+	if err != nil {
+		return err
+	}
+	return auditedUpdate(tx, models.AuditDelete, entity, id, func() error {
+		return tx.Unscoped().Delete(model, id).Error
+	})
+}
+
+// HistoryFilter restreint l'historique à une entité, un enregistrement et/ou une période
+type HistoryFilter struct {
+	Entity   Entity
+	EntityID uint
+	From     time.Time
+	To       time.Time
+}
+
+// GetHistory renvoie les entrées du journal d'audit correspondant au filtre, des plus anciennes aux plus récentes
+func GetHistory(filter HistoryFilter) ([]models.AuditLog, error) {
+	query := db.DB.Order("created_at, id")
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var logs []models.AuditLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture du journal d'audit : %w", err)
+	}
+	return logs, nil
+}
+
+// ShowHistory affiche l'historique des modifications sous forme de tableau
+func ShowHistory(filter HistoryFilter) error {
+	logs, err := GetHistory(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(logs) == 0 {
+		i18n.Println("Aucune modification enregistrée.")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{i18n.T("Date"), i18n.T("Acteur"), i18n.T("Commande"), i18n.T("Action"), i18n.T("Entité"), i18n.T("Changements")})
+	table.SetAutoWrapText(false)
+	for _, l := range logs {
+		table.Append([]string{
+			i18n.DateTime(l.CreatedAt.Local()),
+			l.Actor,
+			l.Command,
+			string(l.Action),
+			i18n.Sprintf("%s %d", l.Entity, l.EntityID),
+			describeChanges(l),
+		})
+	}
+
+	i18n.Println("\n🕓 Historique des modifications :")
+	table.Render()
+	return nil
+}
+
+// describeChanges résume les colonnes modifiées entre les deux états d'une entrée
+func describeChanges(l models.AuditLog) string {
+	var before, after map[string]any
+	_ = json.Unmarshal([]byte(l.Before), &before)
+	_ = json.Unmarshal([]byte(l.After), &after)
+
+	switch {
+	case before == nil:
+		return i18n.T("créé")
+	case after == nil:
+		return i18n.T("supprimé définitivement")
+	}
+
+	var changes []string
+	for key, newValue := range after {
+		if key == "deleted_at" {
+			continue
+		}
+		if oldValue := before[key]; fmt.Sprint(oldValue) != fmt.Sprint(newValue) {
+			changes = append(changes, i18n.Sprintf("%s : %v → %v", key, oldValue, newValue))
+		}
+	}
+	sort.Strings(changes)
+	if len(changes) == 0 {
+		switch l.Action {
+		case models.AuditDelete:
+			return i18n.T("mis à la corbeille")
+		case models.AuditRestore:
+			return i18n.T("restauré")
+		}
+	}
+	return strings.Join(changes, "\n")
+}
@@ -0,0 +1,245 @@
+package fdc
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// mealTypeOrder est l'ordre d'affichage des types de repas
+var mealTypeOrder = []models.MealType{models.Breakfast, models.Lunch, models.Dinner, models.Snack}
+
+// DayBreakdown contient les repas d'un menu journalier, avec leurs aliments
+type DayBreakdown struct {
+	MenuID uint
+	Date   time.Time
+	Meals  []models.Meal
+	Nutrients
+}
+
+// MealTypeShare contient les apports cumulés des repas d'un même type
+type MealTypeShare struct {
+	Type  models.MealType
+	Meals int
+	Nutrients
+}
+
+// Breakdown détaille les apports d'une période par repas et par aliment
+type Breakdown struct {
+	Days      []DayBreakdown
+	Total     Nutrients
+	MealTypes []MealTypeShare
+}
+
+// FoodContribution est l'apport cumulé d'un aliment sur la période
+type FoodContribution struct {
+	FdcID int
+	Name  string
+	// Uses est le nombre de fois où l'aliment a été ajouté à un repas
+	Uses     int
+	Quantity float64
+	// Amount est la quantité du nutriment classé, dans son unité
+	Amount float64
+}
+
+// Share renvoie part en pourcentage de total ; 0 si total est nul
+func Share(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
+
+// GetBreakdown charge les menus journaliers correspondant au filtre avec
+// leurs repas et leurs aliments, et cumule leurs apports par type de repas
+func GetBreakdown(filter ReportFilter) (Breakdown, error) {
+	var b Breakdown
+	meals := func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Order("logged_at, id")
+		if filter.MealType != "" {
+			tx = tx.Where("type = ?", filter.MealType)
+		}
+		return tx
+	}
+	query := db.DB.Preload("Meals", meals).Preload("Meals.Items", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Order("date, user_id")
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date < ?", filter.To)
+	}
+	var menus []models.DailyMenu
+	if err := query.Find(&menus).Error; err != nil {
+		return b, i18n.Errorf("erreur lors de la récupération des menus : %w", err)
+	}
+
+	types := map[models.MealType]*MealTypeShare{}
+	for _, menu := range menus {
+		if len(menu.Meals) == 0 {
+			continue
+		}
+		day := DayBreakdown{MenuID: menu.ID, Date: menu.Date, Meals: menu.Meals}
+		for _, meal := range menu.Meals {
+			cal, prot, carb, lipid := meal.GetMacros()
+			n := Nutrients{cal, prot, carb, lipid}
+			day.add(n)
+			if types[meal.Type] == nil {
+				types[meal.Type] = &MealTypeShare{Type: meal.Type}
+			}
+			types[meal.Type].Meals++
+			types[meal.Type].add(n)
+		}
+		b.Total.add(day.Nutrients)
+		b.Days = append(b.Days, day)
+	}
+	for _, t := range mealTypeOrder {
+		if share, ok := types[t]; ok {
+			b.MealTypes = append(b.MealTypes, *share)
+			delete(types, t)
+		}
+	}
+	// Autres types éventuels, par ordre alphabétique
+	rest := len(b.MealTypes)
+	for _, share := range types {
+		b.MealTypes = append(b.MealTypes, *share)
+	}
+	sort.Slice(b.MealTypes[rest:], func(i, j int) bool { return b.MealTypes[rest+i].Type < b.MealTypes[rest+j].Type })
+	return b, nil
+}
+
+// TopContributors classe les aliments de la période selon leur apport en
+// nutrient et renvoie les limit premiers, avec le total de ce nutriment
+// sur tous les aliments. Hors calories et macronutriments, les valeurs
+// viennent des détails FDC des aliments ; missing compte ceux dont les
+// détails n'ont pas pu être obtenus.
+func TopContributors(b Breakdown, nutrient Nutrient, limit int) (top []FoodContribution, total float64, missing int, err error) {
+	var ids []int
+	index := map[int]int{}
+	var contributions []FoodContribution
+	for _, day := range b.Days {
+		for _, meal := range day.Meals {
+			for _, item := range meal.Items {
+				i, ok := index[item.FdcID]
+				if !ok {
+					i = len(contributions)
+					index[item.FdcID] = i
+					contributions = append(contributions, FoodContribution{FdcID: item.FdcID, Name: item.Name})
+					ids = append(ids, item.FdcID)
+				}
+				contributions[i].Uses++
+				contributions[i].Quantity += item.Quantity
+				if nutrient.Macro() {
+					contributions[i].Amount += Nutrients{item.Calories, item.Proteins, item.Carbohydrates, item.Lipids}.Get(nutrient)
+				}
+			}
+		}
+	}
+
+	if !nutrient.Macro() && len(ids) > 0 {
+		amounts, unknown, err := FoodNutrientAmounts(ids)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		missing = len(unknown)
+		for i := range contributions {
+			c := &contributions[i]
+			c.Amount = amounts[c.FdcID][nutrient.Number()] * c.Quantity / 100
+		}
+	}
+
+	for _, c := range contributions {
+		total += c.Amount
+	}
+	sort.SliceStable(contributions, func(i, j int) bool { return contributions[i].Amount > contributions[j].Amount })
+	if limit > 0 && len(contributions) > limit {
+		contributions = contributions[:limit]
+	}
+	return contributions, total, missing, nil
+}
+
+// PrintBreakdown affiche la part de chaque type de repas dans les apports de
+// la période ; avec detail, celle de chaque repas et de chacun de ses
+// aliments dans les apports du jour ; puis les limit aliments qui apportent
+// le plus de nutrient
+func PrintBreakdown(b Breakdown, detail bool, nutrient Nutrient, limit int) error {
+	if len(b.Days) == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
+		return nil
+	}
+
+	cell := func(v, total float64) string {
+		return i18n.Sprintf("%s (%s %%)", i18n.Float(v, 0), i18n.Float(Share(v, total), 0))
+	}
+	header := []string{i18n.T("Calories"), i18n.T("Protéines"), i18n.T("Glucides"), i18n.T("Lipides")}
+	right := []int{tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT}
+	shares := func(n, total Nutrients) []string {
+		return []string{cell(n.Calories, total.Calories), cell(n.Proteins, total.Proteins),
+			cell(n.Carbohydrates, total.Carbohydrates), cell(n.Lipids, total.Lipids)}
+	}
+
+	i18n.Printf("\n🍽️  Répartition par type de repas sur %d jour(s) :\n", len(b.Days))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(append([]string{i18n.T("Type"), i18n.T("Repas")}, header...))
+	table.SetColumnAlignment(append([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT}, right...))
+	for _, t := range b.MealTypes {
+		table.Append(append([]string{string(t.Type), strconv.Itoa(t.Meals)}, shares(t.Nutrients, b.Total)...))
+	}
+	table.Render()
+
+	if detail {
+		for _, day := range b.Days {
+			i18n.Printf("\n📅 %s : %s kcal\n", i18n.Date(day.Date), i18n.Float(day.Calories, 0))
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader(append([]string{i18n.T("Repas / aliment"), i18n.T("Quantité")}, header...))
+			table.SetColumnAlignment(append([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT}, right...))
+			table.SetAutoWrapText(false)
+			for _, meal := range day.Meals {
+				cal, prot, carb, lipid := meal.GetMacros()
+				table.Append(append([]string{i18n.Sprintf("%s : %s", meal.Type, meal.Description), ""},
+					shares(Nutrients{cal, prot, carb, lipid}, day.Nutrients)...))
+				for _, item := range meal.Items {
+					table.Append(append([]string{"  • " + item.Name, i18n.Sprintf("%s g", i18n.Float(item.Quantity, 0))},
+						shares(Nutrients{item.Calories, item.Proteins, item.Carbohydrates, item.Lipids}, day.Nutrients)...))
+				}
+			}
+			table.Render()
+		}
+	}
+
+	top, total, missing, err := TopContributors(b, nutrient, limit)
+	if err != nil {
+		return err
+	}
+	if len(top) == 0 {
+		return nil
+	}
+	i18n.Printf("\n🏆 Principaux apports en %s (%s %s au total) :\n",
+		nutrient.Label(), i18n.Float(total, 0), nutrient.Unit())
+	table = tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", i18n.T("Aliment"), i18n.T("Fois"), i18n.T("Quantité"),
+		i18n.Sprintf("%s (%s)", nutrient.Label(), nutrient.Unit()), i18n.T("Part")})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for i, c := range top {
+		table.Append([]string{strconv.Itoa(i + 1), i18n.Sprintf("%s (fdcId %d)", c.Name, c.FdcID), strconv.Itoa(c.Uses),
+			i18n.Sprintf("%s g", i18n.Float(c.Quantity, 0)), i18n.Float(c.Amount, 1),
+			i18n.Sprintf("%s %%", i18n.Float(Share(c.Amount, total), 0))})
+	}
+	table.Render()
+	if missing > 0 {
+		i18n.Printf("⚠️ %d aliment(s) sans détails nutritionnels (API FDC injoignable ?) ne sont pas comptés\n", missing)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package fdc
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// CopyMeal copie un repas, type ou rattaché à un menu, dans le menu de
+// l'utilisateur userID à chacun des jours calendaires days, créé au besoin ;
+// les portions sont multipliées par scale. Avec userID à 0, le repas est copié
+// chez l'utilisateur de son menu. Tous les jours sont copiés, ou aucun.
+func CopyMeal(mealID, userID uint, days []time.Time, scale float64) ([]models.Meal, error) {
+	var source models.Meal
+	if err := db.DB.Preload("Items").First(&source, mealID).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération du repas %d : %w", mealID, err)
+	}
+	if userID == 0 {
+		if source.DailyMenuID == nil {
+			return nil, i18n.Errorf("le repas %d est un repas type : précisez l'utilisateur", mealID)
+		}
+		var menu models.DailyMenu
+		if err := db.DB.First(&menu, *source.DailyMenuID).Error; err != nil {
+			return nil, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
+		}
+		userID = menu.UserID
+	}
+	return copyMeals(userID, []models.Meal{source}, days, scale,
+		i18n.Sprintf("copie du repas '%s'", source.Description))
+}
+
+// CopyDailyMenu copie les repas d'un menu journalier dans les menus de son
+// utilisateur aux jours days, comme CopyMeal
+func CopyDailyMenu(menuID uint, days []time.Time, scale float64) ([]models.Meal, error) {
+	var menu models.DailyMenu
+	if err := db.DB.
+		Preload("Meals", func(tx *gorm.DB) *gorm.DB { return tx.Order("logged_at, id") }).
+		Preload("Meals.Items").
+		First(&menu, menuID).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
+	}
+	if len(menu.Meals) == 0 {
+		return nil, i18n.Errorf("le menu %d ne contient aucun repas", menuID)
+	}
+	return copyMeals(menu.UserID, menu.Meals, days, scale,
+		i18n.Sprintf("copie du menu du %s", i18n.Date(menu.Date)))
+}
+
+// copyMeals copie sources dans les menus de l'utilisateur aux jours days, en
+// une seule transaction ; description nomme l'opération pour undo
+func copyMeals(userID uint, sources []models.Meal, days []time.Time, scale float64, description string) ([]models.Meal, error) {
+	var meals []models.Meal
+	var createdMenus []uint
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, date := range days {
+			day := models.CalendarDay(date)
+			menu, created, err := getOrCreateDailyMenu(tx, userID, day)
+			if err != nil {
+				return err
+			}
+			if created {
+				createdMenus = append(createdMenus, menu.ID)
+			}
+			for _, source := range sources {
+				if source.DailyMenuID != nil && *source.DailyMenuID == menu.ID {
+					return i18n.Errorf("%s : un repas ne peut pas être copié dans son propre menu", i18n.Date(day))
+				}
+				meal, err := copyMeal(tx, source, menu.ID, scale)
+				if err != nil {
+					return i18n.Errorf("%s : %w", i18n.Date(day), err)
+				}
+				meals = append(meals, meal)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pushUndo(description, func(tx *gorm.DB) error {
+		for _, meal := range meals {
+			if err := deleteMealPermanently(tx, meal.ID); err != nil {
+				return err
+			}
+		}
+		for _, id := range createdMenus {
+			if err := auditedHardDelete(tx, EntityDailyMenu, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return meals, nil
+}
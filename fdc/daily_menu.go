@@ -1,92 +1,312 @@
 package fdc
 
 import (
-	"fmt"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
 	"github.com/lsoulet/gofit/models"
 )
 
-// GetUsers récupère la liste des utilisateurs
-func GetUsers() ([]models.User, error) {
-	var users []models.User
-	if err := db.DB.Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération des utilisateurs : %w", err)
-	}
-	return users, nil
-}
-
 // GetDailyMenus récupère la liste des menus journaliers
 func GetDailyMenus() ([]models.DailyMenu, error) {
 	var menus []models.DailyMenu
-	if err := db.DB.Preload("User").Find(&menus).Error; err != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération des menus : %w", err)
+	if err := db.DB.Preload("User").Preload("Meals").Order("date, user_id").Find(&menus).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des menus : %w", err)
 	}
 	return menus, nil
 }
 
-// CreateDailyMenu crée un nouveau menu journalier
+// ErrDailyMenuExists est renvoyée quand un menu existe déjà pour ce jour
+var ErrDailyMenuExists = i18n.New("un menu existe déjà pour cet utilisateur à cette date")
+
+// CreateDailyMenu crée un nouveau menu journalier, ou renvoie ErrDailyMenuExists
+// si l'utilisateur en possède déjà un pour ce jour
 func CreateDailyMenu(userID uint, date time.Time) error {
-	menu := models.DailyMenu{
-		UserID: userID,
-		Date:   date,
+	_, created, err := GetOrCreateDailyMenu(userID, date)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDailyMenuExists
+	}
+	return nil
+}
+
+// GetOrCreateDailyMenu renvoie le menu de l'utilisateur pour le jour que
+// représente date dans son fuseau horaire, en le créant s'il n'existe pas encore.
+// Le booléen indique si le menu vient d'être créé.
+func GetOrCreateDailyMenu(userID uint, date time.Time) (models.DailyMenu, bool, error) {
+	var user models.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		return models.DailyMenu{}, false, i18n.Errorf("erreur lors de la récupération de l'utilisateur : %w", err)
+	}
+	day := user.Day(date)
+
+	var menu models.DailyMenu
+	created := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		menu, created, err = getOrCreateDailyMenu(tx, userID, day)
+		return err
+	})
+	if err != nil {
+		return menu, false, err
+	}
+	if created {
+		pushUndo(i18n.Sprintf("création du menu du %s", i18n.Date(day)), func(tx *gorm.DB) error {
+			return auditedHardDelete(tx, EntityDailyMenu, menu.ID)
+		})
+	}
+	return menu, created, nil
+}
+
+// getOrCreateDailyMenu renvoie le menu de l'utilisateur pour le jour calendaire
+// day, en le créant dans tx s'il n'existe pas encore
+func getOrCreateDailyMenu(tx *gorm.DB, userID uint, day time.Time) (models.DailyMenu, bool, error) {
+	menu, err := findDailyMenu(tx, userID, day)
+	if err == nil {
+		return menu, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return menu, false, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
+	}
+
+	// Un autre processus peut créer le même menu entre-temps : l'index unique
+	// fait alors échouer silencieusement l'insertion et on relit le menu existant
+	menu = models.DailyMenu{UserID: userID, Date: day}
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&menu)
+	if res.Error != nil {
+		return menu, false, i18n.Errorf("erreur lors de la création du menu : %w", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		return menu, true, recordChange(tx, models.AuditCreate, EntityDailyMenu, menu.ID, nil)
+	}
+
+	menu, err = findDailyMenu(tx, userID, day)
+	if err != nil {
+		return menu, false, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
+	}
+	return menu, false, nil
+}
+
+// GetDailyMenu renvoie le menu de l'utilisateur userID pour le jour calendaire
+// day, avec ses repas et leurs aliments ; un menu vide s'il n'existe pas
+func GetDailyMenu(userID uint, day time.Time) (models.DailyMenu, error) {
+	var menu models.DailyMenu
+	if err := db.DB.Preload("Meals.Items").Where("user_id = ? AND date = ?", userID, day).Order("id").Limit(1).
+		Find(&menu).Error; err != nil {
+		return menu, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
+	}
+	return menu, nil
+}
+
+// findDailyMenu utilise Find plutôt que First pour ne pas journaliser
+// d'erreur quand le menu n'existe pas encore
+func findDailyMenu(tx *gorm.DB, userID uint, day time.Time) (models.DailyMenu, error) {
+	var menu models.DailyMenu
+	res := tx.Preload("Meals").Where("user_id = ? AND date = ?", userID, day).Order("id").Limit(1).Find(&menu)
+	if res.Error != nil {
+		return menu, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return menu, gorm.ErrRecordNotFound
+	}
+	return menu, nil
+}
+
+// MergeDuplicateDailyMenus fusionne les menus d'un même utilisateur pour un même
+// jour dans le plus ancien d'entre eux, puis crée l'index d'unicité.
+// Elle renvoie le nombre de menus supprimés. Si deux menus à fusionner ont
+// chacun un repas du même type, hors collations, rien n'est fusionné : ces
+// menus sont nommés dans l'erreur pour être corrigés d'abord.
+func MergeDuplicateDailyMenus() (int, error) {
+	merged := 0
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var menus []models.DailyMenu
+		if err := tx.Where("(user_id, date) IN (?)",
+			tx.Model(&models.DailyMenu{}).Select("user_id, date").Group("user_id, date").Having("COUNT(*) > 1"),
+		).Order("user_id, date, id").Find(&menus).Error; err != nil {
+			return i18n.Errorf("erreur lors de la recherche des menus en double : %w", err)
+		}
+		if err := checkMergeClashes(tx, menus); err != nil {
+			return err
+		}
+
+		var keep models.DailyMenu
+		for _, menu := range menus {
+			if menu.UserID != keep.UserID || !menu.Date.Equal(keep.Date) {
+				keep = menu
+				continue
+			}
+
+			var mealIDs []uint
+			if err := tx.Model(&models.Meal{}).Where("daily_menu_id = ?", menu.ID).Pluck("id", &mealIDs).Error; err != nil {
+				return i18n.Errorf("erreur lors de la récupération des repas du menu %d : %w", menu.ID, err)
+			}
+			for _, mealID := range mealIDs {
+				if err := auditedUpdate(tx, models.AuditUpdate, EntityMeal, mealID, func() error {
+					return tx.Model(&models.Meal{}).Where("id = ?", mealID).Update("daily_menu_id", keep.ID).Error
+				}); err != nil {
+					return i18n.Errorf("erreur lors du transfert des repas du menu %d : %w", menu.ID, err)
+				}
+			}
+			if err := auditedUpdate(tx, models.AuditDelete, EntityDailyMenu, menu.ID, func() error {
+				return tx.Delete(&models.DailyMenu{}, menu.ID).Error
+			}); err != nil {
+				return i18n.Errorf("erreur lors de la suppression du menu %d : %w", menu.ID, err)
+			}
+			merged++
+		}
+
+		return db.EnsureDailyMenuUniqueIndex(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if merged > 0 {
+		clearUndo()
+	}
+	return merged, nil
+}
+
+// checkMergeClashes renvoie une erreur si des menus du même utilisateur et du
+// même jour, triés comme dans MergeDuplicateDailyMenus, contiennent chacun un
+// repas du même type autre qu'une collation : leur fusion créerait un menu
+// que copyMeal refuse
+func checkMergeClashes(tx *gorm.DB, menus []models.DailyMenu) error {
+	if len(menus) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(menus))
+	for i, menu := range menus {
+		ids[i] = menu.ID
+	}
+	var meals []models.Meal
+	if err := tx.Where("daily_menu_id IN ? AND type <> ?", ids, models.Snack).Find(&meals).Error; err != nil {
+		return i18n.Errorf("erreur lors de la récupération des repas des menus en double : %w", err)
 	}
-	if err := db.DB.Create(&menu).Error; err != nil {
-		return fmt.Errorf("erreur lors de la création du menu : %w", err)
+	types := map[uint][]models.MealType{}
+	for _, meal := range meals {
+		types[*meal.DailyMenuID] = append(types[*meal.DailyMenuID], meal.Type)
+	}
+
+	var clashes []string
+	for i := 0; i < len(menus); {
+		// Les menus i à j (exclu) sont ceux d'un même utilisateur et d'un même jour
+		j := i + 1
+		for j < len(menus) && menus[j].UserID == menus[i].UserID && menus[j].Date.Equal(menus[i].Date) {
+			j++
+		}
+		byType := map[models.MealType][]string{}
+		var order []models.MealType
+		for _, menu := range menus[i:j] {
+			for _, t := range slices.Compact(slices.Sorted(slices.Values(types[menu.ID]))) {
+				if len(byType[t]) == 0 {
+					order = append(order, t)
+				}
+				byType[t] = append(byType[t], strconv.FormatUint(uint64(menu.ID), 10))
+			}
+		}
+		for _, t := range order {
+			if len(byType[t]) > 1 {
+				clashes = append(clashes, i18n.Sprintf("%s le %s (menus %s)", t, i18n.Date(menus[i].Date), strings.Join(byType[t], ", ")))
+			}
+		}
+		i = j
+	}
+	if len(clashes) > 0 {
+		return i18n.Errorf("repas du même type dans des menus à fusionner : %s ; supprimez l'un des repas (gofit delmeal) ou déplacez l'un des menus (gofit editmenu) avant de fusionner",
+			strings.Join(clashes, " ; "))
 	}
 	return nil
 }
 
-// AddMealToDailyMenu ajoute un repas à un menu journalier
-func AddMealToDailyMenu(menuID uint, mealType models.MealType, description string) error {
+// AddMealToDailyMenu ajoute à un menu journalier une copie du repas type sourceID
+func AddMealToDailyMenu(menuID, sourceID uint) (models.Meal, error) {
 	// Récupérer le menu
 	var menu models.DailyMenu
 	if err := db.DB.First(&menu, menuID).Error; err != nil {
-		return fmt.Errorf("erreur lors de la récupération du menu : %w", err)
+		return models.Meal{}, i18n.Errorf("erreur lors de la récupération du menu : %w", err)
 	}
 
 	// Récupérer le repas source
 	var sourceMeal models.Meal
-	if err := db.DB.Where("description = ? AND type = ?", description, mealType).First(&sourceMeal).Error; err != nil {
-		return fmt.Errorf("erreur lors de la récupération du repas source : %w", err)
+	if err := db.DB.Preload("Items").Where("daily_menu_id IS NULL").First(&sourceMeal, sourceID).Error; err != nil {
+		return models.Meal{}, i18n.Errorf("erreur lors de la récupération du repas source : %w", err)
 	}
 
-	// Vérifier si un repas de ce type existe déjà (sauf pour les collations)
-	if mealType != models.Snack {
-		var existingMenu models.DailyMenu
-		if err := db.DB.Preload("Meals").First(&existingMenu, menuID).Error; err != nil {
-			return fmt.Errorf("erreur lors de la vérification des repas existants : %w", err)
-		}
+	// Créer le nouveau repas rattaché au menu avec les valeurs nutritionnelles
+	// et les aliments du repas source
+	var meal models.Meal
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		meal, err = copyMeal(tx, sourceMeal, menu.ID, 1)
+		return err
+	}); err != nil {
+		return models.Meal{}, err
+	}
 
-		for _, meal := range existingMenu.Meals {
-			if meal.Type == mealType {
-				return fmt.Errorf("ce menu contient déjà un repas de type %s", mealType)
-			}
+	pushUndo(i18n.Sprintf("ajout du repas '%s' au menu %d", sourceMeal.Description, menuID), func(tx *gorm.DB) error {
+		return deleteMealPermanently(tx, meal.ID)
+	})
+	return meal, nil
+}
+
+// copyMeal crée dans le menu menuID une copie du repas source et de ses
+// aliments, portions multipliées par scale. Hors collations, le menu ne doit
+// pas déjà contenir un repas du même type.
+func copyMeal(tx *gorm.DB, source models.Meal, menuID uint, scale float64) (models.Meal, error) {
+	if source.Type != models.Snack {
+		var count int64
+		if err := tx.Model(&models.Meal{}).Where("daily_menu_id = ? AND type = ?", menuID, source.Type).
+			Count(&count).Error; err != nil {
+			return models.Meal{}, i18n.Errorf("erreur lors de la vérification des repas existants : %w", err)
+		}
+		if count > 0 {
+			return models.Meal{}, i18n.Errorf("ce menu contient déjà un repas de type %s", source.Type)
 		}
 	}
 
-	// Créer le nouveau repas avec les valeurs nutritionnelles du repas source
 	meal := models.Meal{
-		Type:          mealType,
-		Description:   description,
-		Calories:      sourceMeal.Calories,
-		Proteins:      sourceMeal.Proteins,
-		Carbohydrates: sourceMeal.Carbohydrates,
-		Lipids:        sourceMeal.Lipids,
+		DailyMenuID:   &menuID,
+		Type:          source.Type,
+		Description:   source.Description,
+		Calories:      source.Calories * scale,
+		Proteins:      source.Proteins * scale,
+		Carbohydrates: source.Carbohydrates * scale,
+		Lipids:        source.Lipids * scale,
+		LoggedAt:      time.Now(),
 	}
-
-	// Sauvegarder le repas
-	if err := db.DB.Create(&meal).Error; err != nil {
-		return fmt.Errorf("erreur lors de la création du repas : %w", err)
+	for _, item := range source.Items {
+		item.ID = 0
+		item.MealID = 0
+		item.Quantity *= scale
+		item.Calories *= scale
+		item.Proteins *= scale
+		item.Carbohydrates *= scale
+		item.Lipids *= scale
+		item.LoggedAt = meal.LoggedAt
+		meal.Items = append(meal.Items, item)
 	}
 
-	// Associer le repas au menu
-	if err := db.DB.Model(&menu).Association("Meals").Append(&meal); err != nil {
-		return fmt.Errorf("erreur lors de l'association du repas au menu : %w", err)
+	if err := tx.Create(&meal).Error; err != nil {
+		return models.Meal{}, i18n.Errorf("erreur lors de la création du repas : %w", err)
 	}
+	return meal, recordMealCreation(tx, meal)
+}
 
-	return nil
+// NewMenuMeal renvoie, sans l'enregistrer, un repas sans aliment du menu
+// journalier menuID, décrit par son type
+func NewMenuMeal(menuID uint, mealType models.MealType) models.Meal {
+	return models.Meal{DailyMenuID: &menuID, Type: mealType, Description: string(mealType), LoggedAt: time.Now()}
 }
 
 // ListDailyMenus affiche la liste des menus journaliers
@@ -97,13 +317,16 @@ func ListDailyMenus() error {
 	}
 
 	if len(menus) == 0 {
-		fmt.Println("Aucun menu journalier enregistré.")
+		i18n.Println("Aucun menu journalier enregistré.")
 		return nil
 	}
 
-	fmt.Println("📅 Menus journaliers enregistrés :")
-	for i, menu := range menus {
-		fmt.Printf("%d. %s %s - %s\n", i+1, menu.User.FirstName, menu.User.LastName, menu.Date.Format("02/01/2006"))
+	i18n.Println("📅 Menus journaliers enregistrés :")
+	for _, menu := range menus {
+		i18n.Printf("%d. %s %s - %s\n", menu.ID, menu.User.FirstName, menu.User.LastName, i18n.Date(menu.Date))
+		for _, meal := range menu.Meals {
+			i18n.Printf("   %d. %s (%s) | %.1f kcal\n", meal.ID, meal.Description, meal.Type, meal.Calories)
+		}
 	}
 	return nil
 }
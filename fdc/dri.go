@@ -0,0 +1,166 @@
+package fdc
+
+import "github.com/lsoulet/gofit/models"
+
+// Apports nutritionnels de référence (Dietary Reference Intakes) des
+// National Academies, par tranche d'âge et par sexe, hors grossesse et
+// allaitement. Les nourrissons de moins d'un an reçoivent les valeurs de
+// 1 à 3 ans.
+
+// driAgeGroups sont les âges maximaux (inclus) des tranches d'âge des
+// tables ; au-delà du dernier, la tranche des plus de 70 ans s'applique
+var driAgeGroups = []int{3, 8, 13, 18, 30, 50, 70}
+
+// driValues donne une valeur par tranche d'âge (1-3, 4-8, 9-13, 14-18,
+// 19-30, 31-50, 51-70, 71+), pour les hommes puis pour les femmes
+type driValues [8][2]float64
+
+// dri est la référence d'un nutriment
+type dri struct {
+	// intake est l'apport recommandé (RDA), ou l'apport adéquat (AI) quand
+	// adequate est vrai
+	intake   driValues
+	adequate bool
+	// upper est l'apport maximal tolérable (UL) ; 0 pour un nutriment sans
+	// limite applicable aux aliments. Pour le sodium, c'est la limite de
+	// réduction du risque chronique (CDRR).
+	upper driValues
+	// upperOf est le nutriment auquel s'applique upper, s'il n'est pas le
+	// nutriment lui-même : l'UL de la vitamine A ne vise que le rétinol, pas
+	// les caroténoïdes comptés dans son apport en équivalents rétinol (RAE)
+	upperOf Nutrient
+}
+
+// same renvoie des valeurs identiques pour les hommes et les femmes
+func same(v ...float64) driValues {
+	var d driValues
+	for i := range d {
+		d[i] = [2]float64{v[i], v[i]}
+	}
+	return d
+}
+
+// bySex renvoie des valeurs distinctes : les hommes puis les femmes, par tranche
+func bySex(male, female []float64) driValues {
+	var d driValues
+	for i := range d {
+		d[i] = [2]float64{male[i], female[i]}
+	}
+	return d
+}
+
+var driTable = map[Nutrient]dri{
+	NutrientVitaminA: {
+		intake:  bySex([]float64{300, 400, 600, 900, 900, 900, 900, 900}, []float64{300, 400, 600, 700, 700, 700, 700, 700}),
+		upper:   same(600, 900, 1700, 2800, 3000, 3000, 3000, 3000),
+		upperOf: NutrientRetinol,
+	},
+	NutrientVitaminC: {
+		intake: bySex([]float64{15, 25, 45, 75, 90, 90, 90, 90}, []float64{15, 25, 45, 65, 75, 75, 75, 75}),
+		upper:  same(400, 650, 1200, 1800, 2000, 2000, 2000, 2000),
+	},
+	NutrientVitaminD: {
+		intake: same(15, 15, 15, 15, 15, 15, 15, 20),
+		upper:  same(63, 75, 100, 100, 100, 100, 100, 100),
+	},
+	NutrientVitaminE: {
+		intake: same(6, 7, 11, 15, 15, 15, 15, 15),
+	},
+	NutrientVitaminK: {
+		intake:   bySex([]float64{30, 55, 60, 75, 120, 120, 120, 120}, []float64{30, 55, 60, 75, 90, 90, 90, 90}),
+		adequate: true,
+	},
+	NutrientThiamin: {
+		intake: bySex([]float64{0.5, 0.6, 0.9, 1.2, 1.2, 1.2, 1.2, 1.2}, []float64{0.5, 0.6, 0.9, 1.0, 1.1, 1.1, 1.1, 1.1}),
+	},
+	NutrientRiboflavin: {
+		intake: bySex([]float64{0.5, 0.6, 0.9, 1.3, 1.3, 1.3, 1.3, 1.3}, []float64{0.5, 0.6, 0.9, 1.0, 1.1, 1.1, 1.1, 1.1}),
+	},
+	NutrientNiacin: {
+		intake: bySex([]float64{6, 8, 12, 16, 16, 16, 16, 16}, []float64{6, 8, 12, 14, 14, 14, 14, 14}),
+	},
+	NutrientVitaminB6: {
+		intake: bySex([]float64{0.5, 0.6, 1.0, 1.3, 1.3, 1.3, 1.7, 1.7}, []float64{0.5, 0.6, 1.0, 1.2, 1.3, 1.3, 1.5, 1.5}),
+		upper:  same(30, 40, 60, 80, 100, 100, 100, 100),
+	},
+	NutrientFolate: {
+		intake: same(150, 200, 300, 400, 400, 400, 400, 400),
+	},
+	NutrientVitaminB12: {
+		intake: same(0.9, 1.2, 1.8, 2.4, 2.4, 2.4, 2.4, 2.4),
+	},
+	NutrientCalcium: {
+		intake: bySex([]float64{700, 1000, 1300, 1300, 1000, 1000, 1000, 1200}, []float64{700, 1000, 1300, 1300, 1000, 1000, 1200, 1200}),
+		upper:  same(2500, 2500, 3000, 3000, 2500, 2500, 2000, 2000),
+	},
+	NutrientIron: {
+		intake: bySex([]float64{7, 10, 8, 11, 8, 8, 8, 8}, []float64{7, 10, 8, 15, 18, 18, 8, 8}),
+		upper:  same(40, 40, 40, 45, 45, 45, 45, 45),
+	},
+	NutrientMagnesium: {
+		intake: bySex([]float64{80, 130, 240, 410, 400, 420, 420, 420}, []float64{80, 130, 240, 360, 310, 320, 320, 320}),
+	},
+	NutrientPhosphorus: {
+		intake: same(460, 500, 1250, 1250, 700, 700, 700, 700),
+		upper:  same(3000, 3000, 4000, 4000, 4000, 4000, 4000, 3000),
+	},
+	NutrientPotassium: {
+		intake:   bySex([]float64{2000, 2300, 2500, 3000, 3400, 3400, 3400, 3400}, []float64{2000, 2300, 2300, 2300, 2600, 2600, 2600, 2600}),
+		adequate: true,
+	},
+	NutrientSodium: {
+		intake:   same(800, 1000, 1200, 1500, 1500, 1500, 1500, 1500),
+		adequate: true,
+		upper:    same(1200, 1500, 1800, 2300, 2300, 2300, 2300, 2300),
+	},
+	NutrientZinc: {
+		intake: bySex([]float64{3, 5, 8, 11, 11, 11, 11, 11}, []float64{3, 5, 8, 9, 8, 8, 8, 8}),
+		upper:  same(7, 12, 23, 34, 40, 40, 40, 40),
+	},
+	NutrientSelenium: {
+		intake: same(20, 30, 40, 55, 55, 55, 55, 55),
+		upper:  same(90, 150, 280, 400, 400, 400, 400, 400),
+	},
+	NutrientFiber: {
+		intake:   bySex([]float64{19, 25, 31, 38, 38, 38, 30, 30}, []float64{19, 25, 26, 26, 25, 25, 21, 21}),
+		adequate: true,
+	},
+}
+
+// Reference est l'apport de référence d'un nutriment pour un utilisateur
+type Reference struct {
+	Nutrient Nutrient
+	// Intake est l'apport recommandé (RDA), ou adéquat (AI) si Adequate
+	Intake   float64
+	Adequate bool
+	// Upper est l'apport maximal tolérable (UL) ; 0 sans limite
+	Upper float64
+	// UpperOf est le nutriment comparé à Upper : Nutrient, sauf pour la
+	// vitamine A dont seul le rétinol est limité
+	UpperOf Nutrient
+}
+
+// ReferenceFor renvoie l'apport de référence de nutrient selon l'âge et le
+// sexe de user ; faux pour un nutriment hors des tables
+func ReferenceFor(user models.User, nutrient Nutrient) (Reference, bool) {
+	d, ok := driTable[nutrient]
+	if !ok {
+		return Reference{}, false
+	}
+	group := len(driAgeGroups)
+	for i, max := range driAgeGroups {
+		if user.Age <= max {
+			group = i
+			break
+		}
+	}
+	sex := 0
+	if user.Gender == models.Female {
+		sex = 1
+	}
+	upperOf := nutrient
+	if d.upperOf != "" {
+		upperOf = d.upperOf
+	}
+	return Reference{nutrient, d.intake[group][sex], d.adequate, d.upper[group][sex], upperOf}, true
+}
@@ -0,0 +1,126 @@
+package fdc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// UpdateEntity modifie les colonnes d'un enregistrement (nom de colonne → valeur)
+// et mémorise les anciennes valeurs pour pouvoir annuler la modification
+func UpdateEntity(entity Entity, id uint, updates map[string]any) error {
+	model, err := entityModel(entity)
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(updates))
+	for column := range updates {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	before := map[string]any{}
+	if err := db.DB.Model(model).Select(columns).Where("id = ?", id).Take(&before).Error; err != nil {
+		return i18n.Errorf("%s %d introuvable : %w", entity, id, err)
+	}
+
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return auditedUpdate(tx, models.AuditUpdate, entity, id, func() error {
+			return tx.Model(model).Where("id = ?", id).Updates(updates).Error
+		})
+	}); err != nil {
+		return i18n.Errorf("erreur lors de la modification de %s %d : %w", entity, id, err)
+	}
+
+	pushUndo(i18n.Sprintf("modification de %s %d (%s)", entity, id, strings.Join(columns, ", ")), func(tx *gorm.DB) error {
+		return auditedUpdate(tx, models.AuditUpdate, entity, id, func() error {
+			return tx.Model(model).Where("id = ?", id).Updates(before).Error
+		})
+	})
+	return nil
+}
+
+// UpdateDailyMenuDate déplace un menu au jour calendaire de date, pris tel
+// quel (une date saisie JJ/MM/AAAA désigne déjà le jour local de l'utilisateur)
+func UpdateDailyMenuDate(menuID uint, date time.Time) error {
+	var menu models.DailyMenu
+	if err := db.DB.First(&menu, menuID).Error; err != nil {
+		return i18n.Errorf("menu %d introuvable : %w", menuID, err)
+	}
+
+	day := models.CalendarDay(date)
+	if existing, err := findDailyMenu(db.DB, menu.UserID, day); err == nil && existing.ID != menuID {
+		return ErrDailyMenuExists
+	}
+	return UpdateEntity(EntityDailyMenu, menuID, map[string]any{"date": day})
+}
+
+// mealMacroColumns sont les colonnes d'un repas tenues à jour par
+// AddFoodToMeal comme la somme des valeurs de ses aliments
+var mealMacroColumns = []string{"calories", "proteins", "carbohydrates", "lipids"}
+
+// UpdateMeal modifie un repas. Hors collations, son nouveau type ne doit pas
+// être celui d'un autre repas de son menu ; les calories et macronutriments
+// d'un repas qui a des aliments ne peuvent pas être modifiés, car ils en
+// sont la somme.
+func UpdateMeal(mealID uint, updates map[string]any) error {
+	var meal models.Meal
+	if err := db.DB.Preload("Items").First(&meal, mealID).Error; err != nil {
+		return i18n.Errorf("repas %d introuvable : %w", mealID, err)
+	}
+
+	if t, ok := updates["type"].(models.MealType); ok && t != meal.Type && t != models.Snack && meal.DailyMenuID != nil {
+		var count int64
+		if err := db.DB.Model(&models.Meal{}).Where("daily_menu_id = ? AND type = ? AND id <> ?", *meal.DailyMenuID, t, mealID).
+			Count(&count).Error; err != nil {
+			return i18n.Errorf("erreur lors de la vérification des repas existants : %w", err)
+		}
+		if count > 0 {
+			return i18n.Errorf("ce menu contient déjà un repas de type %s", t)
+		}
+	}
+
+	if len(meal.Items) > 0 {
+		for _, column := range mealMacroColumns {
+			if _, ok := updates[column]; ok {
+				return i18n.Errorf("le repas %d contient %d aliment(s) : ses calories et macronutriments sont calculés à partir d'eux et ne peuvent pas être modifiés",
+					mealID, len(meal.Items))
+			}
+		}
+	}
+
+	return UpdateEntity(EntityMeal, mealID, updates)
+}
+
+// UpdateMeasurement modifie une mesure et recalcule l'IMC si le poids ou la
+// taille change
+func UpdateMeasurement(measurementID uint, updates map[string]any) error {
+	var measurement models.Measurement
+	if err := db.DB.First(&measurement, measurementID).Error; err != nil {
+		return i18n.Errorf("mesure %d introuvable : %w", measurementID, err)
+	}
+
+	weight, height := measurement.Weight, measurement.Height
+	if v, ok := updates["weight"].(float64); ok {
+		weight = v
+	}
+	if v, ok := updates["height"].(float64); ok {
+		height = v
+	}
+	if weight != measurement.Weight || height != measurement.Height {
+		bmi, err := models.CalculateBMI(weight, height)
+		if err != nil {
+			return err
+		}
+		updates["bmi"] = bmi
+	}
+
+	return UpdateEntity(EntityMeasurement, measurementID, updates)
+}
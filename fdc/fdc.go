@@ -6,8 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 
 	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
 	"github.com/lsoulet/gofit/models"
 )
 
@@ -32,15 +38,44 @@ type FoodDetail struct {
 	Description   string `json:"description"`
 	FoodNutrients []struct {
 		Nutrient struct {
-			Number string `json:"number"`
-			Name   string `json:"name"`
+			Number   string `json:"number"`
+			Name     string `json:"name"`
+			UnitName string `json:"unitName"`
 		} `json:"nutrient"`
 		Amount float64 `json:"amount"`
 	} `json:"foodNutrients"`
+	FoodPortions []struct {
+		Amount             float64 `json:"amount"`
+		GramWeight         float64 `json:"gramWeight"`
+		Modifier           string  `json:"modifier"`
+		PortionDescription string  `json:"portionDescription"`
+		MeasureUnit        struct {
+			Name string `json:"name"`
+		} `json:"measureUnit"`
+	} `json:"foodPortions"`
+	// Portion déclarée par les aliments de marque
+	ServingSize              float64 `json:"servingSize"`
+	ServingSizeUnit          string  `json:"servingSizeUnit"`
+	HouseholdServingFullText string  `json:"householdServingFullText"`
+}
+
+// Portion est une mesure ménagère d'un aliment (tasse, tranche, portion...)
+// et son poids en grammes
+type Portion struct {
+	// Description nomme la mesure, ex. « 1 cup, chopped » ou « slice »
+	Description string
+	Amount      float64
+	GramWeight  float64
+}
+
+// SearchResult est un aliment trouvé par SearchFood
+type SearchResult struct {
+	FdcID       int
+	Description string
 }
 
 // 🔍 Rechercher un aliment
-func SearchFood(query string) ([]string, error) {
+func SearchFood(query string) ([]SearchResult, error) {
 	reqBody, _ := json.Marshal(SearchRequest{Query: query})
 
 	req, err := http.NewRequest("POST", fmt.Sprintf("%s?api_key=%s", searchURL, apiKey), bytes.NewBuffer(reqBody))
@@ -54,37 +89,95 @@ func SearchFood(query string) ([]string, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
 
 	var result SearchResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
 
-	var results []string
+	var results []SearchResult
 	for _, food := range result.Foods {
-		results = append(results, fmt.Sprintf("%s (fdcId: %d)", food.Description, food.FdcID))
+		results = append(results, SearchResult{FdcID: food.FdcID, Description: food.Description})
 	}
+	rememberResults(results)
 	return results, nil
 }
 
-// 🔎 Récupérer les détails nutritionnels d'un aliment à partir de son fdcId
-func GetFoodDetails(fdcID int) (string, float64, float64, float64, float64, error) {
+// maxRecentResults borne le nombre d'aliments retenus par rememberResults
+const maxRecentResults = 50
+
+// recentResults contient les aliments trouvés pendant la session, le plus récent en premier
+var recentResults []SearchResult
+
+func rememberResults(results []SearchResult) {
+	recent := append([]SearchResult(nil), results...)
+	for _, r := range recentResults {
+		if !slices.ContainsFunc(results, func(n SearchResult) bool { return n.FdcID == r.FdcID }) {
+			recent = append(recent, r)
+		}
+	}
+	if len(recent) > maxRecentResults {
+		recent = recent[:maxRecentResults]
+	}
+	recentResults = recent
+}
+
+// RecentResults renvoie les aliments trouvés par les recherches de la session,
+// le plus récent en premier
+func RecentResults() []SearchResult {
+	return recentResults
+}
+
+// checkStatus renvoie une erreur si l'API FDC n'a pas répondu par un succès,
+// par exemple pour une clé invalide ou un quota dépassé : le corps de la
+// réponse n'est alors pas un aliment
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return i18n.Errorf("l'API FDC a répondu %s", resp.Status)
+	}
+	return nil
+}
+
+// getFood interroge l'API FDC sur un aliment
+func getFood(fdcID int) (FoodDetail, error) {
 	url := fmt.Sprintf("%s%d?api_key=%s", detailURL, fdcID, apiKey)
 
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", 0, 0, 0, 0, err
+		return FoodDetail{}, err
 	}
 	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return FoodDetail{}, err
+	}
 
 	var result FoodDetail
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return FoodDetail{}, err
+	}
+	return result, nil
+}
+
+// 🔎 Récupérer les détails nutritionnels d'un aliment à partir de son fdcId
+func GetFoodDetails(fdcID int) (string, float64, float64, float64, float64, error) {
+	result, err := getFood(fdcID)
+	if err != nil {
 		return "", 0, 0, 0, 0, err
 	}
+	if result.Description == "" {
+		return "", 0, 0, 0, 0, i18n.Errorf("aliment %d introuvable dans FDC", fdcID)
+	}
 
-	var calories, proteins, carbohydrates, lipids float64
+	calories, proteins, carbohydrates, lipids := result.macros()
+	return result.Description, calories, proteins, carbohydrates, lipids, nil
+}
 
-	for _, nutrient := range result.FoodNutrients {
+// macros renvoie les calories et macronutriments pour 100 g de l'aliment
+func (d FoodDetail) macros() (calories, proteins, carbohydrates, lipids float64) {
+	for _, nutrient := range d.FoodNutrients {
 		switch nutrient.Nutrient.Number {
 		case "203": // Protéines
 			proteins = nutrient.Amount
@@ -96,41 +189,217 @@ func GetFoodDetails(fdcID int) (string, float64, float64, float64, float64, erro
 			calories = nutrient.Amount
 		}
 	}
+	return calories, proteins, carbohydrates, lipids
+}
 
-	return result.Description, calories, proteins, carbohydrates, lipids, nil
+// GetFoodPortions renvoie les mesures ménagères connues d'un aliment ; la
+// portion déclarée d'un aliment de marque est nommée « serving »
+func GetFoodPortions(fdcID int) ([]Portion, error) {
+	result, err := getFood(fdcID)
+	if err != nil {
+		return nil, err
+	}
+	var portions []Portion
+	for _, p := range result.FoodPortions {
+		if p.GramWeight <= 0 {
+			continue
+		}
+		amount := p.Amount
+		if amount <= 0 {
+			amount = 1
+		}
+		description := strings.TrimSpace(strings.Join([]string{p.PortionDescription, p.MeasureUnit.Name, p.Modifier}, " "))
+		portions = append(portions, Portion{Description: description, Amount: amount, GramWeight: p.GramWeight})
+	}
+	if result.ServingSize > 0 && strings.EqualFold(result.ServingSizeUnit, "g") {
+		description := strings.TrimSpace("serving " + result.HouseholdServingFullText)
+		portions = append(portions, Portion{Description: description, Amount: 1, GramWeight: result.ServingSize})
+	}
+	return portions, nil
 }
 
-func AddFoodToMeal(mealID uint, fdcID int, quantity float64) error {
+func AddFoodToMeal(mealID uint, fdcID int, quantity float64) (models.MealItem, error) {
 	// Récupérer le repas
 	var meal models.Meal
 	if err := db.DB.First(&meal, mealID).Error; err != nil {
-		return fmt.Errorf("erreur lors de la récupération du repas : %w", err)
+		return models.MealItem{}, i18n.Errorf("erreur lors de la récupération du repas : %w", err)
 	}
 
-	// Récupérer les détails de l'aliment
-	name, calories, proteins, carbs, lipids, err := GetFoodDetails(fdcID)
+	item, err := newMealItem(fdcID, quantity)
 	if err != nil {
-		return fmt.Errorf("erreur lors de la récupération des détails de l'aliment : %w", err)
+		return models.MealItem{}, err
+	}
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return addMealItem(tx, &meal, &item)
+	}); err != nil {
+		return models.MealItem{}, err
 	}
 
-	// Calculer les valeurs nutritionnelles en fonction de la quantité
+	pushUndo(i18n.Sprintf("ajout de %.0f g de %s au repas %d", quantity, item.Name, mealID), func(tx *gorm.DB) error {
+		return removeMealItem(tx, item)
+	})
+	return item, nil
+}
+
+// FoodQuantity est un aliment FDC et son poids en grammes
+type FoodQuantity struct {
+	FdcID    int
+	Quantity float64
+}
+
+// AddFoodsToMeal ajoute les aliments foods au repas meal en une seule
+// transaction : aucun n'est ajouté si l'un d'eux échoue. Un repas sans
+// identifiant est créé dans la même transaction. L'ajout s'annule d'un bloc.
+func AddFoodsToMeal(meal models.Meal, foods []FoodQuantity) (models.Meal, []models.MealItem, error) {
+	// Récupérer les détails de tous les aliments avant d'écrire quoi que ce soit
+	items := make([]models.MealItem, len(foods))
+	for i, f := range foods {
+		item, err := newMealItem(f.FdcID, f.Quantity)
+		if err != nil {
+			return models.Meal{}, nil, err
+		}
+		items[i] = item
+	}
+
+	created := meal.ID == 0
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if created {
+			if err := tx.Create(&meal).Error; err != nil {
+				return i18n.Errorf("erreur lors de la création du repas : %w", err)
+			}
+			if err := recordMealCreation(tx, meal); err != nil {
+				return err
+			}
+		} else {
+			// Relire le repas, sans ses aliments, pour partir de ses totaux enregistrés
+			var stored models.Meal
+			if err := tx.First(&stored, meal.ID).Error; err != nil {
+				return i18n.Errorf("erreur lors de la récupération du repas : %w", err)
+			}
+			meal = stored
+		}
+		for i := range items {
+			if err := addMealItem(tx, &meal, &items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return models.Meal{}, nil, err
+	}
+
+	pushUndo(i18n.Sprintf("ajout de %d aliment(s) au repas %d", len(items), meal.ID), func(tx *gorm.DB) error {
+		if created {
+			return deleteMealPermanently(tx, meal.ID)
+		}
+		for _, item := range slices.Backward(items) {
+			if err := removeMealItem(tx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return meal, items, nil
+}
+
+// newMealItem prépare l'aliment fdcID pour quantity grammes, ses valeurs
+// nutritionnelles calculées depuis ses détails FDC, en cache s'il y est
+func newMealItem(fdcID int, quantity float64) (models.MealItem, error) {
+	food, err := GetFood(fdcID)
+	if err != nil {
+		return models.MealItem{}, i18n.Errorf("erreur lors de la récupération des détails de l'aliment : %w", err)
+	}
 	ratio := quantity / 100.0
-	meal.Calories += calories * ratio
-	meal.Proteins += proteins * ratio
-	meal.Carbohydrates += carbs * ratio
-	meal.Lipids += lipids * ratio
+	return models.MealItem{
+		FdcID:         fdcID,
+		Name:          food.Name,
+		Quantity:      quantity,
+		Calories:      food.Calories * ratio,
+		Proteins:      food.Proteins * ratio,
+		Carbohydrates: food.Carbohydrates * ratio,
+		Lipids:        food.Lipids * ratio,
+		LoggedAt:      time.Now(),
+	}, nil
+}
+
+// addMealItem enregistre item dans meal et ajoute ses valeurs aux totaux du repas
+func addMealItem(tx *gorm.DB, meal *models.Meal, item *models.MealItem) error {
+	item.MealID = meal.ID
+	if err := tx.Create(item).Error; err != nil {
+		return i18n.Errorf("erreur lors de l'enregistrement de l'aliment : %w", err)
+	}
+	if err := recordChange(tx, models.AuditCreate, EntityMealItem, item.ID, nil); err != nil {
+		return err
+	}
+	meal.Calories += item.Calories
+	meal.Proteins += item.Proteins
+	meal.Carbohydrates += item.Carbohydrates
+	meal.Lipids += item.Lipids
+	return auditedUpdate(tx, models.AuditUpdate, EntityMeal, meal.ID, func() error {
+		if err := tx.Save(meal).Error; err != nil {
+			return i18n.Errorf("erreur lors de la mise à jour du repas : %w", err)
+		}
+		return nil
+	})
+}
 
-	// Sauvegarder les modifications
-	if err := db.DB.Save(&meal).Error; err != nil {
-		return fmt.Errorf("erreur lors de la mise à jour du repas : %w", err)
+// removeMealItem supprime définitivement un aliment et déduit ses valeurs des totaux du repas
+func removeMealItem(tx *gorm.DB, item models.MealItem) error {
+	if err := auditedUpdate(tx, models.AuditUpdate, EntityMeal, item.MealID, func() error {
+		return tx.Model(&models.Meal{}).Where("id = ?", item.MealID).Updates(map[string]any{
+			"calories":      gorm.Expr("calories - ?", item.Calories),
+			"proteins":      gorm.Expr("proteins - ?", item.Proteins),
+			"carbohydrates": gorm.Expr("carbohydrates - ?", item.Carbohydrates),
+			"lipids":        gorm.Expr("lipids - ?", item.Lipids),
+		}).Error
+	}); err != nil {
+		return err
 	}
+	return auditedHardDelete(tx, EntityMealItem, item.ID)
+}
+
+// deleteMealPermanently supprime définitivement un repas et ses aliments
+func deleteMealPermanently(tx *gorm.DB, mealID uint) error {
+	var itemIDs []uint
+	if err := tx.Unscoped().Model(&models.MealItem{}).Where("meal_id = ?", mealID).Pluck("id", &itemIDs).Error; err != nil {
+		return err
+	}
+	for _, itemID := range itemIDs {
+		if err := auditedHardDelete(tx, EntityMealItem, itemID); err != nil {
+			return err
+		}
+	}
+	return auditedHardDelete(tx, EntityMeal, mealID)
+}
 
-	fmt.Printf("✔ Aliment '%s' (%.0f g) ajouté au repas\n", name, quantity)
+// recordMealCreation journalise la création d'un repas et de ses aliments
+func recordMealCreation(tx *gorm.DB, meal models.Meal) error {
+	if err := recordChange(tx, models.AuditCreate, EntityMeal, meal.ID, nil); err != nil {
+		return err
+	}
+	for _, item := range meal.Items {
+		if err := recordChange(tx, models.AuditCreate, EntityMealItem, item.ID, nil); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// GetMeal récupère un repas, type ou rattaché à un menu
+func GetMeal(id uint) (models.Meal, error) {
+	var meal models.Meal
+	if err := db.DB.First(&meal, id).Error; err != nil {
+		return meal, i18n.Errorf("erreur lors de la récupération du repas %d : %w", id, err)
+	}
+	return meal, nil
+}
+
+// GetMeals récupère les repas types, c'est-à-dire ceux qui ne sont rattachés à aucun menu
 func GetMeals() ([]models.Meal, error) {
 	var meals []models.Meal
+	if err := db.DB.Where("daily_menu_id IS NULL").Order("id").Find(&meals).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des repas : %w", err)
+	}
 	return meals, nil
 }
 
@@ -141,18 +410,29 @@ func ListMeals() error {
 	}
 
 	if len(meals) == 0 {
-		fmt.Println("Aucun repas enregistré.")
+		i18n.Println("Aucun repas enregistré.")
 		return nil
 	}
 
-	fmt.Println("🍽️ Repas enregistrés :")
-	for i, meal := range meals {
-		fmt.Printf("%d. %s (%s) | %.1f kcal | P: %.1f g | G: %.1f g | L: %.1f g\n",
-			i+1, meal.Description, meal.Type, meal.Calories, meal.Proteins, meal.Carbohydrates, meal.Lipids)
+	i18n.Println("🍽️ Repas enregistrés :")
+	for _, meal := range meals {
+		i18n.Printf("%d. %s (%s) | %.1f kcal | P: %.1f g | G: %.1f g | L: %.1f g\n",
+			meal.ID, meal.Description, meal.Type, meal.Calories, meal.Proteins, meal.Carbohydrates, meal.Lipids)
 	}
 	return nil
 }
 
-func AddMeal(meal models.Meal) error {
-	return db.DB.Create(&meal).Error
+func AddMeal(meal models.Meal) (models.Meal, error) {
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&meal).Error; err != nil {
+			return err
+		}
+		return recordMealCreation(tx, meal)
+	}); err != nil {
+		return meal, err
+	}
+	pushUndo(i18n.Sprintf("création du repas '%s'", meal.Description), func(tx *gorm.DB) error {
+		return deleteMealPermanently(tx, meal.ID)
+	})
+	return meal, nil
 }
@@ -0,0 +1,297 @@
+package fdc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// GetFood renvoie un aliment depuis le cache, ou depuis l'API FDC en le
+// mettant en cache ; sans base de données, l'API est toujours interrogée. Un
+// aliment sans nom est une erreur : il ne peut pas être ajouté à un repas.
+func GetFood(fdcID int) (models.Food, error) {
+	var food models.Food
+	if db.DB != nil {
+		if err := db.DB.Where("fdc_id = ?", fdcID).Limit(1).Find(&food).Error; err != nil {
+			return food, i18n.Errorf("erreur lors de la lecture du cache des aliments : %w", err)
+		}
+		if food.FdcID != 0 && food.Name != "" {
+			return food, nil
+		}
+	}
+	return fetchFood(fdcID)
+}
+
+// fetchFood interroge l'API FDC et met l'aliment en cache avec tous ses
+// nutriments, en remplaçant ceux déjà enregistrés
+func fetchFood(fdcID int) (models.Food, error) {
+	detail, err := getFood(fdcID)
+	if err != nil {
+		return models.Food{}, err
+	}
+	calories, proteins, carbs, lipids := detail.macros()
+	now := time.Now()
+	food := models.Food{
+		FdcID:         fdcID,
+		Name:          detail.Description,
+		Calories:      calories,
+		Proteins:      proteins,
+		Carbohydrates: carbs,
+		Lipids:        lipids,
+		FetchedAt:     now,
+		DetailedAt:    &now,
+	}
+	if food.Name == "" {
+		return food, i18n.Errorf("aliment %d introuvable dans FDC", fdcID)
+	}
+	if db.DB == nil {
+		return food, nil
+	}
+
+	nutrients := make([]models.FoodNutrient, 0, len(detail.FoodNutrients))
+	seen := map[string]bool{}
+	for _, n := range detail.FoodNutrients {
+		if n.Nutrient.Number == "" || seen[n.Nutrient.Number] {
+			continue
+		}
+		seen[n.Nutrient.Number] = true
+		nutrients = append(nutrients, models.FoodNutrient{FdcID: fdcID, Number: n.Nutrient.Number,
+			Name: n.Nutrient.Name, Unit: n.Nutrient.UnitName, Amount: n.Amount})
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&food).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("fdc_id = ?", fdcID).Delete(&models.FoodNutrient{}).Error; err != nil {
+			return err
+		}
+		if len(nutrients) == 0 {
+			return nil
+		}
+		return tx.Create(&nutrients).Error
+	})
+	if err != nil {
+		return food, i18n.Errorf("erreur lors de la mise en cache de l'aliment : %w", err)
+	}
+	return food, nil
+}
+
+// FoodNutrientAmounts renvoie, pour chacun des aliments fdcIDs, la quantité
+// pour 100 g de ses nutriments par numéro FDC. Les aliments mis en cache sans
+// leurs nutriments sont redemandés à l'API ; ceux qu'elle ne fournit pas sont
+// renvoyés dans missing.
+func FoodNutrientAmounts(fdcIDs []int) (amounts map[int]map[string]float64, missing []int, err error) {
+	var foods []models.Food
+	if err := db.DB.Where("fdc_id IN ?", fdcIDs).Find(&foods).Error; err != nil {
+		return nil, nil, i18n.Errorf("erreur lors de la lecture du cache des aliments : %w", err)
+	}
+	detailed := map[int]bool{}
+	for _, food := range foods {
+		detailed[food.FdcID] = food.DetailedAt != nil
+	}
+	for _, id := range fdcIDs {
+		if detailed[id] {
+			continue
+		}
+		if _, err := fetchFood(id); err != nil {
+			missing = append(missing, id)
+		}
+	}
+
+	var nutrients []models.FoodNutrient
+	if err := db.DB.Where("fdc_id IN ?", fdcIDs).Find(&nutrients).Error; err != nil {
+		return nil, nil, i18n.Errorf("erreur lors de la lecture des nutriments des aliments : %w", err)
+	}
+	amounts = map[int]map[string]float64{}
+	for _, n := range nutrients {
+		if amounts[n.FdcID] == nil {
+			amounts[n.FdcID] = map[string]float64{}
+		}
+		amounts[n.FdcID][n.Number] = n.Amount
+	}
+	return amounts, missing, nil
+}
+
+// FoodUsage résume la consommation d'un aliment par un utilisateur
+type FoodUsage struct {
+	FdcID int
+	Name  string
+	// Uses est le nombre d'ajouts de l'aliment à un repas
+	Uses int
+	// LastQuantity est la quantité en grammes du dernier ajout, 0 s'il n'y en a pas eu
+	LastQuantity float64
+	LastUsed     time.Time
+	Favorite     bool
+}
+
+// GetFoodUsage renvoie les aliments consommés ou mis en favori par un
+// utilisateur, le plus récemment consommé en premier ; les favoris jamais
+// consommés viennent à la fin
+func GetFoodUsage(userID uint) ([]FoodUsage, error) {
+	usage, err := consumedFoods(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := favoriteIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	markFavorites(usage, favorites)
+	index := map[int]int{}
+	for i, u := range usage {
+		index[u.FdcID] = i
+	}
+
+	// Le nom des favoris jamais consommés vient du cache
+	var unused []int
+	for _, id := range favorites {
+		if _, ok := index[id]; !ok {
+			unused = append(unused, id)
+		}
+	}
+	if len(unused) == 0 {
+		return usage, nil
+	}
+	var foods []models.Food
+	if err := db.DB.Where("fdc_id IN ?", unused).Find(&foods).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture du cache des aliments : %w", err)
+	}
+	names := map[int]string{}
+	for _, f := range foods {
+		names[f.FdcID] = f.Name
+	}
+	for _, id := range unused {
+		usage = append(usage, FoodUsage{FdcID: id, Name: names[id], Favorite: true})
+	}
+	return usage, nil
+}
+
+// consumedFoods agrège dans la base les aliments consommés par un
+// utilisateur, le plus récent en premier, au plus limit si limit est positif.
+// Le nom et la quantité sont ceux du dernier ajout ; Favorite n'est pas rempli.
+func consumedFoods(userID uint, limit int) ([]FoodUsage, error) {
+	ranked := db.DB.Model(&models.MealItem{}).
+		Select("meal_items.fdc_id, meal_items.name, meal_items.quantity AS last_quantity, "+
+			"meal_items.logged_at AS last_used, meal_items.id AS last_id, "+
+			"COUNT(*) OVER (PARTITION BY meal_items.fdc_id) AS uses, "+
+			"ROW_NUMBER() OVER (PARTITION BY meal_items.fdc_id ORDER BY meal_items.logged_at DESC, meal_items.id DESC) AS recency").
+		Joins("JOIN meals ON meals.id = meal_items.meal_id AND meals.deleted_at IS NULL").
+		Joins("JOIN daily_menus ON daily_menus.id = meals.daily_menu_id AND daily_menus.deleted_at IS NULL").
+		Where("daily_menus.user_id = ?", userID)
+	query := db.DB.Table("(?) AS ranked", ranked).Where("recency = 1").Order("last_used DESC, last_id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var usage []FoodUsage
+	if err := query.Scan(&usage).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des aliments consommés : %w", err)
+	}
+	return usage, nil
+}
+
+// favoriteIDs renvoie les aliments favoris d'un utilisateur, dans l'ordre où
+// ils ont été ajoutés
+func favoriteIDs(userID uint) ([]int, error) {
+	var ids []int
+	if err := db.DB.Model(&models.FavoriteFood{}).Where("user_id = ?", userID).Order("created_at").
+		Pluck("fdc_id", &ids).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des favoris : %w", err)
+	}
+	return ids, nil
+}
+
+// RecentFoods renvoie au plus limit aliments consommés par un utilisateur,
+// le plus récent en premier
+func RecentFoods(userID uint, limit int) ([]FoodUsage, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	recent, err := consumedFoods(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := favoriteIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	markFavorites(recent, favorites)
+	return recent, nil
+}
+
+// markFavorites marque dans usage les aliments de favorites
+func markFavorites(usage []FoodUsage, favorites []int) {
+	starred := map[int]bool{}
+	for _, id := range favorites {
+		starred[id] = true
+	}
+	for i := range usage {
+		usage[i].Favorite = starred[usage[i].FdcID]
+	}
+}
+
+// FavoriteFoods renvoie les aliments favoris d'un utilisateur, le plus
+// consommé en premier
+func FavoriteFoods(userID uint) ([]FoodUsage, error) {
+	usage, err := GetFoodUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	var favorites []FoodUsage
+	for _, u := range usage {
+		if u.Favorite {
+			favorites = append(favorites, u)
+		}
+	}
+	sort.SliceStable(favorites, func(i, j int) bool {
+		if favorites[i].Uses != favorites[j].Uses {
+			return favorites[i].Uses > favorites[j].Uses
+		}
+		return strings.ToLower(favorites[i].Name) < strings.ToLower(favorites[j].Name)
+	})
+	return favorites, nil
+}
+
+// StarFood met un aliment dans les favoris d'un utilisateur ; l'aliment est
+// mis en cache pour que la liste n'ait pas à interroger l'API
+func StarFood(userID uint, fdcID int) (models.Food, error) {
+	food, err := GetFood(fdcID)
+	if err != nil {
+		return food, i18n.Errorf("erreur lors de la récupération de l'aliment : %w", err)
+	}
+	favorite := models.FavoriteFood{UserID: userID, FdcID: fdcID}
+	result := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite)
+	if result.Error != nil {
+		return food, i18n.Errorf("erreur lors de l'ajout aux favoris : %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		pushUndo(i18n.Sprintf("ajout de %s aux favoris", food.Name), func(tx *gorm.DB) error {
+			return tx.Delete(&favorite).Error
+		})
+	}
+	return food, nil
+}
+
+// UnstarFood retire un aliment des favoris d'un utilisateur
+func UnstarFood(userID uint, fdcID int) error {
+	var favorite models.FavoriteFood
+	if err := db.DB.Where("user_id = ? AND fdc_id = ?", userID, fdcID).Limit(1).Find(&favorite).Error; err != nil {
+		return i18n.Errorf("erreur lors de la récupération des favoris : %w", err)
+	}
+	if favorite.FdcID == 0 {
+		return i18n.Errorf("l'aliment %d n'est pas dans les favoris", fdcID)
+	}
+	if err := db.DB.Delete(&favorite).Error; err != nil {
+		return i18n.Errorf("erreur lors du retrait des favoris : %w", err)
+	}
+	pushUndo(i18n.Sprintf("retrait de l'aliment %d des favoris", fdcID), func(tx *gorm.DB) error {
+		return tx.Create(&favorite).Error
+	})
+	return nil
+}
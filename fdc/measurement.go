@@ -0,0 +1,74 @@
+package fdc
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// AddMeasurement enregistre une mesure datée de maintenant. La masse grasse
+// n'est calculée que si les tours de taille et de cou sont renseignés
+// (et celui des hanches pour les femmes).
+func AddMeasurement(userID uint, weight, height, waist, neck, hip float64) (models.Measurement, error) {
+	user, err := GetUser(userID)
+	if err != nil {
+		return models.Measurement{}, err
+	}
+
+	bmi, err := models.CalculateBMI(weight, height)
+	if err != nil {
+		return models.Measurement{}, err
+	}
+
+	measurement := models.Measurement{
+		UserID: user.ID,
+		Date:   user.Now(),
+		Weight: weight,
+		Height: height,
+		BMI:    bmi,
+	}
+	if waist > 0 && neck > 0 {
+		bodyFat, err := models.CalculateBodyFat(user.Gender, height, waist, neck, hip)
+		if err != nil {
+			return models.Measurement{}, err
+		}
+		measurement.BodyFat = bodyFat
+	}
+
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&measurement).Error; err != nil {
+			return i18n.Errorf("erreur lors de l'enregistrement de la mesure : %w", err)
+		}
+		return recordChange(tx, models.AuditCreate, EntityMeasurement, measurement.ID, nil)
+	}); err != nil {
+		return measurement, err
+	}
+
+	id := measurement.ID
+	pushUndo(i18n.Sprintf("ajout de la mesure %d", id), func(tx *gorm.DB) error {
+		return auditedHardDelete(tx, EntityMeasurement, id)
+	})
+	return measurement, nil
+}
+
+// ListMeasurements affiche les mesures d'un utilisateur
+func ListMeasurements(userID uint) error {
+	user, err := GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if len(user.Measurements) == 0 {
+		i18n.Printf("Aucune mesure enregistrée pour %s %s.\n", user.FirstName, user.LastName)
+		return nil
+	}
+
+	i18n.Printf("📏 Mesures de %s %s :\n", user.FirstName, user.LastName)
+	for _, m := range user.Measurements {
+		i18n.Printf("%d. %s | %.1f kg | %.0f cm | IMC %.2f | MG %.0f %%\n",
+			m.ID, i18n.DateTime(m.Date.In(user.Location())), m.Weight, m.Height, m.BMI, m.BodyFat)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package fdc
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// IntakeStatus situe un apport par rapport à sa référence
+type IntakeStatus string
+
+const (
+	// StatusLow est un apport moyen inférieur à l'apport de référence
+	StatusLow IntakeStatus = "low"
+	// StatusMet est un apport moyen qui atteint la référence sans dépasser la limite
+	StatusMet IntakeStatus = "met"
+	// StatusAboveUpper est un apport moyen au-delà de l'apport maximal tolérable
+	StatusAboveUpper IntakeStatus = "above_upper"
+)
+
+// MicronutrientDay contient les apports en micronutriments d'un jour
+// enregistré, par nutriment, ainsi que le rétinol comparé à la limite de la
+// vitamine A
+type MicronutrientDay struct {
+	MenuID  uint
+	Date    time.Time
+	Amounts map[Nutrient]float64
+}
+
+// MicronutrientIntake compare l'apport moyen d'un micronutriment à sa référence
+type MicronutrientIntake struct {
+	Reference
+	// Average est l'apport moyen des jours enregistrés
+	Average float64
+	// UpperAverage est l'apport moyen en UpperOf, comparé à la limite : le
+	// même que Average sauf pour la vitamine A
+	UpperAverage float64
+	// DaysAboveUpper est le nombre de jours au-delà de l'apport maximal tolérable
+	DaysAboveUpper int
+}
+
+// Percent renvoie l'apport moyen en pourcentage de la référence
+func (m MicronutrientIntake) Percent() float64 {
+	return Share(m.Average, m.Intake)
+}
+
+// Status situe l'apport moyen par rapport à la référence et à la limite
+func (m MicronutrientIntake) Status() IntakeStatus {
+	switch {
+	case m.Upper > 0 && m.UpperAverage > m.Upper:
+		return StatusAboveUpper
+	case m.Average >= m.Intake:
+		return StatusMet
+	}
+	return StatusLow
+}
+
+// MicronutrientReport compare les apports en micronutriments d'un
+// utilisateur aux apports de référence de son âge et de son sexe
+type MicronutrientReport struct {
+	User models.User
+	From time.Time
+	// To est exclu de la période
+	To time.Time
+	// Days ne contient que les jours enregistrés, par date
+	Days      []MicronutrientDay
+	Nutrients []MicronutrientIntake
+	// Missing sont les aliments dont les détails FDC n'ont pas pu être
+	// obtenus, et qui ne sont pas comptés
+	Missing []int
+}
+
+// GetMicronutrients calcule, jour par jour sur la période de from à to
+// (exclu), les apports en Micronutrients de l'utilisateur userID à partir
+// des détails FDC de ses aliments, et les compare aux apports de référence
+// de son âge et de son sexe
+func GetMicronutrients(userID uint, from, to time.Time) (MicronutrientReport, error) {
+	user, err := GetUser(userID)
+	if err != nil {
+		return MicronutrientReport{}, err
+	}
+	report := MicronutrientReport{User: user, From: from, To: to}
+	if user.Age <= 0 || (user.Gender != models.Male && user.Gender != models.Female) {
+		return report, i18n.Errorf("âge ou sexe inconnu pour %s %s : renseignez-les (gofit edituser)",
+			user.FirstName, user.LastName)
+	}
+
+	b, err := GetBreakdown(ReportFilter{UserID: user.ID, From: from, To: to})
+	if err != nil {
+		return report, err
+	}
+	var ids []int
+	seen := map[int]bool{}
+	for _, day := range b.Days {
+		for _, meal := range day.Meals {
+			for _, item := range meal.Items {
+				if !seen[item.FdcID] {
+					seen[item.FdcID] = true
+					ids = append(ids, item.FdcID)
+				}
+			}
+		}
+	}
+	amounts := map[int]map[string]float64{}
+	if len(ids) > 0 {
+		if amounts, report.Missing, err = FoodNutrientAmounts(ids); err != nil {
+			return report, err
+		}
+	}
+
+	counted := slices.Concat(Micronutrients, []Nutrient{NutrientRetinol})
+	for _, day := range b.Days {
+		d := MicronutrientDay{MenuID: day.MenuID, Date: models.CalendarDay(day.Date), Amounts: map[Nutrient]float64{}}
+		for _, meal := range day.Meals {
+			for _, item := range meal.Items {
+				for _, n := range counted {
+					d.Amounts[n] += amounts[item.FdcID][n.Number()] * item.Quantity / 100
+				}
+			}
+		}
+		report.Days = append(report.Days, d)
+	}
+
+	for _, n := range Micronutrients {
+		ref, ok := ReferenceFor(user, n)
+		if !ok {
+			continue
+		}
+		intake := MicronutrientIntake{Reference: ref}
+		for _, day := range report.Days {
+			intake.Average += day.Amounts[n]
+			intake.UpperAverage += day.Amounts[ref.UpperOf]
+			if ref.Upper > 0 && day.Amounts[ref.UpperOf] > ref.Upper {
+				intake.DaysAboveUpper++
+			}
+		}
+		if len(report.Days) > 0 {
+			intake.Average /= float64(len(report.Days))
+			intake.UpperAverage /= float64(len(report.Days))
+		}
+		report.Nutrients = append(report.Nutrients, intake)
+	}
+	return report, nil
+}
+
+// PrintMicronutrients affiche l'apport moyen de chaque micronutriment en
+// pourcentage de sa référence ; avec detail, l'apport de chaque jour. Avec
+// color, le statut est en vert si la référence est atteinte, en jaune en
+// deçà et en rouge au-delà de la limite.
+func PrintMicronutrients(report MicronutrientReport, detail, color bool) {
+	user := report.User
+	last := report.To.AddDate(0, 0, -1)
+	i18n.Printf("\n💊 Micronutriments de %s %s du %s au %s (%d ans, %s) :\n",
+		user.FirstName, user.LastName, i18n.Date(report.From), i18n.Date(last), user.Age, strings.ToLower(user.Gender.Label()))
+	if len(report.Days) == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
+		return
+	}
+
+	if detail {
+		table := tablewriter.NewWriter(os.Stdout)
+		header := []string{i18n.T("Nutriment")}
+		align := []int{tablewriter.ALIGN_DEFAULT}
+		for _, day := range report.Days {
+			header = append(header, i18n.Date(day.Date))
+			align = append(align, tablewriter.ALIGN_RIGHT)
+		}
+		table.SetHeader(header)
+		table.SetColumnAlignment(align)
+		for _, n := range report.Nutrients {
+			row := []string{i18n.Sprintf("%s (%s)", n.Nutrient.Label(), n.Nutrient.Unit())}
+			colors := []tablewriter.Colors{{}}
+			for _, day := range report.Days {
+				v := day.Amounts[n.Nutrient]
+				row = append(row, i18n.Float(v, 1))
+				c := tablewriter.Colors{}
+				if color && n.Upper > 0 && day.Amounts[n.UpperOf] > n.Upper {
+					c = tablewriter.Colors{tablewriter.FgRedColor}
+				}
+				colors = append(colors, c)
+			}
+			table.Rich(row, colors)
+		}
+		table.Render()
+	}
+
+	i18n.Printf("\n📋 Apports moyens sur %d jour(s) enregistré(s) :\n", len(report.Days))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{i18n.T("Nutriment"), i18n.T("Apport moyen / jour"), i18n.T("Référence"),
+		i18n.T("Atteint"), i18n.T("Limite"), i18n.T("Jours au-delà"), i18n.T("Statut")})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_DEFAULT})
+	for _, n := range report.Nutrients {
+		unit := n.Nutrient.Unit()
+		reference := i18n.Sprintf("%s %s", i18n.Float(n.Intake, 1), unit)
+		if n.Adequate {
+			reference += " (AI)"
+		}
+		upper, above := "", ""
+		if n.Upper > 0 {
+			upper = i18n.Sprintf("%s %s", i18n.Float(n.Upper, 0), unit)
+			if n.UpperOf != n.Nutrient {
+				upper = i18n.Sprintf("%s %s (%s)", i18n.Float(n.Upper, 0), n.UpperOf.Unit(), n.UpperOf.Label())
+			}
+			above = i18n.Sprintf("%d / %d", n.DaysAboveUpper, len(report.Days))
+		}
+		table.Rich([]string{
+			n.Nutrient.Label(),
+			i18n.Sprintf("%s %s", i18n.Float(n.Average, 1), unit),
+			reference,
+			i18n.Sprintf("%s %%", i18n.Float(n.Percent(), 0)),
+			upper,
+			above,
+			n.Status().Label(),
+		}, []tablewriter.Colors{{}, {}, {}, {}, {}, {}, statusColor(n.Status(), color)})
+	}
+	table.Render()
+	i18n.Println("Références : apports nutritionnels conseillés (RDA) ou apports adéquats (AI) des National Academies ; limite : apport maximal tolérable (UL)")
+	i18n.Println("La limite de la vitamine A ne vise que le rétinol (vitamine A préformée), pas les caroténoïdes des végétaux")
+	if len(report.Missing) > 0 {
+		i18n.Printf("⚠️ %d aliment(s) sans détails nutritionnels (API FDC injoignable ?) ne sont pas comptés\n", len(report.Missing))
+	}
+}
+
+// Label renvoie le nom affiché du statut
+func (s IntakeStatus) Label() string {
+	switch s {
+	case StatusAboveUpper:
+		return i18n.T("⚠️ au-delà de la limite")
+	case StatusMet:
+		return i18n.T("atteint")
+	}
+	return i18n.T("insuffisant")
+}
+
+// statusColor renvoie la couleur d'un statut
+func statusColor(s IntakeStatus, color bool) tablewriter.Colors {
+	switch {
+	case !color:
+		return tablewriter.Colors{}
+	case s == StatusAboveUpper:
+		return tablewriter.Colors{tablewriter.FgRedColor}
+	case s == StatusMet:
+		return tablewriter.Colors{tablewriter.FgGreenColor}
+	}
+	return tablewriter.Colors{tablewriter.FgYellowColor}
+}
@@ -0,0 +1,161 @@
+package fdc
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// Nutrient désigne un apport : calories, macronutriments, ou un nutriment
+// relevé dans les détails FDC des aliments
+type Nutrient string
+
+const (
+	NutrientCalories      Nutrient = "calories"
+	NutrientProteins      Nutrient = "proteins"
+	NutrientCarbohydrates Nutrient = "carbohydrates"
+	NutrientLipids        Nutrient = "lipids"
+	NutrientSugars        Nutrient = "sugars"
+	NutrientFiber         Nutrient = "fiber"
+	NutrientSaturatedFat  Nutrient = "saturated_fat"
+	NutrientSodium        Nutrient = "sodium"
+	NutrientCholesterol   Nutrient = "cholesterol"
+
+	NutrientVitaminA   Nutrient = "vitamin_a"
+	NutrientVitaminC   Nutrient = "vitamin_c"
+	NutrientVitaminD   Nutrient = "vitamin_d"
+	NutrientVitaminE   Nutrient = "vitamin_e"
+	NutrientVitaminK   Nutrient = "vitamin_k"
+	NutrientThiamin    Nutrient = "thiamin"
+	NutrientRiboflavin Nutrient = "riboflavin"
+	NutrientNiacin     Nutrient = "niacin"
+	NutrientVitaminB6  Nutrient = "vitamin_b6"
+	NutrientFolate     Nutrient = "folate"
+	NutrientVitaminB12 Nutrient = "vitamin_b12"
+	NutrientCalcium    Nutrient = "calcium"
+	NutrientIron       Nutrient = "iron"
+	NutrientMagnesium  Nutrient = "magnesium"
+	NutrientPhosphorus Nutrient = "phosphorus"
+	NutrientPotassium  Nutrient = "potassium"
+	NutrientZinc       Nutrient = "zinc"
+	NutrientSelenium   Nutrient = "selenium"
+
+	// NutrientRetinol est la vitamine A préformée, seule visée par l'apport
+	// maximal tolérable de la vitamine A
+	NutrientRetinol Nutrient = "retinol"
+)
+
+// TrackedNutrients liste les apports suivis, dans l'ordre d'affichage
+var TrackedNutrients = []Nutrient{NutrientCalories, NutrientProteins, NutrientCarbohydrates, NutrientLipids}
+
+// RankedNutrients liste les apports selon lesquels les aliments peuvent être classés
+var RankedNutrients = []Nutrient{NutrientCalories, NutrientProteins, NutrientCarbohydrates, NutrientLipids,
+	NutrientSugars, NutrientFiber, NutrientSaturatedFat, NutrientSodium, NutrientCholesterol}
+
+// Micronutrients liste les vitamines, minéraux et fibres comparés aux
+// apports de référence, dans l'ordre d'affichage
+var Micronutrients = []Nutrient{NutrientVitaminA, NutrientVitaminC, NutrientVitaminD, NutrientVitaminE,
+	NutrientVitaminK, NutrientThiamin, NutrientRiboflavin, NutrientNiacin, NutrientVitaminB6, NutrientFolate,
+	NutrientVitaminB12, NutrientCalcium, NutrientIron, NutrientMagnesium, NutrientPhosphorus, NutrientPotassium,
+	NutrientSodium, NutrientZinc, NutrientSelenium, NutrientFiber}
+
+// nutrientInfo décrit un apport : son numéro de nutriment FDC, son unité et
+// son nom affiché
+type nutrientInfo struct {
+	number string
+	unit   string
+	label  string
+}
+
+var nutrientInfos = map[Nutrient]nutrientInfo{
+	NutrientCalories:      {"208", "kcal", "Calories"},
+	NutrientProteins:      {"203", "g", "Protéines"},
+	NutrientCarbohydrates: {"205", "g", "Glucides"},
+	NutrientLipids:        {"204", "g", "Lipides"},
+	NutrientSugars:        {"269", "g", "Sucres"},
+	NutrientFiber:         {"291", "g", "Fibres"},
+	NutrientSaturatedFat:  {"606", "g", "Acides gras saturés"},
+	NutrientSodium:        {"307", "mg", "Sodium"},
+	NutrientCholesterol:   {"601", "mg", "Cholestérol"},
+	NutrientVitaminA:      {"320", "µg", "Vitamine A"},
+	NutrientVitaminC:      {"401", "mg", "Vitamine C"},
+	NutrientVitaminD:      {"328", "µg", "Vitamine D"},
+	NutrientVitaminE:      {"323", "mg", "Vitamine E"},
+	NutrientVitaminK:      {"430", "µg", "Vitamine K"},
+	NutrientThiamin:       {"404", "mg", "Thiamine (B1)"},
+	NutrientRiboflavin:    {"405", "mg", "Riboflavine (B2)"},
+	NutrientNiacin:        {"406", "mg", "Niacine (B3)"},
+	NutrientVitaminB6:     {"415", "mg", "Vitamine B6"},
+	NutrientFolate:        {"435", "µg", "Folates (B9)"},
+	NutrientVitaminB12:    {"418", "µg", "Vitamine B12"},
+	NutrientCalcium:       {"301", "mg", "Calcium"},
+	NutrientIron:          {"303", "mg", "Fer"},
+	NutrientMagnesium:     {"304", "mg", "Magnésium"},
+	NutrientPhosphorus:    {"305", "mg", "Phosphore"},
+	NutrientPotassium:     {"306", "mg", "Potassium"},
+	NutrientZinc:          {"309", "mg", "Zinc"},
+	NutrientSelenium:      {"317", "µg", "Sélénium"},
+	NutrientRetinol:       {"319", "µg", "Rétinol"},
+}
+
+// ParseNutrient lit le nom d'un des RankedNutrients ou des Micronutrients
+func ParseNutrient(s string) (Nutrient, error) {
+	n := Nutrient(strings.ReplaceAll(strings.ToLower(s), "-", "_"))
+	accepted := slices.Concat(RankedNutrients, Micronutrients)
+	if slices.Contains(accepted, n) {
+		return n, nil
+	}
+	var names []string
+	for _, r := range accepted {
+		if !slices.Contains(names, string(r)) {
+			names = append(names, string(r))
+		}
+	}
+	return "", i18n.Errorf("%q : utilisez %s", s, strings.Join(names, ", "))
+}
+
+// Label renvoie le nom affiché de l'apport
+func (n Nutrient) Label() string {
+	if info, ok := nutrientInfos[n]; ok {
+		return i18n.T(info.label)
+	}
+	return string(n)
+}
+
+// Unit renvoie l'unité de l'apport
+func (n Nutrient) Unit() string {
+	return nutrientInfos[n].unit
+}
+
+// Number renvoie le numéro FDC du nutriment, ex. 307 pour le sodium
+func (n Nutrient) Number() string {
+	return nutrientInfos[n].number
+}
+
+// Macro indique si l'apport est enregistré avec chaque aliment des repas,
+// sans besoin des détails FDC
+func (n Nutrient) Macro() bool {
+	for _, t := range TrackedNutrients {
+		if t == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Get renvoie la valeur de l'apport nutrient ; 0 pour un nutriment qui n'est
+// pas un des TrackedNutrients
+func (n Nutrients) Get(nutrient Nutrient) float64 {
+	switch nutrient {
+	case NutrientCalories:
+		return n.Calories
+	case NutrientProteins:
+		return n.Proteins
+	case NutrientCarbohydrates:
+		return n.Carbohydrates
+	case NutrientLipids:
+		return n.Lipids
+	}
+	return 0
+}
@@ -0,0 +1,272 @@
+package fdc
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// Period est la durée sur laquelle le rapport regroupe les jours
+type Period string
+
+const (
+	// PeriodWeek regroupe les jours par semaine ISO, du lundi au dimanche
+	PeriodWeek Period = "week"
+	// PeriodMonth regroupe les jours par mois calendaire
+	PeriodMonth Period = "month"
+)
+
+// minCorrelationPeriods est le nombre de périodes en dessous duquel la
+// corrélation avec le poids n'est pas calculée
+const minCorrelationPeriods = 3
+
+// Start renvoie le premier jour de la période contenant le jour calendaire day
+func (p Period) Start(day time.Time) time.Time {
+	day = models.CalendarDay(day)
+	if p == PeriodMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Next renvoie le premier jour de la période qui suit celle commençant à start
+func (p Period) Next(start time.Time) time.Time {
+	if p == PeriodMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// Label renvoie le nom de la période commençant à start : 2026-W38 pour une
+// semaine ISO, 2026-09 pour un mois
+func (p Period) Label(start time.Time) string {
+	if p == PeriodMonth {
+		return start.Format("2006-01")
+	}
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// PeriodTotals contient les apports d'un utilisateur sur une semaine ou un mois
+type PeriodTotals struct {
+	User  models.User
+	Start time.Time
+	// End est exclu de la période
+	End time.Time
+	// Days est le nombre de jours enregistrés, seuls comptés dans la moyenne
+	Days    int
+	Total   Nutrients
+	Average Nutrients
+	Min     Nutrients
+	Max     Nutrients
+	// Delta est l'écart de la moyenne avec celle de la période précédente ;
+	// nil si l'utilisateur n'y a aucun jour enregistré
+	Delta *Nutrients
+	// Weight est le poids moyen des mesures de la période ; 0 sans mesure
+	Weight float64
+	// WeightDelta est l'écart de poids avec la période précédente ; nil si
+	// l'une des deux n'a pas de mesure
+	WeightDelta *float64
+}
+
+// WeightCorrelation est la corrélation, pour un utilisateur, entre l'apport
+// calorique moyen d'une période et la variation de son poids
+type WeightCorrelation struct {
+	User models.User
+	// R est le coefficient de corrélation de Pearson, entre -1 et 1
+	R float64
+	// Periods est le nombre de périodes ayant servi au calcul
+	Periods int
+}
+
+// GetPeriodReport regroupe par période les jours du rapport correspondant au
+// filtre, utilisateur par utilisateur, et y ajoute le poids moyen des mesures
+func GetPeriodReport(filter ReportFilter, by Period) ([]PeriodTotals, error) {
+	report, err := GetNutritionalReport(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var periods []PeriodTotals
+	index := map[uint]map[time.Time]int{}
+	for _, day := range report {
+		start := by.Start(day.Date)
+		if index[day.User.ID] == nil {
+			index[day.User.ID] = map[time.Time]int{}
+		}
+		i, ok := index[day.User.ID][start]
+		if !ok {
+			i = len(periods)
+			index[day.User.ID][start] = i
+			periods = append(periods, PeriodTotals{User: day.User, Start: start, End: by.Next(start),
+				Min: day.Nutrients, Max: day.Nutrients})
+		}
+		p := &periods[i]
+		p.Days++
+		p.Total.add(day.Nutrients)
+		p.Min = Nutrients{math.Min(p.Min.Calories, day.Calories), math.Min(p.Min.Proteins, day.Proteins),
+			math.Min(p.Min.Carbohydrates, day.Carbohydrates), math.Min(p.Min.Lipids, day.Lipids)}
+		p.Max = Nutrients{math.Max(p.Max.Calories, day.Calories), math.Max(p.Max.Proteins, day.Proteins),
+			math.Max(p.Max.Carbohydrates, day.Carbohydrates), math.Max(p.Max.Lipids, day.Lipids)}
+	}
+	if len(periods) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(index))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	var measurements []models.Measurement
+	if err := db.DB.Where("user_id IN ?", ids).Order("date").Find(&measurements).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des mesures : %w", err)
+	}
+
+	sort.SliceStable(periods, func(i, j int) bool {
+		if periods[i].User.ID != periods[j].User.ID {
+			return userLess(periods[i].User, periods[j].User)
+		}
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	for i := range periods {
+		p := &periods[i]
+		p.Average = p.Total.scaled(1 / float64(p.Days))
+		var weight float64
+		var n int
+		for _, m := range measurements {
+			day := models.CalendarDay(m.Date.In(p.User.Location()))
+			if m.UserID == p.User.ID && !day.Before(p.Start) && day.Before(p.End) {
+				weight += m.Weight
+				n++
+			}
+		}
+		if n > 0 {
+			p.Weight = weight / float64(n)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := periods[i-1]
+		if prev.User.ID != p.User.ID || !by.Next(prev.Start).Equal(p.Start) {
+			continue
+		}
+		delta := p.Average
+		delta.add(prev.Average.scaled(-1))
+		p.Delta = &delta
+		if p.Weight > 0 && prev.Weight > 0 {
+			weightDelta := p.Weight - prev.Weight
+			p.WeightDelta = &weightDelta
+		}
+	}
+	return periods, nil
+}
+
+// CorrelateWeight calcule, pour chaque utilisateur de periods ayant au moins
+// minCorrelationPeriods périodes avec une variation de poids, la corrélation
+// entre son apport calorique moyen et cette variation
+func CorrelateWeight(periods []PeriodTotals) []WeightCorrelation {
+	var correlations []WeightCorrelation
+	for start := 0; start < len(periods); {
+		end := start
+		var calories, weights []float64
+		for ; end < len(periods) && periods[end].User.ID == periods[start].User.ID; end++ {
+			if p := periods[end]; p.WeightDelta != nil {
+				calories = append(calories, p.Average.Calories)
+				weights = append(weights, *p.WeightDelta)
+			}
+		}
+		if len(calories) >= minCorrelationPeriods {
+			if r, ok := pearson(calories, weights); ok {
+				correlations = append(correlations, WeightCorrelation{periods[start].User, r, len(calories)})
+			}
+		}
+		start = end
+	}
+	return correlations
+}
+
+// pearson renvoie le coefficient de corrélation de xs et ys ; faux si l'une
+// des séries est constante
+func pearson(xs, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx, my = mx/n, my/n
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(vx*vy), true
+}
+
+// GeneratePeriodReport affiche le rapport nutritionnel regroupé par semaine
+// ou par mois : pour chaque utilisateur, ses périodes par date puis la
+// corrélation entre son apport calorique et la variation de son poids
+func GeneratePeriodReport(filter ReportFilter, by Period) error {
+	periods, err := GetPeriodReport(filter, by)
+	if err != nil {
+		return err
+	}
+
+	if len(periods) == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{i18n.T("Période"), i18n.T("Utilisateur"), i18n.T("Jours"),
+		i18n.T("Total (kcal)"), i18n.T("Moyenne (kcal)"), i18n.T("Min"), i18n.T("Max"), i18n.T("Δ moyenne"),
+		i18n.T("P / G / L (g/j)"), i18n.T("Poids moyen"), i18n.T("Δ poids")})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, p := range periods {
+		delta, weight, weightDelta := "", "", ""
+		if p.Delta != nil {
+			delta = signedFloat(p.Delta.Calories, 0)
+		}
+		if p.Weight > 0 {
+			weight = i18n.Sprintf("%s kg", i18n.Float(p.Weight, 1))
+		}
+		if p.WeightDelta != nil {
+			weightDelta = signedFloat(*p.WeightDelta, 1)
+		}
+		table.Append([]string{by.Label(p.Start), i18n.Sprintf("%s %s", p.User.FirstName, p.User.LastName),
+			strconv.Itoa(p.Days), i18n.Float(p.Total.Calories, 0), i18n.Float(p.Average.Calories, 0),
+			i18n.Float(p.Min.Calories, 0), i18n.Float(p.Max.Calories, 0), delta,
+			i18n.Sprintf("%s / %s / %s", i18n.Float(p.Average.Proteins, 0), i18n.Float(p.Average.Carbohydrates, 0),
+				i18n.Float(p.Average.Lipids, 0)),
+			weight, weightDelta})
+	}
+
+	if by == PeriodMonth {
+		i18n.Println("\n📊 Rapport nutritionnel par mois :")
+	} else {
+		i18n.Println("\n📊 Rapport nutritionnel par semaine :")
+	}
+	table.Render()
+
+	for _, c := range CorrelateWeight(periods) {
+		i18n.Printf("⚖️  %s %s : corrélation entre apport calorique moyen et variation du poids r = %s (%d périodes)\n",
+			c.User.FirstName, c.User.LastName, i18n.Float(c.R, 2), c.Periods)
+	}
+	return nil
+}
@@ -1,55 +1,179 @@
 package fdc
 
 import (
-	"fmt"
 	"os"
-	"strconv"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"gorm.io/gorm"
 
 	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
 	"github.com/lsoulet/gofit/models"
 )
 
-// GenerateNutritionalReport génère un rapport nutritionnel pour tous les menus journaliers
-func GenerateNutritionalReport() error {
+// Nutrients contient des apports en calories et en macronutriments
+type Nutrients struct {
+	Calories      float64
+	Proteins      float64
+	Carbohydrates float64
+	Lipids        float64
+}
+
+func (n *Nutrients) add(o Nutrients) {
+	n.Calories += o.Calories
+	n.Proteins += o.Proteins
+	n.Carbohydrates += o.Carbohydrates
+	n.Lipids += o.Lipids
+}
+
+// scaled renvoie les apports multipliés par f
+func (n Nutrients) scaled(f float64) Nutrients {
+	return Nutrients{n.Calories * f, n.Proteins * f, n.Carbohydrates * f, n.Lipids * f}
+}
+
+// DailyTotals contient les apports d'un menu journalier
+type DailyTotals struct {
+	MenuID uint
+	Date   time.Time
+	User   models.User
+	Nutrients
+}
+
+// UserSummary résume les apports d'un utilisateur sur la période du rapport
+type UserSummary struct {
+	User models.User
+	// Days est le nombre de jours du rapport, seuls comptés dans la moyenne
+	Days    int
+	Total   Nutrients
+	Average Nutrients
+}
+
+// ReportFilter restreint le rapport à un utilisateur, une période et un type
+// de repas ; les champs vides ne filtrent pas
+type ReportFilter struct {
+	UserID uint
+	From   time.Time
+	// To est exclu de la période
+	To       time.Time
+	MealType models.MealType
+}
+
+// GetNutritionalReport calcule les apports de chaque menu journalier
+// correspondant au filtre, par date puis par utilisateur. Avec un type de
+// repas, seuls ces repas sont comptés et les jours qui n'en ont pas sont omis.
+func GetNutritionalReport(filter ReportFilter) ([]DailyTotals, error) {
+	query := db.DB.Preload("User").Order("date, user_id")
+	if filter.MealType != "" {
+		query = query.Preload("Meals", func(tx *gorm.DB) *gorm.DB { return tx.Where("type = ?", filter.MealType) })
+	} else {
+		query = query.Preload("Meals")
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date < ?", filter.To)
+	}
 	var menus []models.DailyMenu
-	if err := db.DB.Preload("User").Preload("Meals").Find(&menus).Error; err != nil {
-		return fmt.Errorf("erreur lors de la récupération des menus : %w", err)
+	if err := query.Find(&menus).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des menus : %w", err)
 	}
 
-	if len(menus) == 0 {
-		fmt.Println("Aucun menu journalier enregistré.")
+	report := make([]DailyTotals, 0, len(menus))
+	for _, menu := range menus {
+		if filter.MealType != "" && len(menu.Meals) == 0 {
+			continue
+		}
+		totals := DailyTotals{MenuID: menu.ID, Date: menu.Date, User: menu.User}
+		for _, meal := range menu.Meals {
+			cal, prot, carb, lipid := meal.GetMacros()
+			totals.add(Nutrients{cal, prot, carb, lipid})
+		}
+		report = append(report, totals)
+	}
+	return report, nil
+}
+
+// SummarizeByUser calcule le total et la moyenne journalière de chaque
+// utilisateur présent dans report, triés par nom
+func SummarizeByUser(report []DailyTotals) []UserSummary {
+	var summaries []UserSummary
+	index := map[uint]int{}
+	for _, day := range report {
+		i, ok := index[day.User.ID]
+		if !ok {
+			i = len(summaries)
+			index[day.User.ID] = i
+			summaries = append(summaries, UserSummary{User: day.User})
+		}
+		summaries[i].Days++
+		summaries[i].Total.add(day.Nutrients)
+	}
+	for i := range summaries {
+		summaries[i].Average = summaries[i].Total.scaled(1 / float64(summaries[i].Days))
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return userLess(summaries[i].User, summaries[j].User) })
+	return summaries
+}
+
+func userLess(a, b models.User) bool {
+	if !strings.EqualFold(a.LastName, b.LastName) {
+		return strings.ToLower(a.LastName) < strings.ToLower(b.LastName)
+	}
+	if !strings.EqualFold(a.FirstName, b.FirstName) {
+		return strings.ToLower(a.FirstName) < strings.ToLower(b.FirstName)
+	}
+	return a.ID < b.ID
+}
+
+// GenerateNutritionalReport affiche le rapport nutritionnel des menus
+// journaliers correspondant au filtre : les jours de chaque utilisateur par
+// date, suivis de son sous-total et de sa moyenne journalière
+func GenerateNutritionalReport(filter ReportFilter) error {
+	report, err := GetNutritionalReport(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(report) == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
 		return nil
 	}
 
 	// Créer le tableau
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Date", "Utilisateur", "Calories", "Protéines", "Glucides", "Lipides"})
+	table.SetHeader([]string{i18n.T("Date"), i18n.T("Utilisateur"), i18n.T("Calories"), i18n.T("Protéines"), i18n.T("Glucides"), i18n.T("Lipides")})
+	// Les nombres à virgule ne sont pas reconnus comme tels par tablewriter
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
 
-	// Pour chaque menu, calculer les totaux
-	for _, menu := range menus {
-		var totalCal, totalProt, totalCarb, totalLip float64
-		for _, meal := range menu.Meals {
-			totalCal += meal.Calories
-			totalProt += meal.Proteins
-			totalCarb += meal.Carbohydrates
-			totalLip += meal.Lipids
+	row := func(label, user string, n Nutrients) []string {
+		return []string{label, user,
+			i18n.Float(n.Calories, 1), i18n.Float(n.Proteins, 1), i18n.Float(n.Carbohydrates, 1), i18n.Float(n.Lipids, 1)}
+	}
+	for _, summary := range SummarizeByUser(report) {
+		name := i18n.Sprintf("%s %s", summary.User.FirstName, summary.User.LastName)
+		for _, day := range report {
+			if day.User.ID == summary.User.ID {
+				table.Append(row(i18n.Date(day.Date), name, day.Nutrients))
+			}
 		}
-
-		// Ajouter une ligne au tableau
-		table.Append([]string{
-			menu.Date.Format("02/01/2006"),
-			fmt.Sprintf("%s %s", menu.User.FirstName, menu.User.LastName),
-			strconv.FormatFloat(totalCal, 'f', 1, 64),
-			strconv.FormatFloat(totalProt, 'f', 1, 64),
-			strconv.FormatFloat(totalCarb, 'f', 1, 64),
-			strconv.FormatFloat(totalLip, 'f', 1, 64),
-		})
+		table.Append(row(i18n.T("Sous-total"), name, summary.Total))
+		table.Append(row(i18n.Sprintf("Moyenne (%d j)", summary.Days), name, summary.Average))
 	}
 
 	// Afficher le tableau
-	fmt.Println("\n📊 Rapport nutritionnel :")
+	if filter.MealType != "" {
+		i18n.Printf("\n📊 Rapport nutritionnel (repas %s) :\n", filter.MealType)
+	} else {
+		i18n.Println("\n📊 Rapport nutritionnel :")
+	}
 	table.Render()
 	return nil
 }
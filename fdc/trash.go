@@ -0,0 +1,340 @@
+package fdc
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// Entity désigne un type d'enregistrement manipulable depuis la CLI
+type Entity string
+
+const (
+	EntityUser        Entity = "user"
+	EntityDailyMenu   Entity = "menu"
+	EntityMeal        Entity = "meal"
+	EntityMealItem    Entity = "item"
+	EntityMeasurement Entity = "measurement"
+)
+
+// ParseEntity convertit le nom saisi par l'utilisateur en Entity
+func ParseEntity(name string) (Entity, error) {
+	switch e := Entity(name); e {
+	case EntityUser, EntityDailyMenu, EntityMeal, EntityMeasurement:
+		return e, nil
+	}
+	return "", i18n.Errorf("entité inconnue : %q (user, menu, meal ou measurement)", name)
+}
+
+// TrashEntry est un élément de la corbeille. Les enregistrements supprimés en
+// cascade avec leur parent n'y figurent pas : ils sont restaurés avec lui.
+type TrashEntry struct {
+	Entity    Entity
+	ID        uint
+	Label     string
+	DeletedAt time.Time
+}
+
+// DeleteEntity place un enregistrement et ses dépendances dans la corbeille
+func DeleteEntity(entity Entity, id uint) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return softDelete(tx, entity, id, time.Now().Truncate(time.Microsecond))
+	})
+	if err != nil {
+		return err
+	}
+	pushUndo(i18n.Sprintf("suppression de %s %d", entity, id), func(tx *gorm.DB) error {
+		return restore(tx, entity, id)
+	})
+	return nil
+}
+
+// RestoreEntity sort de la corbeille un enregistrement et les dépendances
+// supprimées en même temps que lui
+func RestoreEntity(entity Entity, id uint) error {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return restore(tx, entity, id)
+	})
+	if err != nil {
+		return err
+	}
+	pushUndo(i18n.Sprintf("restauration de %s %d", entity, id), func(tx *gorm.DB) error {
+		return softDelete(tx, entity, id, time.Now().Truncate(time.Microsecond))
+	})
+	return nil
+}
+
+// cascadeStep désigne les lignes d'une entité touchées par une suppression ou une restauration
+type cascadeStep struct {
+	entity Entity
+	query  *gorm.DB
+}
+
+// softDelete marque l'enregistrement et ses descendants avec le même horodatage
+// deleted_at, qui sert ensuite à les restaurer ensemble
+func softDelete(tx *gorm.DB, entity Entity, id uint, now time.Time) error {
+	model, err := entityModel(entity)
+	if err != nil {
+		return err
+	}
+	if err := tx.First(model, id).Error; err != nil {
+		return i18n.Errorf("%s %d introuvable : %w", entity, id, err)
+	}
+
+	// Les descendants sont marqués du plus profond au plus proche : une fois
+	// un niveau supprimé, les sous-requêtes ne le voient plus
+	var steps []cascadeStep
+	switch entity {
+	case EntityUser:
+		menus := tx.Model(&models.DailyMenu{}).Select("id").Where("user_id = ?", id)
+		meals := tx.Model(&models.Meal{}).Select("id").Where("daily_menu_id IN (?)", menus)
+		steps = []cascadeStep{
+			{EntityMealItem, tx.Model(&models.MealItem{}).Where("meal_id IN (?)", meals)},
+			{EntityMeal, tx.Model(&models.Meal{}).Where("daily_menu_id IN (?)", menus)},
+			{EntityDailyMenu, tx.Model(&models.DailyMenu{}).Where("user_id = ?", id)},
+			{EntityMeasurement, tx.Model(&models.Measurement{}).Where("user_id = ?", id)},
+		}
+	case EntityDailyMenu:
+		meals := tx.Model(&models.Meal{}).Select("id").Where("daily_menu_id = ?", id)
+		steps = []cascadeStep{
+			{EntityMealItem, tx.Model(&models.MealItem{}).Where("meal_id IN (?)", meals)},
+			{EntityMeal, tx.Model(&models.Meal{}).Where("daily_menu_id = ?", id)},
+		}
+	case EntityMeal:
+		steps = []cascadeStep{
+			{EntityMealItem, tx.Model(&models.MealItem{}).Where("meal_id = ?", id)},
+		}
+	}
+	steps = append(steps, cascadeStep{entity, tx.Model(model).Where("id = ?", id)})
+
+	if err := applyCascade(tx, steps, models.AuditDelete, now); err != nil {
+		return i18n.Errorf("erreur lors de la suppression de %s %d : %w", entity, id, err)
+	}
+	return nil
+}
+
+func restore(tx *gorm.DB, entity Entity, id uint) error {
+	deletedAt, err := deletedAtOf(tx, entity, id)
+	if err != nil {
+		return err
+	}
+	if err := checkParentActive(tx, entity, id); err != nil {
+		return err
+	}
+
+	model, _ := entityModel(entity)
+	u := tx.Unscoped().Session(&gorm.Session{})
+	steps := []cascadeStep{{entity, u.Model(model).Where("id = ?", id)}}
+	switch entity {
+	case EntityUser:
+		menus := u.Model(&models.DailyMenu{}).Select("id").Where("user_id = ?", id)
+		meals := u.Model(&models.Meal{}).Select("id").Where("daily_menu_id IN (?)", menus)
+		steps = append(steps,
+			cascadeStep{EntityDailyMenu, u.Model(&models.DailyMenu{}).Where("user_id = ? AND deleted_at = ?", id, deletedAt)},
+			cascadeStep{EntityMeal, u.Model(&models.Meal{}).Where("daily_menu_id IN (?) AND deleted_at = ?", menus, deletedAt)},
+			cascadeStep{EntityMealItem, u.Model(&models.MealItem{}).Where("meal_id IN (?) AND deleted_at = ?", meals, deletedAt)},
+			cascadeStep{EntityMeasurement, u.Model(&models.Measurement{}).Where("user_id = ? AND deleted_at = ?", id, deletedAt)},
+		)
+	case EntityDailyMenu:
+		meals := u.Model(&models.Meal{}).Select("id").Where("daily_menu_id = ?", id)
+		steps = append(steps,
+			cascadeStep{EntityMeal, u.Model(&models.Meal{}).Where("daily_menu_id = ? AND deleted_at = ?", id, deletedAt)},
+			cascadeStep{EntityMealItem, u.Model(&models.MealItem{}).Where("meal_id IN (?) AND deleted_at = ?", meals, deletedAt)},
+		)
+	case EntityMeal:
+		steps = append(steps,
+			cascadeStep{EntityMealItem, u.Model(&models.MealItem{}).Where("meal_id = ? AND deleted_at = ?", id, deletedAt)},
+		)
+	}
+
+	if err := applyCascade(tx, steps, models.AuditRestore, nil); err != nil {
+		return i18n.Errorf("erreur lors de la restauration de %s %d : %w", entity, id, err)
+	}
+	return nil
+}
+
+// applyCascade écrit deletedAt sur les lignes de chaque étape, en journalisant chacune d'elles
+func applyCascade(tx *gorm.DB, steps []cascadeStep, action models.AuditAction, deletedAt any) error {
+	for _, step := range steps {
+		var ids []uint
+		if err := step.query.Session(&gorm.Session{}).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		befores := make(map[uint]map[string]any, len(ids))
+		for _, id := range ids {
+			before, err := snapshot(tx, step.entity, id)
+			if err != nil {
+				return err
+			}
+			befores[id] = before
+		}
+
+		if err := step.query.Session(&gorm.Session{}).Update("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := recordChange(tx, action, step.entity, id, befores[id]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func deletedAtOf(tx *gorm.DB, entity Entity, id uint) (time.Time, error) {
+	model, err := entityModel(entity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var deletedAt gorm.DeletedAt
+	if err := tx.Unscoped().Model(model).Select("deleted_at").Where("id = ?", id).Scan(&deletedAt).Error; err != nil {
+		return time.Time{}, i18n.Errorf("erreur lors de la récupération de %s %d : %w", entity, id, err)
+	}
+	if !deletedAt.Valid {
+		return time.Time{}, i18n.Errorf("%s %d n'est pas dans la corbeille", entity, id)
+	}
+	return deletedAt.Time, nil
+}
+
+// checkParentActive refuse de restaurer un enregistrement dont le parent est
+// toujours dans la corbeille : il resterait invisible
+func checkParentActive(tx *gorm.DB, entity Entity, id uint) error {
+	var parent Entity
+	var parentID *uint
+	switch entity {
+	case EntityDailyMenu:
+		var menu models.DailyMenu
+		if err := tx.Unscoped().First(&menu, id).Error; err != nil {
+			return err
+		}
+		parent, parentID = EntityUser, &menu.UserID
+	case EntityMeal:
+		var meal models.Meal
+		if err := tx.Unscoped().First(&meal, id).Error; err != nil {
+			return err
+		}
+		parent, parentID = EntityDailyMenu, meal.DailyMenuID
+	case EntityMeasurement:
+		var measurement models.Measurement
+		if err := tx.Unscoped().First(&measurement, id).Error; err != nil {
+			return err
+		}
+		parent, parentID = EntityUser, &measurement.UserID
+	}
+	if parentID == nil {
+		return nil
+	}
+
+	if _, err := deletedAtOf(tx, parent, *parentID); err == nil {
+		return i18n.Errorf("%s %d est dans la corbeille, restaurez-le d'abord", parent, *parentID)
+	}
+	return nil
+}
+
+func entityModel(entity Entity) (any, error) {
+	switch entity {
+	case EntityUser:
+		return &models.User{}, nil
+	case EntityDailyMenu:
+		return &models.DailyMenu{}, nil
+	case EntityMeal:
+		return &models.Meal{}, nil
+	case EntityMealItem:
+		return &models.MealItem{}, nil
+	case EntityMeasurement:
+		return &models.Measurement{}, nil
+	}
+	return nil, i18n.Errorf("entité inconnue : %q", entity)
+}
+
+// GetTrash liste la corbeille, les suppressions les plus récentes en premier
+func GetTrash() ([]TrashEntry, error) {
+	var entries []TrashEntry
+	u := db.DB.Unscoped().Session(&gorm.Session{})
+
+	var users []models.User
+	if err := u.Where("deleted_at IS NOT NULL").Find(&users).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture de la corbeille : %w", err)
+	}
+	deletedUsers := make(map[uint]time.Time)
+	for _, user := range users {
+		deletedUsers[user.ID] = user.DeletedAt.Time
+		entries = append(entries, TrashEntry{EntityUser, user.ID,
+			i18n.Sprintf("%s %s", user.FirstName, user.LastName), user.DeletedAt.Time})
+	}
+
+	var menus []models.DailyMenu
+	if err := u.Preload("User", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("deleted_at IS NOT NULL").Find(&menus).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture de la corbeille : %w", err)
+	}
+	deletedMenus := make(map[uint]time.Time)
+	for _, menu := range menus {
+		deletedMenus[menu.ID] = menu.DeletedAt.Time
+		if ts, ok := deletedUsers[menu.UserID]; ok && ts.Equal(menu.DeletedAt.Time) {
+			continue
+		}
+		entries = append(entries, TrashEntry{EntityDailyMenu, menu.ID,
+			i18n.Sprintf("%s %s - %s", menu.User.FirstName, menu.User.LastName, i18n.Date(menu.Date)), menu.DeletedAt.Time})
+	}
+
+	var meals []models.Meal
+	if err := u.Where("deleted_at IS NOT NULL").Find(&meals).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture de la corbeille : %w", err)
+	}
+	for _, meal := range meals {
+		if meal.DailyMenuID != nil {
+			if ts, ok := deletedMenus[*meal.DailyMenuID]; ok && ts.Equal(meal.DeletedAt.Time) {
+				continue
+			}
+		}
+		entries = append(entries, TrashEntry{EntityMeal, meal.ID,
+			i18n.Sprintf("%s (%s)", meal.Description, meal.Type), meal.DeletedAt.Time})
+	}
+
+	var measurements []models.Measurement
+	if err := u.Where("deleted_at IS NOT NULL").Find(&measurements).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la lecture de la corbeille : %w", err)
+	}
+	for _, m := range measurements {
+		if ts, ok := deletedUsers[m.UserID]; ok && ts.Equal(m.DeletedAt.Time) {
+			continue
+		}
+		entries = append(entries, TrashEntry{EntityMeasurement, m.ID,
+			i18n.Sprintf("%.1f kg le %s", m.Weight, i18n.Date(m.Date)), m.DeletedAt.Time})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// ListTrash affiche le contenu de la corbeille
+func ListTrash() error {
+	entries, err := GetTrash()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		i18n.Println("La corbeille est vide.")
+		return nil
+	}
+
+	i18n.Println("🗑️ Corbeille :")
+	for _, e := range entries {
+		i18n.Printf("- %s %d : %s (supprimé le %s)\n", e.Entity, e.ID, e.Label, i18n.DateTime(e.DeletedAt))
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package fdc
+
+import (
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+	"github.com/lsoulet/gofit/textchart"
+)
+
+// DefaultTrendWidth est la largeur par défaut des sparklines, en caractères
+const DefaultTrendWidth = 30
+
+// Séries de mesures d'une tendance, à côté des TrackedNutrients
+const (
+	TrendWeight  = "weight"
+	TrendBodyFat = "body_fat"
+	TrendBMI     = "bmi"
+)
+
+// TrendSeries est l'évolution d'un apport, un point par jour de la période,
+// ou d'une mesure, un point par mesure. Un jour sans menu vaut NaN.
+type TrendSeries struct {
+	// Name est le nom de la série : un des TrackedNutrients ou TrendWeight,
+	// TrendBodyFat et TrendBMI
+	Name   string
+	Label  string
+	Unit   string
+	Dates  []time.Time
+	Values []float64
+	// Target est l'objectif journalier ; 0 sans objectif
+	Target float64
+}
+
+// TrendStats résume les points d'une série, sans les jours sans menu
+type TrendStats struct {
+	Count   int
+	Min     float64
+	Max     float64
+	Average float64
+	First   float64
+	Last    float64
+}
+
+// Stats renvoie le résumé des points de la série
+func (s TrendSeries) Stats() TrendStats {
+	var st TrendStats
+	var sum float64
+	for _, v := range s.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if st.Count == 0 {
+			st.Min, st.Max, st.First = v, v, v
+		}
+		st.Min, st.Max, st.Last = math.Min(st.Min, v), math.Max(st.Max, v), v
+		sum += v
+		st.Count++
+	}
+	if st.Count > 0 {
+		st.Average = sum / float64(st.Count)
+	}
+	return st
+}
+
+// label renvoie le nom affiché de la série avec son unité
+func (s TrendSeries) label() string {
+	return i18n.Sprintf("%s (%s)", s.Label, s.Unit)
+}
+
+// Trend contient l'évolution des apports et des mesures d'un utilisateur
+type Trend struct {
+	User models.User
+	From time.Time
+	// To est exclu de la période
+	To        time.Time
+	Nutrients []TrendSeries
+	Body      []TrendSeries
+}
+
+// Series renvoie toutes les séries, apports puis mesures
+func (t Trend) Series() []TrendSeries {
+	return append(append([]TrendSeries{}, t.Nutrients...), t.Body...)
+}
+
+// Find renvoie la série name
+func (t Trend) Find(name string) (TrendSeries, bool) {
+	for _, s := range t.Series() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return TrendSeries{}, false
+}
+
+// TrendNames liste les noms des séries d'une tendance, dans l'ordre d'affichage
+func TrendNames() []string {
+	var names []string
+	for _, n := range TrackedNutrients {
+		names = append(names, string(n))
+	}
+	return append(names, TrendWeight, TrendBodyFat, TrendBMI)
+}
+
+// GetTrend calcule l'évolution des apports de l'utilisateur userID, jour par
+// jour de from à to (exclu), et de ses mesures sur la même période
+func GetTrend(userID uint, from, to time.Time) (Trend, error) {
+	user, err := GetUser(userID)
+	if err != nil {
+		return Trend{}, err
+	}
+	trend := Trend{User: user, From: from, To: to}
+	days, err := GetNutritionalReport(ReportFilter{UserID: user.ID, From: from, To: to})
+	if err != nil {
+		return trend, err
+	}
+	trend.Nutrients = nutrientTrends(days, from, to, Targets(user))
+	trend.Body = bodyTrends(user, from, to)
+	return trend, nil
+}
+
+// GetWeightTrend renvoie l'évolution du poids de l'utilisateur userID de from
+// à to (exclu), avec l'utilisateur chargé sans ses menus : seules ses mesures
+// de la période et sa dernière mesure, qui fixe ses objectifs, sont lues
+func GetWeightTrend(userID uint, from, to time.Time) (models.User, TrendSeries, error) {
+	var user models.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		return user, TrendSeries{}, i18n.Errorf("erreur lors de la récupération de l'utilisateur : %w", err)
+	}
+	loc := user.Location()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	if err := db.DB.Where("user_id = ? AND date >= ? AND date < ?", user.ID, start, end).Order("date").
+		Find(&user.Measurements).Error; err != nil {
+		return user, TrendSeries{}, i18n.Errorf("erreur lors de la récupération des mesures : %w", err)
+	}
+	var latest []models.Measurement
+	if err := db.DB.Where("user_id = ?", user.ID).Order("date DESC").Limit(1).Find(&latest).Error; err != nil {
+		return user, TrendSeries{}, i18n.Errorf("erreur lors de la récupération des mesures : %w", err)
+	}
+	if n := len(user.Measurements); len(latest) > 0 && (n == 0 || user.Measurements[n-1].ID != latest[0].ID) {
+		user.Measurements = append(user.Measurements, latest[0])
+	}
+	return user, bodyTrends(user, from, to)[0], nil
+}
+
+// bodyTrends renvoie l'évolution du poids, de la masse grasse et de l'IMC
+// d'après les mesures de user de from à to (exclu)
+func bodyTrends(user models.User, from, to time.Time) []TrendSeries {
+	weight := TrendSeries{Name: TrendWeight, Label: i18n.T("Poids"), Unit: "kg"}
+	bodyFat := TrendSeries{Name: TrendBodyFat, Label: i18n.T("Masse grasse"), Unit: "%"}
+	bmi := TrendSeries{Name: TrendBMI, Label: i18n.T("IMC"), Unit: "kg/m²"}
+	for _, m := range user.Measurements {
+		day := models.CalendarDay(m.Date.In(user.Location()))
+		if day.Before(from) || !day.Before(to) {
+			continue
+		}
+		for _, s := range []*TrendSeries{&weight, &bodyFat, &bmi} {
+			s.Dates = append(s.Dates, day)
+		}
+		weight.Values = append(weight.Values, m.Weight)
+		bmi.Values = append(bmi.Values, m.BMI)
+		// Sans tours de taille et de cou, la masse grasse n'est pas calculée
+		fat := m.BodyFat
+		if fat <= 0 {
+			fat = math.NaN()
+		}
+		bodyFat.Values = append(bodyFat.Values, fat)
+	}
+	return []TrendSeries{weight, bodyFat, bmi}
+}
+
+// nutrientTrends renvoie une série par apport suivi, un point par jour de from
+// à to (exclu) : l'apport du menu du jour dans days, NaN sans menu
+func nutrientTrends(days []DailyTotals, from, to time.Time, target Nutrients) []TrendSeries {
+	byDay := map[time.Time]Nutrients{}
+	for _, d := range days {
+		byDay[models.CalendarDay(d.Date)] = d.Nutrients
+	}
+	var series []TrendSeries
+	for _, n := range TrackedNutrients {
+		s := TrendSeries{Name: string(n), Label: n.Label(), Unit: n.Unit(), Target: target.Get(n)}
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			v := math.NaN()
+			if totals, ok := byDay[day]; ok {
+				v = totals.Get(n)
+			}
+			s.Dates = append(s.Dates, day)
+			s.Values = append(s.Values, v)
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// PrintTrend affiche les sparklines des apports et des mesures, sur width
+// caractères au plus, puis les barres de la série bars si elle est donnée
+func PrintTrend(t Trend, width int, bars string) {
+	i18n.Printf("\n📈 Tendances de %s %s du %s au %s :\n", t.User.FirstName, t.User.LastName,
+		i18n.Date(t.From), i18n.Date(t.To.AddDate(0, 0, -1)))
+	printNutrientTrends(t.Nutrients, width)
+
+	if len(t.Body) == 0 || t.Body[0].Stats().Count == 0 {
+		i18n.Println("\nAucune mesure sur la période.")
+	} else {
+		i18n.Printf("\n⚖️  %d mesure(s) :\n", t.Body[0].Stats().Count)
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{i18n.T("Mesure"), i18n.T("Tendance"), i18n.T("Première"), i18n.T("Dernière"), i18n.T("Écart")})
+		table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT,
+			tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+		table.SetAutoWrapText(false)
+		for _, s := range t.Body {
+			st := s.Stats()
+			if st.Count == 0 {
+				table.Append([]string{s.label(), "", "-", "-", "-"})
+				continue
+			}
+			table.Append([]string{s.label(), textchart.Sparkline(s.Values, width),
+				i18n.Float(st.First, 1), i18n.Float(st.Last, 1), signedFloat(st.Last-st.First, 1)})
+		}
+		table.Render()
+	}
+
+	if s, ok := t.Find(bars); ok {
+		printTrendBars(s, width)
+	}
+}
+
+// PrintReportTrends affiche, pour chaque utilisateur du rapport ayant au
+// moins deux jours, les sparklines de ses apports de son premier à son
+// dernier jour enregistré
+func PrintReportTrends(report []DailyTotals, width int) {
+	for _, summary := range SummarizeByUser(report) {
+		var days []DailyTotals
+		for _, day := range report {
+			if day.User.ID == summary.User.ID {
+				days = append(days, day)
+			}
+		}
+		if len(days) < 2 {
+			continue
+		}
+		from := models.CalendarDay(days[0].Date)
+		to := models.CalendarDay(days[len(days)-1].Date).AddDate(0, 0, 1)
+		i18n.Printf("\n📈 Tendances de %s %s du %s au %s :\n", summary.User.FirstName, summary.User.LastName,
+			i18n.Date(from), i18n.Date(to.AddDate(0, 0, -1)))
+		printNutrientTrends(nutrientTrends(days, from, to, Targets(summary.User)), width)
+	}
+}
+
+// printNutrientTrends affiche le tableau des sparklines des apports
+func printNutrientTrends(series []TrendSeries, width int) {
+	if len(series) == 0 || series[0].Stats().Count == 0 {
+		i18n.Println("Aucun menu journalier enregistré.")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{i18n.T("Apport"), i18n.T("Tendance"), i18n.T("Min"), i18n.T("Moyenne"),
+		i18n.T("Max"), i18n.T("Objectif")})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	table.SetAutoWrapText(false)
+	for _, s := range series {
+		st := s.Stats()
+		target := "-"
+		if s.Target > 0 {
+			target = i18n.Float(s.Target, 0)
+		}
+		table.Append([]string{s.label(), textchart.Sparkline(s.Values, width),
+			i18n.Float(st.Min, 0), i18n.Float(st.Average, 0), i18n.Float(st.Max, 0), target})
+	}
+	table.Render()
+	i18n.Println("Les espaces de la tendance sont des jours sans menu.")
+}
+
+// printTrendBars affiche une barre de width caractères par point de s, les
+// jours sans menu omis, à l'échelle du plus grand point ou de l'objectif. Les
+// barres des mesures, qui varient peu, partent d'une base sous le plus petit
+// point pour que ses écarts restent visibles.
+func printTrendBars(s TrendSeries, width int) {
+	st := s.Stats()
+	if st.Count == 0 {
+		return
+	}
+	base, scale := trendBarScale(s)
+	header := []string{i18n.T("Date"), s.label(), i18n.T("Valeur")}
+	if s.Target > 0 {
+		header = append(header, i18n.T("Objectif (%)"))
+	}
+	i18n.Printf("\n📊 %s :\n", s.label())
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_DEFAULT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	table.SetAutoWrapText(false)
+	for i, v := range s.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		row := []string{i18n.Date(s.Dates[i]), textchart.Bar(v-base, scale, width), i18n.Float(v, 1)}
+		if s.Target > 0 {
+			row = append(row, i18n.Float(v/s.Target*100, 0))
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// trendBarScale renvoie la base et l'échelle des barres de s : une barre
+// montre v-base sur scale. La base des mesures est sous leur plus petit point
+// d'autant que leur étendue, d'une unité si tous les points sont égaux.
+func trendBarScale(s TrendSeries) (base, scale float64) {
+	st := s.Stats()
+	if !slices.Contains(TrackedNutrients, Nutrient(s.Name)) {
+		span := st.Max - st.Min
+		if span == 0 {
+			span = 1
+		}
+		base = math.Max(st.Min-span, 0)
+	}
+	return base, math.Max(st.Max, s.Target) - base
+}
+
+// ParseTrendSeries lit le nom d'une série de tendance
+func ParseTrendSeries(s string) (string, error) {
+	name := strings.ReplaceAll(strings.ToLower(s), "-", "_")
+	for _, n := range TrendNames() {
+		if n == name {
+			return n, nil
+		}
+	}
+	return "", i18n.Errorf("%q : utilisez %s", s, strings.Join(TrendNames(), ", "))
+}
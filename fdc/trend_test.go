@@ -0,0 +1,31 @@
+package fdc
+
+import (
+	"testing"
+)
+
+func TestTrendBarScale(t *testing.T) {
+	tests := []struct {
+		name        string
+		series      TrendSeries
+		base, scale float64
+	}{
+		{"apport depuis zéro", TrendSeries{Name: string(NutrientCalories), Values: []float64{1500, 2000}}, 0, 2000},
+		{"apport et objectif", TrendSeries{Name: string(NutrientCalories), Values: []float64{1500}, Target: 2500}, 0, 2500},
+		{"mesures", TrendSeries{Name: TrendWeight, Values: []float64{60, 62}}, 58, 4},
+		{"mesures égales", TrendSeries{Name: TrendWeight, Values: []float64{60, 60}}, 59, 1},
+		{"une seule mesure", TrendSeries{Name: TrendBMI, Values: []float64{22}}, 21, 1},
+		{"base bornée à zéro", TrendSeries{Name: TrendBodyFat, Values: []float64{0.5}}, 0, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, scale := trendBarScale(tt.series)
+			if base != tt.base || scale != tt.scale {
+				t.Errorf("trendBarScale = (%v, %v), attendu (%v, %v)", base, scale, tt.base, tt.scale)
+			}
+			if scale <= 0 {
+				t.Errorf("échelle nulle : les barres seraient vides")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package fdc
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// undoEntry décrit comment annuler une action de la session en cours
+type undoEntry struct {
+	description string
+	revert      func(tx *gorm.DB) error
+}
+
+// undoStack contient les actions annulables de la session, la plus récente en dernier
+var undoStack []undoEntry
+
+// ErrNothingToUndo est renvoyée par Undo quand aucune action n'est annulable
+var ErrNothingToUndo = i18n.New("aucune action à annuler")
+
+func pushUndo(description string, revert func(tx *gorm.DB) error) {
+	undoStack = append(undoStack, undoEntry{description: description, revert: revert})
+}
+
+// clearUndo vide la pile après une action qui ne peut pas être annulée, pour
+// ne pas rejouer une annulation sur des données qu'elle a modifiées
+func clearUndo() {
+	undoStack = nil
+}
+
+// Undo annule la dernière action de la session et renvoie sa description
+func Undo() (string, error) {
+	if len(undoStack) == 0 {
+		return "", ErrNothingToUndo
+	}
+	last := undoStack[len(undoStack)-1]
+
+	if err := db.DB.Transaction(last.revert); err != nil {
+		return "", i18n.Errorf("erreur lors de l'annulation de « %s » : %w", last.description, err)
+	}
+	undoStack = undoStack[:len(undoStack)-1]
+	return last.description, nil
+}
@@ -1,26 +1,92 @@
 package fdc
 
 import (
-	"fmt"
+	"time"
+
+	"gorm.io/gorm"
 
 	"github.com/lsoulet/gofit/db"
+	"github.com/lsoulet/gofit/i18n"
 	"github.com/lsoulet/gofit/models"
 )
 
-func CreateUser(firstName, lastName string, age int, gender models.Gender, goal models.Goal) error {
+// GetUsers récupère la liste des utilisateurs avec leurs menus, repas et mesures
+func GetUsers() ([]models.User, error) {
+	var users []models.User
+	if err := preloadUser(db.DB).Find(&users).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des utilisateurs : %w", err)
+	}
+	return users, nil
+}
+
+// GetUserIDs renvoie les identifiants des utilisateurs, par ordre croissant,
+// sans charger leurs menus ni leurs mesures
+func GetUserIDs() ([]uint, error) {
+	var ids []uint
+	if err := db.DB.Model(&models.User{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, i18n.Errorf("erreur lors de la récupération des utilisateurs : %w", err)
+	}
+	return ids, nil
+}
+
+// GetUser récupère un utilisateur avec ses menus, repas et mesures, afin que
+// les méthodes de models.User travaillent sur les données persistées
+func GetUser(id uint) (models.User, error) {
+	var user models.User
+	if err := preloadUser(db.DB).First(&user, id).Error; err != nil {
+		return user, i18n.Errorf("erreur lors de la récupération de l'utilisateur : %w", err)
+	}
+	return user, nil
+}
+
+func preloadUser(tx *gorm.DB) *gorm.DB {
+	return tx.
+		Preload("DailyMenus", func(tx *gorm.DB) *gorm.DB { return tx.Order("date") }).
+		Preload("DailyMenus.Meals.Items").
+		Preload("Measurements", func(tx *gorm.DB) *gorm.DB { return tx.Order("date") })
+}
+
+// CreateUser crée un utilisateur et le renvoie avec son identifiant ; un
+// fuseau horaire vide vaut models.DefaultTimeZone
+func CreateUser(firstName, lastName string, age int, gender models.Gender, goal models.Goal, timeZone string) (models.User, error) {
+	if timeZone == "" {
+		timeZone = models.DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return models.User{}, i18n.Errorf("fuseau horaire inconnu : %q", timeZone)
+	}
 	user := models.User{
 		FirstName: firstName,
 		LastName:  lastName,
 		Age:       age,
 		Gender:    gender,
 		Goal:      goal,
+		TimeZone:  timeZone,
 	}
 
-	if err := db.DB.Create(&user).Error; err != nil {
-		return fmt.Errorf("erreur lors de la création de l'utilisateur : %w", err)
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return i18n.Errorf("erreur lors de la création de l'utilisateur : %w", err)
+		}
+		return recordChange(tx, models.AuditCreate, EntityUser, user.ID, nil)
+	})
+	if err != nil {
+		return user, err
 	}
 
-	return nil
+	pushUndo(i18n.Sprintf("création de l'utilisateur %s %s", firstName, lastName), func(tx *gorm.DB) error {
+		return auditedHardDelete(tx, EntityUser, user.ID)
+	})
+	return user, nil
+}
+
+// SetUserTimeZone change le fuseau horaire (nom IANA, ex. "America/Montreal")
+// utilisé pour ranger les repas de l'utilisateur par jour
+func SetUserTimeZone(userID uint, timeZone string) error {
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" {
+		return i18n.Errorf("fuseau horaire inconnu : %q", timeZone)
+	}
+	return UpdateEntity(EntityUser, userID, map[string]any{"time_zone": timeZone})
 }
 
 func ListUsers() error {
@@ -30,13 +96,13 @@ func ListUsers() error {
 	}
 
 	if len(users) == 0 {
-		fmt.Println("Aucun utilisateur enregistré.")
+		i18n.Println("Aucun utilisateur enregistré.")
 		return nil
 	}
 
-	fmt.Println("👤 Utilisateurs enregistrés :")
-	for i, user := range users {
-		fmt.Printf("%d. %s %s (%d ans)\n", i+1, user.FirstName, user.LastName, user.Age)
+	i18n.Println("👤 Utilisateurs enregistrés :")
+	for _, user := range users {
+		i18n.Printf("%d. %s %s (%d ans, %s)\n", user.ID, user.FirstName, user.LastName, user.Age, user.TimeZone)
 	}
 	return nil
 }
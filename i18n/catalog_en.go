@@ -0,0 +1,701 @@
+package i18n
+
+// catalogEN traduit les messages en anglais. Les messages qui ne contiennent
+// que des verbes de format (« %s %d ») n'ont pas besoin d'entrée.
+var catalogEN = map[string]string{
+	// Aide et boucle interactive
+	"\nCodes de sortie : 0 succès, 1 erreur, 2 utilisation incorrecte": "\nExit codes: 0 success, 1 error, 2 incorrect usage",
+	"\nCommandes :": "\nCommands:",
+	"\nOptions communes : --output table|json|csv|yaml (format de sortie, table par défaut), --lang fr|en (langue)": "\nCommon options: --output table|json|csv|yaml (output format, table by default), --lang fr|en (language)",
+	"        gofit repl    (mode interactif, aussi lancé sans argument)":                                            "        gofit repl    (interactive mode, also started without arguments)",
+	"Commande incomplète.":                                "Incomplete command.",
+	"Commande inconnue : %s\n":                            "Unknown command: %s\n",
+	"Commande inconnue : %s (gofit help pour la liste)\n": "Unknown command: %s (gofit help for the list)\n",
+	"Erreur : %v\n":                                       "Error: %v\n",
+	"Opération annulée.":                                  "Operation cancelled.",
+	"Tapez « help » pour la liste des commandes, « exit » pour quitter.": "Type \"help\" for the list of commands, \"exit\" to quit.",
+	"Toutes les commandes doivent commencer par 'gofit'":                 "All commands must start with 'gofit'",
+	"Usage : gofit %s\n":                                            "Usage: gofit %s\n",
+	"Usage : gofit <commande> [options]":                            "Usage: gofit <command> [options]",
+	"Usage : gofit <commande> [options] (gofit help pour la liste)": "Usage: gofit <command> [options] (gofit help for the list)",
+	"commande inconnue : %s":                                        "unknown command: %s",
+	"erreur lors de la lecture : %w":                                "error while reading: %w",
+	"guillemet %c non fermé":                                        "unclosed quote %c",
+	"trop d'arguments":                                              "too many arguments",
+
+	// Résumés des commandes
+	"Afficher l'aide générale ou celle d'une commande": "Show general help or help for a command",
+	"Afficher la corbeille":                            "Show the trash",
+	"Afficher ou enregistrer la langue de l'interface": "Show or save the interface language",
+	"Annuler la dernière modification de la boucle interactive (disponible seulement dans celle-ci)":                         "Undo the last change of the interactive loop (available only there)",
+	"Consulter le journal des modifications":                                                                                 "Browse the change log",
+	"Créer un nouveau repas type":                                                                                            "Create a new template meal",
+	"Créer un nouvel utilisateur":                                                                                            "Create a new user",
+	"Définir le fuseau horaire d'un utilisateur":                                                                             "Set a user's time zone",
+	"Déplacer un menu journalier à une autre date":                                                                           "Move a daily menu to another date",
+	"Enregistrer une mesure (poids, taille, tours pour la masse grasse)":                                                     "Record a measurement (weight, height, circumferences for body fat)",
+	"Fusionner les menus en double (même utilisateur, même jour)":                                                            "Merge duplicate menus (same user, same day)",
+	"Lister les enregistrements avec leur identifiant":                                                                       "List records with their identifier",
+	"Modifier un champ d'un repas (type, description, calories, proteines, glucides, lipides)":                               "Edit a field of a meal (type, description, calories, proteins, carbohydrates, lipids)",
+	"Modifier un champ d'un utilisateur (prenom, nom, age, genre, objectif, fuseau, calories, proteines, glucides, lipides)": "Edit a field of a user (firstname, lastname, age, gender, goal, timezone, calories, proteins, carbohydrates, lipids)",
+	"Modifier un champ d'une mesure (poids, taille, massegrasse)":                                                            "Edit a field of a measurement (weight, height, bodyfat)",
+	"Placer un menu journalier dans la corbeille, avec ses repas":                                                            "Move a daily menu to the trash, with its meals",
+	"Placer un repas dans la corbeille, avec ses aliments":                                                                   "Move a meal to the trash, with its foods",
+	"Placer un utilisateur dans la corbeille, avec ses menus et mesures":                                                     "Move a user to the trash, with their menus and measurements",
+	"Placer une mesure dans la corbeille":                                                                                    "Move a measurement to the trash",
+	"Rechercher un aliment dans la base FDC":                                                                                 "Search for a food in the FDC database",
+	"Restaurer un enregistrement de la corbeille":                                                                            "Restore a record from the trash",
+	"Voir les détails nutritionnels d'un aliment":                                                                            "Show the nutrition details of a food",
+
+	// Arguments des commandes
+	"--first <prénom> --last <nom> --age <âge> --gender <male|female> --goal <weight_loss|maintenance|muscle_gain> [--timezone <fuseau>]": "--first <first name> --last <last name> --age <age> --gender <male|female> --goal <weight_loss|maintenance|muscle_gain> [--timezone <zone>]",
+	"--type <breakfast|lunch|dinner|snack> --description <texte>":                                                                         "--type <breakfast|lunch|dinner|snack> --description <text>",
+	"<id utilisateur> <fuseau IANA, ex. Europe/Paris>":                                                                                    "<user id> <IANA zone, e.g. America/New_York>",
+	"<id> <JJ/MM/AAAA>":     "<id> <MM/DD/YYYY>",
+	"<id> <champ> <valeur>": "<id> <field> <value>",
+	"<nom de l'aliment>":    "<food name>",
+	"[commande]":            "[command]",
+	"[fr|en]":               "[fr|en]",
+	"[user|menu|meal|item|measurement [id]] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA]": "[user|menu|meal|item|measurement [id]] [--from MM/DD/YYYY] [--to MM/DD/YYYY]",
+
+	// Options
+	"date de début JJ/MM/AAAA":                           "start date MM/DD/YYYY",
+	"date de fin JJ/MM/AAAA (incluse)":                   "end date MM/DD/YYYY (inclusive)",
+	"description du repas":                               "meal description",
+	"format de sortie : table, json, csv ou yaml":        "output format: table, json, csv or yaml",
+	"fuseau horaire IANA (défaut Europe/Paris)":          "IANA time zone (default Europe/Paris)",
+	"genre : male (homme) ou female (femme)":             "gender: male or female",
+	"identifiant FDC de l'aliment":                       "FDC identifier of the food",
+	"identifiant du repas type":                          "template meal identifier",
+	"langue de l'interface : fr ou en":                   "interface language: fr or en",
+	"nom":                                                "last name",
+	"objectif : weight_loss, maintenance ou muscle_gain": "goal: weight_loss, maintenance or muscle_gain",
+	"prénom": "first name",
+	"quantité en grammes ; défaut : dernière quantité consommée": "quantity in grams; default: last quantity eaten",
+	"type de repas (breakfast, lunch, dinner, snack)":            "meal type (breakfast, lunch, dinner, snack)",
+	"âge": "age",
+
+	// Erreurs d'utilisation et de saisie
+	"%q : utilisez %s, %s ou %s":                              "%q: use %s, %s or %s",
+	"%q : utilisez %s, %s, %s ou %s":                          "%q: use %s, %s, %s or %s",
+	"%q : utilisez homme ou femme":                            "%q: use male or female",
+	"%q n'est pas un entier positif":                          "%q is not a positive integer",
+	"%q n'est pas un nombre positif":                          "%q is not a positive number",
+	"champ inconnu : %s (champs : %s)":                        "unknown field: %s (fields: %s)",
+	"date invalide : %q (format JJ/MM/AAAA)":                  "invalid date: %q (format MM/DD/YYYY)",
+	"entité inconnue : %q":                                    "unknown entity: %q",
+	"entité inconnue : %q (user, menu, meal ou measurement)":  "unknown entity: %q (user, menu, meal or measurement)",
+	"fdcId invalide : %s":                                     "invalid fdcId: %s",
+	"format de date invalide : utilisez le format JJ/MM/AAAA": "invalid date format: use MM/DD/YYYY",
+	"format inconnu : %q (table, json, csv ou yaml)":          "unknown format: %q (table, json, csv or yaml)",
+	"fuseau horaire inconnu : %q":                             "unknown time zone: %q",
+	"identifiant invalide : %s":                               "invalid identifier: %s",
+	"l'identifiant FDC est attendu":                           "the FDC identifier is required",
+	"l'identifiant de l'utilisateur est attendu":              "the user identifier is required",
+	"l'option --age doit être un nombre positif":              "--age must be a positive number",
+	"l'option --description est obligatoire":                  "--description is required",
+	"l'option --grams doit être un nombre positif":            "--grams must be a positive number",
+	"l'option --meal est obligatoire":                         "--meal is required",
+	"la valeur ne peut pas être vide":                         "the value cannot be empty",
+	"langue inconnue : %q (fr ou en)":                         "unknown language: %q (fr or en)",
+	"le nom de l'aliment est attendu":                         "the food name is required",
+	"le repas %d n'est pas un repas type":                     "meal %d is not a template meal",
+	"les options --first et --last sont obligatoires":         "--first and --last are required",
+	"liste inconnue : %s":                                     "unknown list: %s",
+	"précisez ce qu'il faut lister":                           "specify what to list",
+	"quantité invalide : %q n'est pas un nombre positif":      "invalid quantity: %q is not a positive number",
+	"un identifiant d'utilisateur et un fuseau sont attendus": "a user identifier and a time zone are required",
+	"un identifiant de menu et une date sont attendus":        "a menu identifier and a date are required",
+	"un identifiant est attendu":                              "an identifier is required",
+	"un identifiant, un champ et une valeur sont attendus":    "an identifier, a field and a value are required",
+	"une entité et un identifiant sont attendus":              "an entity and an identifier are required",
+	"valeur invalide : %s":                                    "invalid value: %s",
+	"valeur invalide pour %s : %w":                            "invalid value for %s: %w",
+
+	// Wizards
+	"  %s : %s\n":                  "  %s: %s\n",
+	"\nAliment sélectionné : %s\n": "\nSelected food: %s\n",
+	"\nRécapitulatif :":            "\nSummary:",
+	"(« %s » pour revenir en arrière, « %s » pour abandonner)\n": "(\"%s\" to go back, \"%s\" to abort)\n",
+	"Ajout d'un aliment à un repas":                              "Adding a food to a meal",
+	"Ajout d'un repas type à un menu journalier":                 "Adding a template meal to a daily menu",
+	"Aliment":                 "Food",
+	"Choisissez l'objectif :": "Choose the goal:",
+	"Choisissez l'utilisateur pour ce menu :":                    "Choose the user for this menu:",
+	"Choisissez le genre :":                                      "Choose the gender:",
+	"Choisissez le menu auquel ajouter ce repas :":               "Choose the menu to add this meal to:",
+	"Choisissez le repas auquel ajouter cet aliment :":           "Choose the meal to add this food to:",
+	"Choisissez un repas type :":                                 "Choose a template meal:",
+	"Choix invalide. Veuillez entrer un nombre entre 1 et %d.\n": "Invalid choice. Please enter a number between 1 and %d.\n",
+	"Confirmer ? (o/n, « < » pour corriger) > ":                  "Confirm? (y/n, \"<\" to correct) > ",
+	"Création d'un menu journalier":                              "Creating a daily menu",
+	"Création d'un repas type":                                   "Creating a template meal",
+	"Création d'un utilisateur":                                  "Creating a user",
+	"Date du menu (JJ/MM/AAAA) :":                                "Menu date (MM/DD/YYYY):",
+	"Description du repas (ex : \"Déjeuner du mardi\") :":        "Meal description (e.g. \"Tuesday lunch\"):",
+	"Femme":            "Female",
+	"Fuseau horaire":   "Time zone",
+	"Fuseau horaire :": "Time zone:",
+	"Genre":            "Gender",
+	"Homme":            "Male",
+	"Identifiant FDC de l'aliment (voir 'gofit search') :": "FDC identifier of the food (see 'gofit search'):",
+	"Maintien":                  "Maintenance",
+	"Nom de l'utilisateur :":    "User's last name:",
+	"Objectif":                  "Goal",
+	"Perte de poids":            "Weight loss",
+	"Prise de masse":            "Muscle gain",
+	"Prénom":                    "First name",
+	"Prénom de l'utilisateur :": "User's first name:",
+	"Quantité (g)":              "Quantity (g)",
+	"Quantité en grammes :":     "Quantity in grams:",
+	"Quel type de repas souhaitez-vous ajouter ?": "Which type of meal do you want to add?",
+	"Répondez o ou n.":                            "Answer y or n.",
+	"Une réponse est attendue.":                   "An answer is required.",
+	"Vous êtes déjà à la première question.":      "You are already at the first question.",
+	"aucun choix possible pour « %s »":            "no choice available for \"%s\"",
+	"opération annulée":                           "operation cancelled",
+	"Âge":                                         "Age",
+	"Âge de l'utilisateur :":                      "User's age:",
+
+	// Tableaux, listes et graphiques
+	"\n📊 Rapport nutritionnel :":                                    "\n📊 Nutrition report:",
+	"\n🕓 Historique des modifications :":                            "\n🕓 Change history:",
+	"   %d. %s (%s) | %.1f kcal\n":                                  "   %d. %s (%s) | %.1f kcal\n",
+	"%.1f kg le %s":                                                 "%.1f kg on %s",
+	"%d. %s %s (%d ans, %s)\n":                                      "%d. %s %s (%d years old, %s)\n",
+	"%d. %s (%s) | %.1f kcal | P: %.1f g | G: %.1f g | L: %.1f g\n": "%d. %s (%s) | %.1f kcal | P: %.1f g | C: %.1f g | F: %.1f g\n",
+	"%d. %s | %.1f kg | %.0f cm | IMC %.2f | MG %.0f %%\n":          "%d. %s | %.1f kg | %.0f cm | BMI %.2f | BF %.0f %%\n",
+	"- %s %d : %s (supprimé le %s)\n":                               "- %s %d: %s (deleted on %s)\n",
+	"Acteur":                                                        "Actor",
+	"Action":                                                        "Action",
+	"Aucun menu en double.":                                         "No duplicate menus.",
+	"Aucun menu journalier enregistré.":                             "No daily menu recorded.",
+	"Aucun menu journalier enregistré. Veuillez d'abord créer un menu avec 'gofit addmenu'.": "No daily menu recorded. Please create a menu first with 'gofit addmenu'.",
+	"Aucun repas enregistré.": "No meal recorded.",
+	"Aucun repas n'a été créé. Veuillez d'abord créer un repas avec 'gofit newmeal'.":    "No meal has been created. Please create a meal first with 'gofit newmeal'.",
+	"Aucun repas type enregistré. Veuillez d'abord créer un repas avec 'gofit newmeal'.": "No template meal recorded. Please create a meal first with 'gofit newmeal'.",
+	"Aucun résultat trouvé.":                                               "No results found.",
+	"Aucun utilisateur enregistré.":                                        "No user recorded.",
+	"Aucun utilisateur enregistré. Veuillez d'abord créer un utilisateur.": "No user recorded. Please create a user first.",
+	"Aucune mesure enregistrée pour %s %s.\n":                              "No measurement recorded for %s %s.\n",
+	"Aucune modification enregistrée.":                                     "No change recorded.",
+	"Calories":                                                             "Calories",
+	"Calories : %.2f kcal\n":                                               "Calories: %.2f kcal\n",
+	"Changements":                                                          "Changes",
+	"Commande":                                                             "Command",
+	"Date":                                                                 "Date",
+	"Description":                                                          "Description",
+	"Détails nutritionnels :":                                              "Nutrition details:",
+	"Entité":                                                               "Entity",
+	"Glucides":                                                             "Carbohydrates",
+	"Glucides (g)":                                                         "Carbohydrates (g)",
+	"Glucides : %.2f g\n":                                                  "Carbohydrates: %.2f g\n",
+	"Génération du bilan nutritionnel journalier...": "Generating the daily nutrition summary...",
+	"IMC":                               "BMI",
+	"La corbeille est vide.":            "The trash is empty.",
+	"Lipides":                           "Fat",
+	"Lipides (g)":                       "Fat (g)",
+	"Lipides : %.2f g\n":                "Fat: %.2f g\n",
+	"Masse grasse (%)":                  "Body fat (%)",
+	"Menu":                              "Menu",
+	"Nom":                               "Last name",
+	"Nom : %s\n":                        "Name: %s\n",
+	"Protéines":                         "Proteins",
+	"Protéines (g)":                     "Proteins (g)",
+	"Protéines : %.2f g\n":              "Proteins: %.2f g\n",
+	"Quantité : %.2f g\n":               "Quantity: %.2f g\n",
+	"Repas":                             "Meal",
+	"Résultats trouvés :":               "Results found:",
+	"Type":                              "Type",
+	"Utilisateur":                       "User",
+	"Valeur":                            "Value",
+	"Valeur (kcal / g)":                 "Value (kcal / g)",
+	"créé":                              "created",
+	"mis à la corbeille":                "moved to trash",
+	"restauré":                          "restored",
+	"supprimé définitivement":           "permanently deleted",
+	"🍽️ Repas enregistrés :":            "🍽️ Recorded meals:",
+	"👤 Utilisateurs enregistrés :":      "👤 Recorded users:",
+	"📅 Menus journaliers enregistrés :": "📅 Recorded daily menus:",
+	"📏 Mesures de %s %s :\n":            "📏 Measurements of %s %s:\n",
+	"🗑️ Corbeille :":                    "🗑️ Trash:",
+
+	// Confirmations
+	"\n✅ %.0fg de %s ajoutés au repas '%s'\n":             "\n✅ %.0fg of %s added to meal '%s'\n",
+	"\n✅ Repas '%s' (%s) ajouté au menu de %s %s le %s\n": "\n✅ Meal '%s' (%s) added to the menu of %s %s on %s\n",
+	"\n✅ Utilisateur %s %s créé avec succès ! (id %d)\n":  "\n✅ User %s %s created successfully! (id %d)\n",
+	"ℹ️ %s %s a déjà un menu le %s (id %d)\n":             "ℹ️ %s %s already has a menu on %s (id %d)\n",
+	"↩️ Annulé : %s":                                      "↩️ Undone: %s",
+	"♻️ %s %d restauré":                                   "♻️ %s %d restored",
+	"⚠️ %d jour(s) ont plusieurs menus pour un même utilisateur, lancez 'gofit mergemenus' pour les fusionner": "⚠️ %d day(s) have several menus for the same user, run 'gofit mergemenus' to merge them",
+	"✅ %d menu(s) en double fusionné(s)\n":                                     "✅ %d duplicate menu(s) merged\n",
+	"✅ %s %d modifié : %s = %s":                                                "✅ %s %d updated: %s = %s",
+	"✅ Fuseau horaire de l'utilisateur %d : %s":                                "✅ Time zone of user %d: %s",
+	"✅ Langue de l'interface : %s\n":                                           "✅ Interface language: %s\n",
+	"✅ Menu %d déplacé au %s":                                                  "✅ Menu %d moved to %s",
+	"✅ Menu journalier créé pour %s %s le %s (id %d)\n":                        "✅ Daily menu created for %s %s on %s (id %d)\n",
+	"✅ Mesure %d enregistrée : %.1f kg, IMC %.2f\n":                            "✅ Measurement %d recorded: %.1f kg, BMI %.2f\n",
+	"✅ Repas '%s' (%s) ajouté au menu %d (id %d)\n":                            "✅ Meal '%s' (%s) added to menu %d (id %d)\n",
+	"✅ Utilisateur %s %s créé avec succès ! (id %d)\n":                         "✅ User %s %s created successfully! (id %d)\n",
+	"✔ Aliment '%s' (%.0f g) ajouté au repas %d\n":                             "✔ Food '%s' (%.0f g) added to meal %d\n",
+	"✔ Repas '%s' (%s) ajouté avec succès !\n":                                 "✔ Meal '%s' (%s) added successfully!\n",
+	"✔ Repas '%s' (%s) ajouté avec succès ! (id %d)\n":                         "✔ Meal '%s' (%s) added successfully! (id %d)\n",
+	"🗑️ %s %d placé dans la corbeille (gofit restore %s %d pour le récupérer)": "🗑️ %s %d moved to the trash (gofit restore %s %d to recover it)",
+
+	// Actions annulables et journal d'audit
+	"ajout de %.0f g de %s au repas %d":  "adding %.0f g of %s to meal %d",
+	"ajout de %d aliment(s) au repas %d": "adding %d food(s) to meal %d",
+	"ajout de la mesure %d":              "adding measurement %d",
+	"ajout du repas '%s' au menu %d":     "adding meal '%s' to menu %d",
+	"création de l'utilisateur %s %s":    "creating user %s %s",
+	"création du menu du %s":             "creating the menu of %s",
+	"création du repas '%s'":             "creating meal '%s'",
+	"modification de %s %d (%s)":         "editing %s %d (%s)",
+	"restauration de %s %d":              "restoring %s %d",
+	"suppression de %s %d":               "deleting %s %d",
+	"aucune action à annuler":            "nothing to undo",
+
+	// Erreurs métier
+	"%s %d est dans la corbeille, restaurez-le d'abord":                  "%s %d is in the trash, restore it first",
+	"%s %d introuvable : %w":                                             "%s %d not found: %w",
+	"%s %d n'est pas dans la corbeille":                                  "%s %d is not in the trash",
+	"aucun repas trouvé pour cette date":                                 "no meals found for this date",
+	"aucune mesure disponible pour générer le graphique":                 "no measurements available to generate the chart",
+	"ce menu contient déjà un repas de type %s":                          "this menu already contains a %s meal",
+	"ce résultat ne peut pas être écrit en CSV":                          "this result cannot be written as CSV",
+	"genre invalide : 'male' ou 'female' attendu":                        "invalid gender: must be 'male' or 'female'",
+	"index de repas invalide":                                            "invalid meal index",
+	"la taille doit être supérieure à 0":                                 "height must be greater than 0",
+	"le poids, la taille et l'âge doivent être supérieurs à 0":           "weight, height and age must be greater than 0",
+	"le tour de hanches doit être positif pour une femme":                "hip circumference must be positive for females",
+	"le tour de taille doit être supérieur au tour de cou pour un homme": "waist circumference must be greater than neck circumference for males",
+	"les tours de taille et de cou doivent être positifs":                "waist and neck measurements must be positive",
+	"menu %d introuvable : %w":                                           "menu %d not found: %w",
+	"mesure %d introuvable : %w":                                         "measurement %d not found: %w",
+	"taille + hanches - cou doit être positif pour une femme":            "the sum of waist + hip - neck circumference must be positive for females",
+	"un menu existe déjà pour cet utilisateur à cette date":              "a menu already exists for this user on this date",
+
+	// Erreurs techniques
+	"Erreur lors de l'initialisation de la base de données : %v\n":   "Error while initializing the database: %v\n",
+	"erreur lors de la connexion à la base de données : %w":          "error while connecting to the database: %w",
+	"erreur lors de l'ajout de l'aliment au repas : %w":              "error while adding the food to the meal: %w",
+	"erreur lors de l'annulation de « %s » : %w":                     "error while undoing \"%s\": %w",
+	"erreur lors de l'enregistrement de l'aliment : %w":              "error while saving the food: %w",
+	"erreur lors de l'enregistrement de la mesure : %w":              "error while saving the measurement: %w",
+	"erreur lors de l'écriture du journal d'audit : %w":              "error while writing the audit log: %w",
+	"erreur lors de l'écriture de la configuration : %w":             "error while writing the configuration: %w",
+	"erreur lors de la copie du repas %d : %w":                       "error while copying meal %d: %w",
+	"erreur lors de la création de l'index des menus : %w":           "error while creating the menu index: %w",
+	"erreur lors de la création de l'utilisateur : %w":               "error while creating the user: %w",
+	"erreur lors de la création de la fonction d'audit : %w":         "error while creating the audit function: %w",
+	"erreur lors de la création du menu : %w":                        "error while creating the menu: %w",
+	"erreur lors de la création du repas : %w":                       "error while creating the meal: %w",
+	"erreur lors de la création du trigger d'audit : %w":             "error while creating the audit trigger: %w",
+	"erreur lors de la fusion des menus : %w":                        "error while merging menus: %w",
+	"erreur lors de la génération du rapport : %w":                   "error while generating the report: %w",
+	"erreur lors de la lecture de %s %d : %w":                        "error while reading %s %d: %w",
+	"erreur lors de la lecture de dailymenu_meals : %w":              "error while reading dailymenu_meals: %w",
+	"erreur lors de la lecture de la configuration : %w":             "error while reading the configuration: %w",
+	"erreur lors de la lecture de la corbeille : %w":                 "error while reading the trash: %w",
+	"erreur lors de la lecture du journal d'audit : %w":              "error while reading the audit log: %w",
+	"erreur lors de la migration de user_dailymenus : %w":            "error while migrating user_dailymenus: %w",
+	"erreur lors de la mise à jour du repas : %w":                    "error while updating the meal: %w",
+	"erreur lors de la modification de %s %d : %w":                   "error while editing %s %d: %w",
+	"erreur lors de la recherche : %w":                               "error while searching: %w",
+	"erreur lors de la recherche des menus en double : %w":           "error while looking for duplicate menus: %w",
+	"erreur lors de la restauration de %s %d : %w":                   "error while restoring %s %d: %w",
+	"erreur lors de la récupération : %w":                            "error while fetching: %w",
+	"erreur lors de la récupération de %s %d : %w":                   "error while fetching %s %d: %w",
+	"erreur lors de la récupération de l'aliment : %w":               "error while fetching the food: %w",
+	"erreur lors de la récupération de l'utilisateur : %w":           "error while fetching the user: %w",
+	"erreur lors de la récupération des détails de l'aliment : %w":   "error while fetching the food details: %w",
+	"erreur lors de la récupération des menus : %w":                  "error while fetching menus: %w",
+	"erreur lors de la récupération des repas : %w":                  "error while fetching meals: %w",
+	"erreur lors de la récupération des repas du menu %d : %w":       "error while fetching the meals of menu %d: %w",
+	"erreur lors de la récupération des utilisateurs : %w":           "error while fetching users: %w",
+	"erreur lors de la récupération du menu : %w":                    "error while fetching the menu: %w",
+	"erreur lors de la récupération du repas %d : %w":                "error while fetching meal %d: %w",
+	"erreur lors de la récupération du repas : %w":                   "error while fetching the meal: %w",
+	"erreur lors de la récupération du repas source : %w":            "error while fetching the source meal: %w",
+	"erreur lors de la sauvegarde du repas : %w":                     "error while saving the meal: %w",
+	"erreur lors de la suppression de %s %d : %w":                    "error while deleting %s %d: %w",
+	"erreur lors de la suppression de dailymenu_meals : %w":          "error while dropping dailymenu_meals: %w",
+	"erreur lors de la suppression de l'ancien index des menus : %w": "error while dropping the old menu index: %w",
+	"erreur lors de la suppression de user_dailymenus : %w":          "error while dropping user_dailymenus: %w",
+	"erreur lors de la suppression du menu %d : %w":                  "error while deleting menu %d: %w",
+	"erreur lors de la vérification des repas existants : %w":        "error while checking existing meals: %w",
+	"erreur lors du nettoyage des repas types : %w":                  "error while cleaning up template meals: %w",
+	"erreur lors du rattachement du repas %d : %w":                   "error while attaching meal %d: %w",
+	"erreur lors du transfert des repas du menu %d : %w":             "error while moving the meals of menu %d: %w",
+
+	// Complétion du shell et pages de manuel
+	"Écrire le script de complétion du shell":                        "Write the shell completion script",
+	"Générer les pages de manuel (gofit.1 et une page par commande)": "Generate the man pages (gofit.1 and one page per command)",
+	"[--dir <dossier>]":                                  "[--dir <directory>]",
+	"dossier où écrire les pages":                        "directory to write the pages to",
+	"un shell est attendu : bash, zsh ou fish":           "a shell is required: bash, zsh or fish",
+	"shell inconnu : %s (bash, zsh ou fish)":             "unknown shell: %s (bash, zsh or fish)",
+	"erreur lors de l'écriture des pages de manuel : %w": "error while writing the man pages: %w",
+	"✅ %d pages de manuel écrites dans %s\n":             "✅ %d man pages written to %s\n",
+	"Manuel de gofit":                                    "gofit manual",
+	"NOM":                                                "NAME",
+	"SYNOPSIS":                                           "SYNOPSIS",
+	"DESCRIPTION":                                        "DESCRIPTION",
+	"COMMANDES":                                          "COMMANDS",
+	"OPTIONS":                                            "OPTIONS",
+	"OPTIONS COMMUNES":                                   "COMMON OPTIONS",
+	"CODES DE SORTIE":                                    "EXIT STATUS",
+	"ENVIRONNEMENT":                                      "ENVIRONMENT",
+	"VOIR AUSSI":                                         "SEE ALSO",
+	"assistant de suivi nutritionnel":                    "nutrition tracking assistant",
+	"<commande> [options]":                               "<command> [options]",
+	"GoFit suit l'alimentation quotidienne à partir de la base FoodData Central. Chaque commande s'exécute puis se termine ; sans argument ou avec repl, gofit lance la boucle interactive.": "GoFit tracks daily food intake using the FoodData Central database. Each command runs then exits; without arguments or with repl, gofit starts the interactive loop.",
+	"clé de l'API FoodData Central":                                      "FoodData Central API key",
+	"langue de l'interface, prioritaire sur le fichier de configuration": "interface language, takes precedence over the configuration file",
+	"chemin du fichier de configuration":                                 "path of the configuration file",
+	"auteur des modifications inscrit dans le journal d'audit":           "author of changes recorded in the audit log",
+	"succès":              "success",
+	"erreur d'exécution":  "execution error",
+	"arguments invalides": "invalid arguments",
+
+	// Utilisateur et jour courants
+	"Créer le menu journalier d'un utilisateur (jour courant par défaut)":                            "Create a user's daily menu (current day by default)",
+	"[--user <utilisateur>] [--date JJ/MM/AAAA]":                                                     "[--user <user>] [--date MM/DD/YYYY]",
+	"Ajouter un repas type à un menu journalier (menu du jour courant par défaut)":                   "Add a template meal to a daily menu (current day's menu by default)",
+	"[--menu <id menu>] --meal <id repas type>":                                                      "[--menu <menu id>] --meal <template meal id>",
+	"identifiant du menu journalier ; défaut : menu de l'utilisateur et du jour courants":            "daily menu identifier; default: menu of the current user and day",
+	"Ajouter un aliment à un repas (dernier repas du jour courant par défaut)":                       "Add a food to a meal (latest meal of the current day by default)",
+	"[--meal <id repas> | --type <type>] {--fdc <fdcId> | <aliment récent>} [--grams <quantité>]":    "[--meal <meal id> | --type <type>] {--fdc <fdcId> | <recent food>} [--grams <quantity>]",
+	"identifiant du repas ; défaut : dernier repas de l'utilisateur et du jour courants":             "meal identifier; default: latest meal of the current user and day",
+	"type du repas du jour courant (breakfast, lunch, dinner, snack)":                                "type of the current day's meal (breakfast, lunch, dinner, snack)",
+	"Générer un rapport nutritionnel (utilisateur et jour courants, ou tous les repas)":              "Generate a nutrition report (current user and day, or all meals)",
+	"tous les utilisateurs et tous les jours, même avec un utilisateur courant":                      "all users and all days, even with a current user",
+	"[--user <utilisateur>] <poids kg> <taille cm> [tour de taille] [tour de cou] [tour de hanches]": "[--user <user>] <weight kg> <height cm> [waist] [neck] [hip]",
+	"un poids et une taille sont attendus":                                                           "a weight and a height are required",
+	"<users|menus|meals|measurements> [utilisateur]":                                                 "<users|menus|meals|measurements> [user]",
+	"Choisir l'utilisateur et le jour courants, utilisés par défaut par les autres commandes":        "Choose the current user and day, used by default by the other commands",
+	"[<utilisateur>] [--date JJ/MM/AAAA | --today] [--clear]":                                        "[<user>] [--date MM/DD/YYYY | --today] [--clear]",
+	"jour courant JJ/MM/AAAA":                                                        "current day MM/DD/YYYY",
+	"revenir à la date du jour":                                                      "go back to today's date",
+	"oublier l'utilisateur et le jour courants":                                      "forget the current user and day",
+	"un seul utilisateur est attendu":                                                "a single user is expected",
+	"✅ Plus d'utilisateur ni de jour courants":                                       "✅ No current user or day anymore",
+	"Aucun utilisateur courant (gofit use <utilisateur>).":                           "No current user (gofit use <user>).",
+	"👤 Utilisateur courant : %s %s (id %d)\n":                                        "👤 Current user: %s %s (id %d)\n",
+	"📅 Jour courant : aujourd'hui (%s)\n":                                            "📅 Current day: today (%s)\n",
+	"📅 Jour courant : %s\n":                                                          "📅 Current day: %s\n",
+	"utilisateur inconnu : %s":                                                       "unknown user: %s",
+	"plusieurs utilisateurs correspondent à %q : précisez l'identifiant":             "several users match %q: give the identifier",
+	"utilisateur courant introuvable (gofit use --clear pour l'oublier) : %w":        "current user not found (gofit use --clear to forget it): %w",
+	"l'option %s est obligatoire sans utilisateur courant (gofit use <utilisateur>)": "the %s option is required without a current user (gofit use <user>)",
+	"aucun repas le %s : ajoutez-en un avec « gofit addmeal » ou précisez --meal":    "no meal on %s: add one with \"gofit addmeal\" or give --meal",
+	"aucun repas %s le %s : ajoutez-en un avec « gofit addmeal » ou précisez --meal": "no %s meal on %s: add one with \"gofit addmeal\" or give --meal",
+	"utilisateur (identifiant ou nom) ; défaut : utilisateur courant":                "user (identifier or name); default: current user",
+	"jour JJ/MM/AAAA ; défaut : jour courant":                                        "day MM/DD/YYYY; default: current day",
+
+	// Saisie en langage naturel
+	"Noter des aliments en langage naturel dans le menu du jour courant":                                "Log foods in natural language in the current day's menu",
+	"[--type <breakfast|lunch|dinner|snack>] <aliments, ex. 150g chicken breast, 1 cup rice for lunch>": "[--type <breakfast|lunch|dinner|snack>] <foods, e.g. 150g chicken breast, 1 cup rice for lunch>",
+	"type de repas, s'il n'est pas dans la saisie (breakfast, lunch, dinner, snack)":                    "meal type, when the text does not give it (breakfast, lunch, dinner, snack)",
+	"Saisie rapide": "Quick log",
+	"Qu'avez-vous mangé ? (ex. 150g chicken breast, 1 cup rice for lunch)": "What did you eat? (e.g. 150g chicken breast, 1 cup rice for lunch)",
+	"Quelques précisions":                "A few details",
+	"Quel aliment correspond à « %s » ?": "Which food matches \"%s\"?",
+	"Quantité en grammes pour « %s » :":  "Quantity in grams for \"%s\":",
+	"Quantité (g) de « %s »":             "Quantity (g) of \"%s\"",
+	"les aliments sont attendus, ex. « 150g chicken breast, 1 cup rice for lunch »": "foods are required, e.g. \"150g chicken breast, 1 cup rice for lunch\"",
+	"aucun aliment trouvé pour « %s »":                                              "no food found for \"%s\"",
+	"« %s » est ambigu (%s...) : précisez le nom ou utilisez gofit addfood --fdc":   "\"%s\" is ambiguous (%s...): give a more precise name or use gofit addfood --fdc",
+	"poids inconnu pour « %s » : précisez-le en grammes":                            "unknown weight for \"%s\": give it in grams",
+	"✔ %.0f g de %s (%.0f kcal)\n":                                                  "✔ %.0f g of %s (%.0f kcal)\n",
+	"✅ %d aliment(s) ajouté(s) au repas %s du %s (id %d) : %.0f kcal\n":             "✅ %d food(s) added to the %s meal of %s (id %d): %.0f kcal\n",
+	"aucun aliment dans la saisie":                                                  "no food in the text",
+	"aliment manquant dans « %s »":                                                  "missing food in \"%s\"",
+	"quantité invalide dans « %s »":                                                 "invalid quantity in \"%s\"",
+
+	// Aliments récents et favoris
+	"Lister les aliments consommés récemment par l'utilisateur courant": "List the foods recently eaten by the current user",
+	"Lister les aliments favoris de l'utilisateur courant":              "List the current user's favorite foods",
+	"Mettre un aliment dans les favoris de l'utilisateur courant":       "Add a food to the current user's favorites",
+	"Retirer un aliment des favoris de l'utilisateur courant":           "Remove a food from the current user's favorites",
+	"[--limit <n>]":                                         "[--limit <n>]",
+	"<fdcId | aliment récent>":                              "<fdcId | recent food>",
+	"<fdcId | aliment favori>":                              "<fdcId | favorite food>",
+	"nombre maximal d'aliments":                             "maximum number of foods",
+	"l'option --limit doit être un entier positif":          "the --limit option must be a positive integer",
+	"🕘 Aliments récents de %s %s :\n":                       "🕘 Recent foods of %s %s:\n",
+	"⭐ Aliments favoris de %s %s :\n":                       "⭐ Favorite foods of %s %s:\n",
+	"Aucun aliment consommé par %s %s.\n":                   "No food eaten by %s %s.\n",
+	"Aucun favori pour %s %s (gofit star <fdcId>).\n":       "No favorites for %s %s (gofit star <fdcId>).\n",
+	"%s %s (fdcId %d) | %.0f g | %d fois | dernier le %s\n": "%s %s (fdcId %d) | %.0f g | %d times | last on %s\n",
+	"%s %s (fdcId %d) | jamais consommé\n":                  "%s %s (fdcId %d) | never eaten\n",
+	"⭐ %s (fdcId %d) ajouté aux favoris de %s %s\n":         "⭐ %s (fdcId %d) added to the favorites of %s %s\n",
+	"✅ Aliment %d retiré des favoris de %s %s\n":            "✅ Food %d removed from the favorites of %s %s\n",
+	"aucun aliment récent ou favori ne correspond à « %s » : utilisez l'identifiant FDC (gofit search)": "no recent or favorite food matches \"%s\": use the FDC id (gofit search)",
+	"« %s » est ambigu (%s...) : précisez le nom ou l'identifiant FDC":                                  "\"%s\" is ambiguous (%s...): give a more precise name or the FDC id",
+	"l'option --grams est attendue pour un aliment jamais consommé":                                     "the --grams option is required for a food never eaten",
+	"Choisissez un aliment récent ou favori :":                                                          "Choose a recent or favorite food:",
+	"Aliment récent ou favori":                                                                          "Recent or favorite food",
+	"Autre aliment (identifiant FDC)":                                                                   "Other food (FDC id)",
+	"ajout de %s aux favoris":                                                                           "adding %s to the favorites",
+	"retrait de l'aliment %d des favoris":                                                               "removing food %d from the favorites",
+	"erreur lors de la lecture du cache des aliments : %w":                                              "error reading the food cache: %w",
+	"erreur lors de la mise en cache de l'aliment : %w":                                                 "error caching the food: %w",
+	"erreur lors de la création du cache des aliments : %w":                                             "error creating the food cache: %w",
+	"erreur lors de la récupération des aliments consommés : %w":                                        "error retrieving the foods eaten: %w",
+	"erreur lors de la récupération des favoris : %w":                                                   "error retrieving the favorites: %w",
+	"erreur lors de l'ajout aux favoris : %w":                                                           "error adding to the favorites: %w",
+	"erreur lors du retrait des favoris : %w":                                                           "error removing from the favorites: %w",
+	"l'aliment %d n'est pas dans les favoris":                                                           "food %d is not in the favorites",
+
+	// Copie de menus et de repas
+	"Copier tous les repas d'un menu journalier (jour courant par défaut) vers un autre jour ou une période": "Copy all the meals of a daily menu (current day by default) to another day or a period",
+	"Copier un repas (dernier repas du jour courant par défaut) vers un autre jour ou une période":           "Copy a meal (latest meal of the current day by default) to another day or a period",
+	"[<id menu>] --to JJ/MM/AAAA [--until JJ/MM/AAAA] [--scale <facteur>]":                                   "[<menu id>] --to MM/DD/YYYY [--until MM/DD/YYYY] [--scale <factor>]",
+	"[<id repas> | --type <type>] --to JJ/MM/AAAA [--until JJ/MM/AAAA] [--scale <facteur>]":                  "[<meal id> | --type <type>] --to MM/DD/YYYY [--until MM/DD/YYYY] [--scale <factor>]",
+	"type du repas du jour courant à copier (breakfast, lunch, dinner, snack)":                               "type of the current day's meal to copy (breakfast, lunch, dinner, snack)",
+	"jour de destination JJ/MM/AAAA, ou premier jour de la période":                                          "destination day MM/DD/YYYY, or first day of the period",
+	"dernier jour de la période JJ/MM/AAAA (inclus)":                                                         "last day of the period MM/DD/YYYY (inclusive)",
+	"facteur appliqué aux portions, ex. 0.5 ou 1.5":                                                          "factor applied to the portions, e.g. 0.5 or 1.5",
+	"l'option --to est obligatoire":                                                                          "the --to option is required",
+	"l'option --scale doit être un nombre positif":                                                           "the --scale option must be a positive number",
+	"--until doit être postérieur ou égal à --to":                                                            "--until must be on or after --to",
+	"la période ne peut pas dépasser %d jours":                                                               "the period cannot exceed %d days",
+	"un seul menu est attendu":                                                                               "only one menu is expected",
+	"un seul repas est attendu":                                                                              "only one meal is expected",
+	"l'identifiant du menu est attendu sans utilisateur courant (gofit use <utilisateur>)":                   "the menu id is required without a current user (gofit use <user>)",
+	"l'identifiant du repas est attendu sans utilisateur courant (gofit use <utilisateur>)":                  "the meal id is required without a current user (gofit use <user>)",
+	"aucun menu le %s pour %s %s":                                                                            "no menu on %s for %s %s",
+	"aucun menu trouvé pour cette date":                                                                      "no menu found for this date",
+	"erreur lors de la copie du menu : %w":                                                                   "error copying the menu: %w",
+	"erreur lors de la copie du repas : %w":                                                                  "error copying the meal: %w",
+	"✔ %s (%s) copié dans le menu %d (id %d) : %.0f kcal\n":                                                  "✔ %s (%s) copied to menu %d (id %d): %.0f kcal\n",
+	"✅ %d repas copié(s) sur %d jour(s)\n":                                                                   "✅ %d meal(s) copied over %d day(s)\n",
+	"copie du menu du %s":                                                                                    "copy of the menu of %s",
+	"copie du repas '%s'":                                                                                    "copy of the meal '%s'",
+	"le menu %d ne contient aucun repas":                                                                     "menu %d contains no meal",
+	"le repas %d est un repas type : précisez l'utilisateur":                                                 "meal %d is a template meal: specify the user",
+	"%s : un repas ne peut pas être copié dans son propre menu":                                              "%s: a meal cannot be copied into its own menu",
+
+	// Filtres et sous-totaux du rapport
+	"premier jour de la période JJ/MM/AAAA":                                 "first day of the period MM/DD/YYYY",
+	"ne compter que les repas de ce type (breakfast, lunch, dinner, snack)": "only count the meals of this type (breakfast, lunch, dinner, snack)",
+	"--date ne peut pas être combinée avec --from ou --to":                  "--date cannot be combined with --from or --to",
+	"--to doit être postérieur ou égal à --from":                            "--to must be on or after --from",
+	"Sous-total":     "Subtotal",
+	"Moyenne (%d j)": "Average (%d d)",
+	"\n📊 Rapport nutritionnel (repas %s) :\n": "\n📊 Nutrition report (%s meals):\n",
+	// Suivi des objectifs
+	"Comparer les apports de l'utilisateur courant à ses objectifs, jour par jour et sur la période": "Compare the current user's intake with their targets, per day and over the period",
+	"[--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--tolerance <%>]":                 "[--user <user>] [--from MM/DD/YYYY] [--to MM/DD/YYYY] [--tolerance <%>]",
+	"premier jour de la période JJ/MM/AAAA (7 jours jusqu'à --to par défaut)":                        "first day of the period MM/DD/YYYY (default: 7 days up to --to)",
+	"dernier jour de la période JJ/MM/AAAA (inclus, jour courant par défaut)":                        "last day of the period MM/DD/YYYY (inclusive, default: current day)",
+	"marge autour de l'objectif, en % (tolerance de la configuration, sinon 10)":                     "band around the target, in % (default: tolerance from the configuration, else 10)",
+	"l'option --tolerance doit être un nombre positif":                                               "--tolerance must be a positive number",
+	"aucun objectif nutritionnel pour %s %s : enregistrez une mesure (gofit addmeasurement)":         "no nutrition targets for %s %s: record a measurement (gofit addmeasurement)",
+	"\n🎯 Suivi des objectifs de %s %s du %s au %s (marge ± %s %%) :\n":                               "\n🎯 Goal adherence for %s %s from %s to %s (band ± %s %%):\n",
+	"\n📈 Bilan sur %d jour(s) enregistré(s) :\n":                                                     "\n📈 Summary over %d logged day(s):\n",
+	"Écart (kcal)":        "Gap (kcal)",
+	"Objectif / jour":     "Target / day",
+	"Apport":              "Nutrient",
+	"Total":               "Total",
+	"Atteint":             "Achieved",
+	"Excédent / déficit":  "Surplus / deficit",
+	"Jours dans la marge": "Days within band",
+	"Série en cours":      "Current streak",
+	"Meilleure série":     "Longest streak",
+	"%d j":                "%d d",
+	// Rapports par semaine et par mois
+	"[--user <utilisateur>] [--date JJ/MM/AAAA | --from JJ/MM/AAAA --to JJ/MM/AAAA] [--meal-type <type>] [--by week|month] [--trend] [--all]": "[--user <user>] [--date MM/DD/YYYY | --from MM/DD/YYYY --to MM/DD/YYYY] [--meal-type <type>] [--by week|month] [--trend] [--all]",
+	"regrouper les jours par semaine (week) ou par mois (month)":                                                                              "group days by week or month",
+	"%q : utilisez %s ou %s":                          "%q: use %s or %s",
+	"Génération du bilan nutritionnel par période...": "Generating the nutrition summary by period...",
+	"erreur lors de la récupération des mesures : %w": "error retrieving measurements: %w",
+	"\n📊 Rapport nutritionnel par semaine :":          "\n📊 Weekly nutrition report:",
+	"\n📊 Rapport nutritionnel par mois :":             "\n📊 Monthly nutrition report:",
+	"Période":                                         "Period",
+	"Jours":                                           "Days",
+	"Total (kcal)":                                    "Total (kcal)",
+	"Moyenne (kcal)":                                  "Average (kcal)",
+	"Min":                                             "Min",
+	"Max":                                             "Max",
+	"Δ moyenne":                                       "Δ average",
+	"P / G / L (g/j)":                                 "P / C / F (g/d)",
+	"Poids moyen":                                     "Average weight",
+	"Δ poids":                                         "Δ weight",
+	"⚖️  %s %s : corrélation entre apport calorique moyen et variation du poids r = %s (%d périodes)\n": "⚖️  %s %s: correlation between average calorie intake and weight change r = %s (%d periods)\n",
+	// Répartition par repas et par aliment
+	"Détailler les apports par type de repas, par repas et par aliment, et classer les aliments qui apportent le plus":                                "Break down intake by meal type, meal and food, and rank the foods that contribute most",
+	"[--user <utilisateur>] [--date JJ/MM/AAAA | --from JJ/MM/AAAA --to JJ/MM/AAAA] [--meal-type <type>] [--rank <nutriment>] [--top <n>] [--detail]": "[--user <user>] [--date MM/DD/YYYY | --from MM/DD/YYYY --to MM/DD/YYYY] [--meal-type <type>] [--rank <nutrient>] [--top <n>] [--detail]",
+	"nutriment du classement : calories, proteins, carbohydrates, lipids, sugars, fiber, saturated_fat, sodium, cholesterol":                          "nutrient to rank foods by: calories, proteins, carbohydrates, lipids, sugars, fiber, saturated_fat, sodium, cholesterol",
+	"nombre d'aliments du classement":                                  "number of foods in the ranking",
+	"détailler chaque repas et ses aliments, même sur plusieurs jours": "break down each meal and its foods, even over several days",
+	"l'option --top doit être un entier positif":                       "--top must be a positive integer",
+	"%q : utilisez %s": "%q: use %s",
+	"erreur lors de la lecture des nutriments des aliments : %w": "error reading food nutrients: %w",
+	"\n🍽️  Répartition par type de repas sur %d jour(s) :\n":     "\n🍽️  Breakdown by meal type over %d day(s):\n",
+	"\n📅 %s : %s kcal\n": "\n📅 %s: %s kcal\n",
+	"Repas / aliment":    "Meal / food",
+	"Quantité":           "Quantity",
+	"\n🏆 Principaux apports en %s (%s %s au total) :\n": "\n🏆 Top contributors of %s (%s %s in total):\n",
+	"Fois": "Times",
+	"Part": "Share",
+	"⚠️ %d aliment(s) sans détails nutritionnels (API FDC injoignable ?) ne sont pas comptés\n": "⚠️ %d food(s) without nutrient details (FDC API unreachable?) are not counted\n",
+	"Sucres":              "Sugars",
+	"Fibres":              "Fiber",
+	"Acides gras saturés": "Saturated fat",
+	"Sodium":              "Sodium",
+	"Cholestérol":         "Cholesterol",
+	// Micronutriments et apports de référence
+	"Comparer les apports en vitamines et minéraux aux apports de référence de l'âge et du sexe de l'utilisateur": "Compare vitamin and mineral intake with the reference intakes for the user's age and sex",
+	"[--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--detail]":                                     "[--user <user>] [--from MM/DD/YYYY] [--to MM/DD/YYYY] [--detail]",
+	"afficher les apports de chaque jour":                              "show the intake of each day",
+	"âge ou sexe inconnu pour %s %s : renseignez-les (gofit edituser)": "unknown age or sex for %s %s: set them (gofit edituser)",
+	"\n💊 Micronutriments de %s %s du %s au %s (%d ans, %s) :\n":        "\n💊 Micronutrients of %s %s from %s to %s (%d years old, %s):\n",
+	"\n📋 Apports moyens sur %d jour(s) enregistré(s) :\n":              "\n📋 Average intake over %d logged day(s):\n",
+	"Nutriment":           "Nutrient",
+	"Apport moyen / jour": "Average / day",
+	"Référence":           "Reference",
+	"Limite":              "Upper limit",
+	"Jours au-delà":       "Days above",
+	"Statut":              "Status",
+	"Références : apports nutritionnels conseillés (RDA) ou apports adéquats (AI) des National Academies ; limite : apport maximal tolérable (UL)": "References: National Academies Recommended Dietary Allowances (RDA) or Adequate Intakes (AI); upper limit: Tolerable Upper Intake Level (UL)",
+	"⚠️ au-delà de la limite": "⚠️ above upper limit",
+	"atteint":                 "met",
+	"insuffisant":             "low",
+	"Vitamine A":              "Vitamin A",
+	"Vitamine C":              "Vitamin C",
+	"Vitamine D":              "Vitamin D",
+	"Vitamine E":              "Vitamin E",
+	"Vitamine K":              "Vitamin K",
+	"Thiamine (B1)":           "Thiamin (B1)",
+	"Riboflavine (B2)":        "Riboflavin (B2)",
+	"Niacine (B3)":            "Niacin (B3)",
+	"Vitamine B6":             "Vitamin B6",
+	"Folates (B9)":            "Folate (B9)",
+	"Vitamine B12":            "Vitamin B12",
+	"Calcium":                 "Calcium",
+	"Fer":                     "Iron",
+	"Magnésium":               "Magnesium",
+	"Phosphore":               "Phosphorus",
+	"Potassium":               "Potassium",
+	"Zinc":                    "Zinc",
+	"Sélénium":                "Selenium",
+	// Export HTML et PDF
+	"Exporter le rapport nutritionnel de l'utilisateur en page HTML autonome ou en PDF, avec le suivi des objectifs et les graphiques": "Export the user's nutrition report as a self-contained HTML page or a PDF, with goal adherence and charts",
+	"[--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--format <html|pdf>] [--tolerance <%>] <fichier>":                   "[--user <user>] [--from MM/DD/YYYY] [--to MM/DD/YYYY] [--format <html|pdf>] [--tolerance <%>] <file>",
+	"premier jour de la période JJ/MM/AAAA (30 jours jusqu'à --to par défaut)":                                                         "first day of the period MM/DD/YYYY (default: 30 days up to --to)",
+	"format du fichier : html ou pdf (déduit de l'extension par défaut)":                                                               "file format: html or pdf (default: from the extension)",
+	"précisez le fichier à écrire":                                           "specify the file to write",
+	"%s : extension inconnue, précisez --format html ou pdf":                 "%s: unknown extension, specify --format html or pdf",
+	"format d'export inconnu : %q (html ou pdf)":                             "unknown export format: %q (html or pdf)",
+	"erreur lors de la création du fichier : %w":                             "error creating the file: %w",
+	"erreur lors de l'écriture du rapport HTML : %w":                         "error writing the HTML report: %w",
+	"erreur lors de l'écriture du rapport PDF : %w":                          "error writing the PDF report: %w",
+	"erreur lors du tracé du graphique des apports : %w":                     "error drawing the intake chart: %w",
+	"erreur lors du tracé du graphique des mesures : %w":                     "error drawing the measurements chart: %w",
+	"✅ Rapport de %s %s du %s au %s exporté dans %s\n":                       "✅ Report of %s %s from %s to %s exported to %s\n",
+	"Rapport nutritionnel de %s %s":                                          "Nutrition report of %s %s",
+	"Du %s au %s, généré le %s":                                              "From %s to %s, generated on %s",
+	"%d ans":                                                                 "%d years old",
+	"objectif %s":                                                            "goal %s",
+	"%s kg, %s cm le %s":                                                     "%s kg, %s cm on %s",
+	"Apports journaliers":                                                    "Daily intake",
+	"Calories (kcal)":                                                        "Calories (kcal)",
+	"Moyenne / jour":                                                         "Average / day",
+	"Suivi des objectifs":                                                    "Goal adherence",
+	"Aucun objectif nutritionnel : enregistrez une mesure pour le calculer.": "No nutrition goal: log a measurement to compute it.",
+	"%d jour(s) enregistré(s), marge de tolérance ± %s %%":                   "%d logged day(s), tolerance band ± %s %%",
+	"Évolution des apports":                                                  "Intake over time",
+	"Évolution de l'IMC et de la masse grasse":                               "BMI and body fat over time",
+	// Graphiques
+	"Tracer les apports, leur répartition ou les mesures de l'utilisateur dans une image PNG ou SVG":                                                                                                                "Plot the user's intake, its split or measurements as a PNG or SVG image",
+	"[--user <utilisateur>] [--kind <nutrition|body|macros|meals|targets>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--view <combined|dual|normalized>] [--width <px>] [--height <px>] [--format <png|svg>] <fichier>": "[--user <user>] [--kind <nutrition|body|macros|meals|targets>] [--from MM/DD/YYYY] [--to MM/DD/YYYY] [--view <combined|dual|normalized>] [--width <px>] [--height <px>] [--format <png|svg>] <file>",
+	"graphique : nutrition (calories et macronutriments), body (IMC et masse grasse), macros (répartition des calories), meals (calories par type de repas) ou targets (apports et besoins)":                        "chart: nutrition (calories and macronutrients), body (BMI and body fat), macros (calorie split), meals (calories per meal type) or targets (intake and needs)",
+	"premier jour tracé JJ/MM/AAAA (tous par défaut, jour courant pour macros et targets)":                                                                                                                          "first plotted day MM/DD/YYYY (default: all, current day for macros and targets)",
+	"dernier jour tracé JJ/MM/AAAA (inclus)": "last plotted day MM/DD/YYYY (inclusive)",
+	"courbes : combined (un seul axe), dual (calories ou IMC sur un second axe) ou normalized (% de la moyenne)": "lines: combined (single axis), dual (calories or BMI on a second axis) or normalized (% of the average)",
+	"largeur de l'image en pixels":                                                                         "image width in pixels",
+	"hauteur de l'image en pixels":                                                                         "image height in pixels",
+	"format de l'image : png ou svg (déduit de l'extension par défaut)":                                    "image format: png or svg (default: from the extension)",
+	"la largeur et la hauteur doivent être des entiers positifs":                                           "width and height must be positive integers",
+	"%s : extension inconnue, précisez --format png ou svg":                                                "%s: unknown extension, specify --format png or svg",
+	"✅ Graphique de %s %s écrit dans %s (%d × %d)\n":                                                       "✅ Chart of %s %s written to %s (%d × %d)\n",
+	"pas assez de données pour tracer le graphique : au moins deux jours ou deux mesures sont nécessaires": "not enough data to draw the chart: at least two days or two measurements are needed",
+	"Macronutriments (g)":           "Macronutrients (g)",
+	"% de la moyenne de la période": "% of the period average",
+	// Répartition des calories, repas et objectifs en graphiques
+	"aucun apport enregistré sur la période : rien à tracer": "no intake logged over the period: nothing to plot",
+	"%q : utilisez %s, %s, %s, %s ou %s":                     "%q: use %s, %s, %s, %s or %s",
+	"%s %s %%":                                               "%s %s %%",
+	"%s : %s / %s %s":                                        "%s: %s / %s %s",
+	"% des besoins journaliers":                              "% of daily needs",
+	// Tendances dans le terminal
+	"Afficher dans le terminal l'évolution des apports et des mesures de l'utilisateur en sparklines et en barres": "Show the user's intake and measurements over time in the terminal as sparklines and bars",
+	"[--user <utilisateur>] [--from JJ/MM/AAAA] [--to JJ/MM/AAAA] [--width <caractères>] [--bars <série>]":         "[--user <user>] [--from MM/DD/YYYY] [--to MM/DD/YYYY] [--width <characters>] [--bars <series>]",
+	"largeur des sparklines et des barres, en caractères":                                                          "width of the sparklines and bars, in characters",
+	"série à détailler en barres : calories, proteins, carbohydrates, lipids, weight, body_fat ou bmi":             "series to detail as bars: calories, proteins, carbohydrates, lipids, weight, body_fat or bmi",
+	"--width doit être un entier positif":                                                                          "--width must be a positive integer",
+	"ajouter les sparklines des apports de chaque utilisateur sur la période":                                      "add sparklines of each user's intake over the period",
+	"\n📈 Tendances de %s %s du %s au %s :\n":                                                                       "\n📈 Trends of %s %s from %s to %s:\n",
+	"\nAucune mesure sur la période.":                                                                              "\nNo measurement over the period.",
+	"\n⚖️  %d mesure(s) :\n":                                                                                       "\n⚖️  %d measurement(s):\n",
+	"\n📊 %s :\n":                                                                                                   "\n📊 %s:\n",
+	"Les espaces de la tendance sont des jours sans menu.":                                                         "Blanks in the trend are days without a menu.",
+	"Poids":        "Weight",
+	"Masse grasse": "Body fat",
+	"Mesure":       "Measurement",
+	"Tendance":     "Trend",
+	"Première":     "First",
+	"Dernière":     "Last",
+	"Écart":        "Change",
+	"Moyenne":      "Average",
+	"Objectif (%)": "Goal (%)",
+	// Tableau de bord plein écran
+	"Ouvrir le tableau de bord plein écran : menu du jour, objectifs, apports restants et tendance du poids": "Open the full-screen dashboard: today's menu, goals, remaining intake and weight trend",
+	"[--user <utilisateur>] [--date JJ/MM/AAAA] [--tolerance <%>]":                                           "[--user <user>] [--date MM/DD/YYYY] [--tolerance <%>]",
+	"le tableau de bord demande un terminal : utilisez gofit report ou gofit trend dans un script":           "the dashboard needs a terminal: use gofit report or gofit trend in a script",
+	"gofit · %s %s · %s":            "gofit · %s %s · %s",
+	" (aujourd'hui)":                " (today)",
+	"Objectifs du jour":             "Today's goals",
+	"reste %s %s":                   "%s %s left",
+	"dépassé de %s %s":              "%s %s over",
+	"Menu du jour":                  "Menu of the day",
+	"Aucun repas ce jour-là.":       "No meal on that day.",
+	"%s g · %s kcal":                "%s g · %s kcal",
+	"%s kcal":                       "%s kcal",
+	"Poids sur %d jours":            "Weight over %d days",
+	"Aucune mesure sur la période.": "No measurement over the period.",
+	"%s kg (%s%s kg depuis le %s)":  "%s kg (%s%s kg since %s)",
+	"←/→ jour  t aujourd'hui  a ajouter  u/U utilisateur  r actualiser  q quitter": "←/→ day  t today  a add  u/U user  r refresh  q quit",
+	"←/→ jour  t aujourd'hui  u/U utilisateur  r actualiser  q quitter":            "←/→ day  t today  u/U user  r refresh  q quit",
+	"Erreur : %v":              "Error: %v",
+	"Aucun autre utilisateur.": "No other user.",
+	"Ajout au menu de %s %s du %s (ex. 150g chicken breast, 1 cup rice for lunch)\n": "Adding to the menu of %s %s on %s (e.g. 150g chicken breast, 1 cup rice for lunch)\n",
+	"Aliments : ":           "Foods: ",
+	"Aliments ajoutés.":     "Foods added.",
+	"Aucun aliment ajouté.": "No food added.",
+	"Appuyez sur Entrée pour revenir au tableau de bord.": "Press Enter to return to the dashboard.",
+	// Réponses d'erreur de l'API FDC
+	"l'API FDC a répondu %s":          "the FDC API responded %s",
+	"aliment %d introuvable dans FDC": "food %d not found in FDC",
+	// Fusion des menus avec des repas du même type
+	"%s le %s (menus %s)": "%s on %s (menus %s)",
+	"erreur lors de la récupération des repas des menus en double : %w":                                                                                            "error retrieving the meals of the duplicate menus: %w",
+	"repas du même type dans des menus à fusionner : %s ; supprimez l'un des repas (gofit delmeal) ou déplacez l'un des menus (gofit editmenu) avant de fusionner": "meals of the same type in menus to merge: %s; delete one of the meals (gofit delmeal) or move one of the menus (gofit editmenu) before merging",
+	// Modification des repas
+	"repas %d introuvable : %w": "meal %d not found: %w",
+	"le repas %d contient %d aliment(s) : ses calories et macronutriments sont calculés à partir d'eux et ne peuvent pas être modifiés": "meal %d contains %d food(s): its calories and macronutrients are computed from them and cannot be edited",
+	// Mot-clé d'abandon des questionnaires
+	"annuler": "cancel",
+	// Micronutriments
+	"%s %s (%s)": "%s %s (%s)",
+	"La limite de la vitamine A ne vise que le rétinol (vitamine A préformée), pas les caroténoïdes des végétaux": "The vitamin A limit applies to retinol (preformed vitamin A) only, not to plant carotenoids",
+	"Rétinol": "Retinol",
+	// Commandes
+	"%s n'est disponible que dans la boucle interactive (gofit repl)\n": "%s is only available in the interactive loop (gofit repl)\n",
+	// Erreurs des paquets de terminal et de sortie
+	"format %q non géré par output.Write": "format %q not supported by output.Write",
+	"interrompu":                          "interrupted",
+	"l'entrée n'est pas un terminal":      "the input is not a terminal",
+}
@@ -0,0 +1,203 @@
+// Package i18n traduit les messages de la CLI et formate nombres et dates
+// selon la langue choisie.
+//
+// Les messages sont écrits en français dans le code et servent de clé : le
+// français est la langue source, les autres langues ont leur catalogue
+// (catalog_en.go). Un message absent d'un catalogue est affiché en français.
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale est une langue de l'interface
+type Locale string
+
+const (
+	French  Locale = "fr"
+	English Locale = "en"
+)
+
+// Locales liste les langues disponibles
+var Locales = []Locale{French, English}
+
+// format regroupe les conventions d'écriture propres à une langue
+type format struct {
+	dateLayout     string
+	datePattern    string
+	dateTimeLayout string
+	shortLayout    string
+	decimalComma   bool
+}
+
+var formats = map[Locale]format{
+	French:  {"02/01/2006", "JJ/MM/AAAA", "02/01/2006 15:04", "02/01", true},
+	English: {"01/02/2006", "MM/DD/YYYY", "01/02/2006 3:04 PM", "01/02", false},
+}
+
+var catalogs = map[Locale]map[string]string{
+	English: catalogEN,
+}
+
+var current = French
+
+// SetLocale change la langue de l'interface
+func SetLocale(l Locale) {
+	current = l
+}
+
+// Current renvoie la langue de l'interface
+func Current() Locale {
+	return current
+}
+
+// ParseLocale reconnaît « fr », « en » ainsi que les valeurs de LANG (« en_US.UTF-8 », « fr-CA »…)
+func ParseLocale(s string) (Locale, error) {
+	lang := strings.ToLower(s)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	for _, l := range Locales {
+		if lang == string(l) {
+			return l, nil
+		}
+	}
+	return "", Errorf("langue inconnue : %q (fr ou en)", s)
+}
+
+// String et Set permettent d'utiliser une Locale comme option de flag.FlagSet
+func (l *Locale) String() string { return string(*l) }
+
+func (l *Locale) Set(s string) error {
+	parsed, err := ParseLocale(s)
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// Detect choisit la langue : GOFIT_LANG, sinon configured (fichier de
+// configuration), sinon LC_ALL, LC_MESSAGES ou LANG, sinon le français
+func Detect(configured string) Locale {
+	candidates := []string{os.Getenv("GOFIT_LANG"), configured,
+		os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, c := range candidates {
+		if l, err := ParseLocale(c); err == nil {
+			return l
+		}
+	}
+	return French
+}
+
+// T traduit un message dans la langue courante
+func T(msg string) string {
+	if translated, ok := catalogs[current][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// message est une erreur dont le texte est traduit à l'affichage, ce qui
+// permet de déclarer des erreurs sentinelles avant le choix de la langue
+type message string
+
+func (m message) Error() string {
+	return T(string(m))
+}
+
+// New est l'équivalent traduit de errors.New
+func New(msg string) error {
+	return message(msg)
+}
+
+// Sprintf traduit format puis le complète ; les nombres décimaux suivent la
+// convention de la langue (virgule en français)
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), localize(args)...)
+}
+
+// Errorf est l'équivalent traduit de fmt.Errorf ; %w reste utilisable
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), localize(args)...)
+}
+
+// Printf écrit un message traduit sur la sortie standard
+func Printf(format string, args ...any) {
+	fmt.Print(Sprintf(format, args...))
+}
+
+// Println écrit un message traduit suivi d'un retour à la ligne
+func Println(msg string) {
+	fmt.Println(T(msg))
+}
+
+// Fprintf écrit un message traduit sur w
+func Fprintf(w io.Writer, format string, args ...any) {
+	fmt.Fprint(w, Sprintf(format, args...))
+}
+
+// Fprintln écrit un message traduit sur w suivi d'un retour à la ligne
+func Fprintln(w io.Writer, msg string) {
+	fmt.Fprintln(w, T(msg))
+}
+
+// Float formate un nombre avec prec décimales
+func Float(v float64, prec int) string {
+	return decimal(strconv.FormatFloat(v, 'f', prec, 64))
+}
+
+// Date formate un jour calendaire (19/10/2026 en français, 10/19/2026 en anglais)
+func Date(t time.Time) string {
+	return t.Format(formats[current].dateLayout)
+}
+
+// DateTime formate un instant à la minute près
+func DateTime(t time.Time) string {
+	return t.Format(formats[current].dateTimeLayout)
+}
+
+// ShortDate formate le jour et le mois, pour les axes des graphiques
+func ShortDate(t time.Time) string {
+	return t.Format(formats[current].shortLayout)
+}
+
+// DateLayout renvoie la mise en page time.Parse des dates saisies
+func DateLayout() string {
+	return formats[current].dateLayout
+}
+
+// DatePattern renvoie le format des dates tel qu'il est montré à l'utilisateur (JJ/MM/AAAA)
+func DatePattern() string {
+	return formats[current].datePattern
+}
+
+// localFloat formate un float64 avec le séparateur décimal de la langue
+type localFloat float64
+
+func (f localFloat) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, decimal(fmt.Sprintf(fmt.FormatString(s, verb), float64(f))))
+}
+
+func localize(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		if f, ok := arg.(float64); ok {
+			arg = localFloat(f)
+		}
+		out[i] = arg
+	}
+	return out
+}
+
+func decimal(s string) string {
+	if formats[current].decimalComma {
+		return strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
@@ -0,0 +1,340 @@
+// Package lineedit lit une ligne au terminal avec édition (flèches, début et
+// fin de ligne, effacement), historique et complétion par Tab.
+package lineedit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// ErrInterrupted est renvoyée par ReadLine quand l'utilisateur tape Ctrl-C
+var ErrInterrupted = i18n.New("interrompu")
+
+// DefaultMaxHistory est le nombre de lignes conservées dans l'historique
+const DefaultMaxHistory = 1000
+
+// Candidate est une complétion possible du mot en cours de saisie
+type Candidate struct {
+	// Value remplace le mot saisi
+	Value string
+	// Description est affichée à côté de Value dans la liste des complétions
+	Description string
+}
+
+// Completer renvoie les complétions de word ; prefix est le début de la
+// ligne, jusqu'au mot en cours
+type Completer func(prefix, word string) []Candidate
+
+// Editor lit des lignes sur un terminal
+type Editor struct {
+	in  *os.File
+	out io.Writer
+	r   *bufio.Reader
+
+	// Complete propose les complétions ; nil pour désactiver Tab
+	Complete Completer
+	// MaxHistory borne l'historique
+	MaxHistory int
+
+	history []string
+}
+
+// IsTerminal indique si f est un terminal, auquel cas un Editor peut le lire
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// New crée un éditeur qui lit le terminal in et affiche sur out
+func New(in *os.File, out io.Writer) *Editor {
+	return NewShared(in, bufio.NewReader(in), out)
+}
+
+// NewShared crée un éditeur qui lit le terminal in à travers r, le tampon
+// d'un autre lecteur du même terminal : une saisie tapée d'avance n'est pas
+// perdue en passant de l'un à l'autre
+func NewShared(in *os.File, r *bufio.Reader, out io.Writer) *Editor {
+	return &Editor{in: in, out: out, r: r, MaxHistory: DefaultMaxHistory}
+}
+
+// AddHistory ajoute une ligne à l'historique, sauf si elle répète la précédente
+func (e *Editor) AddHistory(line string) {
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > e.MaxHistory {
+		e.history = e.history[len(e.history)-e.MaxHistory:]
+	}
+}
+
+// LoadHistory lit l'historique enregistré dans path ; un fichier absent est ignoré
+func (e *Editor) LoadHistory(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		e.AddHistory(line)
+	}
+	return nil
+}
+
+// SaveHistory écrit l'historique dans path, lisible par son seul propriétaire
+func (e *Editor) SaveHistory(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, line := range e.history {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// ReadLine affiche prompt et renvoie la ligne saisie. Elle renvoie io.EOF
+// sur Ctrl-D quand la ligne est vide et ErrInterrupted sur Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(int(e.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(e.in.Fd()), state)
+
+	l := &line{prompt: prompt, historyPos: len(e.history)}
+	l.refresh(e.out)
+	for {
+		r, _, err := e.r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(l.buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl-D
+			if len(l.buf) == 0 {
+				return "", io.EOF
+			}
+			l.deleteForward()
+		case 1: // Ctrl-A
+			l.pos = 0
+		case 5: // Ctrl-E
+			l.pos = len(l.buf)
+		case 2: // Ctrl-B
+			l.left()
+		case 6: // Ctrl-F
+			l.right()
+		case 8, 127: // Retour arrière
+			l.deleteBackward()
+		case 11: // Ctrl-K
+			l.buf = l.buf[:l.pos]
+		case 21: // Ctrl-U
+			l.buf = l.buf[l.pos:]
+			l.pos = 0
+		case 23: // Ctrl-W
+			l.deleteWord()
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 16: // Ctrl-P
+			e.historyMove(l, -1)
+		case 14: // Ctrl-N
+			e.historyMove(l, 1)
+		case '\t':
+			e.complete(l)
+		case 27:
+			e.escape(l)
+		default:
+			if !unicode.IsControl(r) {
+				l.insert(r)
+			}
+		}
+		l.refresh(e.out)
+	}
+}
+
+// escape interprète les séquences des touches fléchées, Début, Fin et Suppr
+func (e *Editor) escape(l *line) {
+	r, _, err := e.r.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+	var seq []rune
+	for {
+		r, _, err = e.r.ReadRune()
+		if err != nil {
+			return
+		}
+		seq = append(seq, r)
+		if r < '0' || r > '9' {
+			break
+		}
+	}
+	switch string(seq) {
+	case "A":
+		e.historyMove(l, -1)
+	case "B":
+		e.historyMove(l, 1)
+	case "C":
+		l.right()
+	case "D":
+		l.left()
+	case "H", "1~", "7~":
+		l.pos = 0
+	case "F", "4~", "8~":
+		l.pos = len(l.buf)
+	case "3~":
+		l.deleteForward()
+	}
+}
+
+// historyMove remplace la ligne par l'entrée précédente (-1) ou suivante (1)
+// de l'historique ; la saisie en cours est retrouvée en redescendant
+func (e *Editor) historyMove(l *line, delta int) {
+	pos := l.historyPos + delta
+	if pos < 0 || pos > len(e.history) {
+		return
+	}
+	if l.historyPos == len(e.history) {
+		l.draft = append([]rune(nil), l.buf...)
+	}
+	l.historyPos = pos
+	if pos == len(e.history) {
+		l.buf = l.draft
+	} else {
+		l.buf = []rune(e.history[pos])
+	}
+	l.pos = len(l.buf)
+}
+
+// complete complète le mot sous le curseur : une seule complétion est
+// insérée, sinon le préfixe commun l'est et les complétions sont listées
+func (e *Editor) complete(l *line) {
+	if e.Complete == nil {
+		return
+	}
+	start := l.pos
+	for start > 0 && l.buf[start-1] != ' ' {
+		start--
+	}
+	prefix, word := string(l.buf[:start]), string(l.buf[start:l.pos])
+	candidates := e.Complete(prefix, word)
+
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, "\a")
+		return
+	case 1:
+		l.replace(start, candidates[0].Value+" ")
+		return
+	}
+	if common := commonPrefix(candidates); len(common) > len(word) && strings.HasPrefix(common, word) {
+		l.replace(start, common)
+		return
+	}
+	width := 0
+	for _, c := range candidates {
+		width = max(width, len([]rune(c.Value)))
+	}
+	fmt.Fprint(e.out, "\r\n")
+	for _, c := range candidates {
+		fmt.Fprintf(e.out, "  %-*s  %s\r\n", width, c.Value, c.Description)
+	}
+}
+
+// commonPrefix renvoie le plus long début commun aux candidats, raccourci
+// caractère par caractère pour ne jamais couper un caractère multi-octet
+func commonPrefix(candidates []Candidate) string {
+	common := candidates[0].Value
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c.Value, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	return common
+}
+
+// line est la ligne en cours d'édition
+type line struct {
+	prompt     string
+	buf        []rune
+	pos        int
+	historyPos int
+	draft      []rune
+}
+
+func (l *line) insert(r rune) {
+	l.buf = append(l.buf[:l.pos], append([]rune{r}, l.buf[l.pos:]...)...)
+	l.pos++
+}
+
+// replace remplace le texte entre start et le curseur par s
+func (l *line) replace(start int, s string) {
+	rest := append([]rune(s), l.buf[l.pos:]...)
+	l.buf = append(l.buf[:start], rest...)
+	l.pos = start + len([]rune(s))
+}
+
+func (l *line) left() {
+	if l.pos > 0 {
+		l.pos--
+	}
+}
+
+func (l *line) right() {
+	if l.pos < len(l.buf) {
+		l.pos++
+	}
+}
+
+func (l *line) deleteBackward() {
+	if l.pos > 0 {
+		l.buf = append(l.buf[:l.pos-1], l.buf[l.pos:]...)
+		l.pos--
+	}
+}
+
+func (l *line) deleteForward() {
+	if l.pos < len(l.buf) {
+		l.buf = append(l.buf[:l.pos], l.buf[l.pos+1:]...)
+	}
+}
+
+func (l *line) deleteWord() {
+	start := l.pos
+	for start > 0 && l.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && l.buf[start-1] != ' ' {
+		start--
+	}
+	l.buf = append(l.buf[:start], l.buf[l.pos:]...)
+	l.pos = start
+}
+
+// refresh réécrit la ligne et replace le curseur
+func (l *line) refresh(out io.Writer) {
+	fmt.Fprintf(out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(out, "\x1b[%dD", back)
+	}
+}
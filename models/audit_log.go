@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// AuditLog trace une modification de données. La table est en ajout seul :
+// un trigger refuse toute modification ou suppression de ses lignes.
+type AuditLog struct {
+	ID        uint        `gorm:"primaryKey"`
+	Entity    string      `gorm:"not null;index:idx_audit_logs_entity"`
+	EntityID  uint        `gorm:"not null;index:idx_audit_logs_entity"`
+	Action    AuditAction `gorm:"not null"`
+	Before    string      `gorm:"type:jsonb;not null"`
+	After     string      `gorm:"type:jsonb;not null"`
+	Actor     string      `gorm:"not null"`
+	Command   string
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,521 @@
+package models
+
+import (
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lsoulet/gofit/i18n"
+	chart "github.com/wcharczuk/go-chart/v2"
+)
+
+// ChartKind est le sujet d'un graphique
+type ChartKind string
+
+const (
+	// ChartNutrition trace les calories et les macronutriments de chaque menu journalier
+	ChartNutrition ChartKind = "nutrition"
+	// ChartBody trace l'IMC et la masse grasse de chaque mesure
+	ChartBody ChartKind = "body"
+	// ChartMacros trace en anneau la part des protéines, des glucides et des
+	// lipides dans les calories de la période
+	ChartMacros ChartKind = "macros"
+	// ChartMeals trace pour chaque jour une barre empilée des calories de
+	// chaque type de repas
+	ChartMeals ChartKind = "meals"
+	// ChartTargets trace en barres les apports moyens de la période en
+	// pourcentage des besoins journaliers
+	ChartTargets ChartKind = "targets"
+)
+
+// ChartView est la façon de placer les courbes sur les axes
+type ChartView string
+
+const (
+	// ChartCombined trace toutes les courbes sur un seul axe
+	ChartCombined ChartView = "combined"
+	// ChartDualAxis trace la première courbe (calories, IMC) sur l'axe de
+	// gauche et les autres sur un second axe, à droite
+	ChartDualAxis ChartView = "dual"
+	// ChartNormalized trace chaque courbe en pourcentage de sa moyenne sur la
+	// période, pour comparer leurs variations
+	ChartNormalized ChartView = "normalized"
+)
+
+// ChartFormat est le format d'image d'un graphique
+type ChartFormat string
+
+const (
+	ChartPNG ChartFormat = "png"
+	ChartSVG ChartFormat = "svg"
+)
+
+// Taille par défaut des graphiques, en pixels
+const (
+	DefaultChartWidth  = 1024
+	DefaultChartHeight = 512
+)
+
+// ErrChartData signale qu'il n'y a pas assez de points pour tracer une courbe
+var ErrChartData = i18n.New("pas assez de données pour tracer le graphique : au moins deux jours ou deux mesures sont nécessaires")
+
+// ErrChartEmpty signale qu'aucun apport n'est enregistré sur la période
+var ErrChartEmpty = i18n.New("aucun apport enregistré sur la période : rien à tracer")
+
+// chartMealTypes est l'ordre des types de repas dans les barres empilées
+var chartMealTypes = []MealType{Breakfast, Lunch, Dinner, Snack}
+
+// ChartOptions règle le tracé d'un graphique ; les valeurs nulles prennent
+// la valeur par défaut
+type ChartOptions struct {
+	Width  int
+	Height int
+	Format ChartFormat
+	View   ChartView
+	// From et To (exclu) limitent les jours tracés ; zéro pour ne pas limiter
+	From time.Time
+	To   time.Time
+}
+
+// size renvoie la largeur et la hauteur de l'image
+func (o ChartOptions) size() (int, int) {
+	width, height := o.Width, o.Height
+	if width <= 0 {
+		width = DefaultChartWidth
+	}
+	if height <= 0 {
+		height = DefaultChartHeight
+	}
+	return width, height
+}
+
+// in indique si le jour day est dans la période
+func (o ChartOptions) in(day time.Time) bool {
+	return (o.From.IsZero() || !day.Before(o.From)) && (o.To.IsZero() || day.Before(o.To))
+}
+
+// renderer est un graphique go-chart : courbes, anneau ou barres
+type renderer interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+// render écrit le graphique c dans w au format de opts
+func (o ChartOptions) render(w io.Writer, c renderer) error {
+	if o.Format == ChartSVG {
+		return c.Render(chart.SVG, w)
+	}
+	return c.Render(chart.PNG, w)
+}
+
+// chartSeries est une courbe avant son placement sur les axes
+type chartSeries struct {
+	name   string
+	values []float64
+}
+
+// GenerateNutritionChart écrit dans filename le graphique PNG des calories
+// et des macronutriments de chaque menu journalier
+func (u *User) GenerateNutritionChart(filename string) error {
+	return writeChart(filename, u.RenderNutritionChart)
+}
+
+// RenderNutritionChart écrit dans w le graphique PNG de GenerateNutritionChart
+func (u *User) RenderNutritionChart(w io.Writer) error {
+	return u.RenderChart(w, ChartNutrition, ChartOptions{})
+}
+
+// GenerateBodyTrackingChart écrit dans filename le graphique PNG de l'IMC
+// et de la masse grasse de chaque mesure
+func (u *User) GenerateBodyTrackingChart(filename string) error {
+	if len(u.Measurements) == 0 {
+		return i18n.New("aucune mesure disponible pour générer le graphique")
+	}
+	return writeChart(filename, u.RenderBodyTrackingChart)
+}
+
+// RenderBodyTrackingChart écrit dans w le graphique PNG de GenerateBodyTrackingChart
+func (u *User) RenderBodyTrackingChart(w io.Writer) error {
+	return u.RenderChart(w, ChartBody, ChartOptions{})
+}
+
+// RenderChart écrit dans w le graphique kind des menus ou des mesures de la
+// période de opts. Les courbes demandent au moins deux points, sinon
+// ErrChartData ; l'anneau et les barres au moins un jour avec des calories,
+// sinon ErrChartEmpty.
+func (u *User) RenderChart(w io.Writer, kind ChartKind, opts ChartOptions) error {
+	switch kind {
+	case ChartMacros:
+		return u.renderMacros(w, opts)
+	case ChartMeals:
+		return u.renderMeals(w, opts)
+	case ChartTargets:
+		return u.renderTargets(w, opts)
+	}
+	return u.renderLines(w, kind, opts)
+}
+
+// renderLines trace les courbes nutrition ou body selon la vue de opts
+func (u *User) renderLines(w io.Writer, kind ChartKind, opts ChartOptions) error {
+	var dates []time.Time
+	var series []chartSeries
+	var unit, secondaryUnit string
+	if kind == ChartBody {
+		// Trie les mesures par date
+		sort.Slice(u.Measurements, func(i, j int) bool {
+			return u.Measurements[i].Date.Before(u.Measurements[j].Date)
+		})
+		series = []chartSeries{{name: i18n.T("IMC")}, {name: i18n.T("Masse grasse (%)")}}
+		for _, m := range u.Measurements {
+			if !opts.in(CalendarDay(m.Date.In(u.Location()))) {
+				continue
+			}
+			dates = append(dates, m.Date)
+			series[0].values = append(series[0].values, m.BMI)
+			series[1].values = append(series[1].values, m.BodyFat)
+		}
+		unit, secondaryUnit = i18n.T("Valeur"), i18n.T("Masse grasse (%)")
+	} else {
+		// Trie les menus par date
+		sort.Slice(u.DailyMenus, func(i, j int) bool {
+			return u.DailyMenus[i].Date.Before(u.DailyMenus[j].Date)
+		})
+		series = []chartSeries{{name: i18n.T("Calories")}, {name: i18n.T("Protéines (g)")},
+			{name: i18n.T("Glucides (g)")}, {name: i18n.T("Lipides (g)")}}
+		for _, dm := range u.DailyMenus {
+			if !opts.in(CalendarDay(dm.Date)) {
+				continue
+			}
+			dates = append(dates, dm.Date)
+			cal, prot, carb, fat, _ := dm.GetDailyMacroSummary()
+			for i, v := range []float64{cal, prot, carb, fat} {
+				series[i].values = append(series[i].values, v)
+			}
+		}
+		unit, secondaryUnit = i18n.T("Valeur (kcal / g)"), i18n.T("Macronutriments (g)")
+	}
+	if len(dates) < 2 {
+		return ErrChartData
+	}
+
+	width, height := opts.size()
+	graph := chart.Chart{
+		Width:  width,
+		Height: height,
+		XAxis: chart.XAxis{
+			Name: i18n.T("Date"),
+			ValueFormatter: func(v interface{}) string {
+				// go-chart passe les graduations d'une TimeSeries en float64
+				switch val := v.(type) {
+				case time.Time:
+					return i18n.ShortDate(val)
+				case float64:
+					return i18n.ShortDate(chart.TimeFromFloat64(val))
+				}
+				return ""
+			},
+		},
+		YAxis: chart.YAxis{
+			Name: unit,
+		},
+	}
+	var primary, secondary []float64
+	for i, s := range series {
+		values := s.values
+		axis := chart.YAxisPrimary
+		switch opts.View {
+		case ChartNormalized:
+			values = normalized(values)
+		case ChartDualAxis:
+			if i > 0 {
+				axis = chart.YAxisSecondary
+			}
+		}
+		if axis == chart.YAxisSecondary {
+			secondary = append(secondary, values...)
+		} else {
+			primary = append(primary, values...)
+		}
+		graph.Series = append(graph.Series, chart.TimeSeries{
+			Name:    s.name,
+			XValues: dates,
+			YValues: values,
+			YAxis:   axis,
+			Style:   chart.Style{StrokeColor: chart.GetDefaultColor(i), StrokeWidth: 2},
+		})
+	}
+	switch opts.View {
+	case ChartNormalized:
+		graph.YAxis.Name = i18n.T("% de la moyenne de la période")
+	case ChartDualAxis:
+		graph.YAxis.Name = series[0].name
+		graph.YAxisSecondary = chart.YAxis{Name: secondaryUnit, Range: flatRange(secondary)}
+		// go-chart ne réserve pas la place du nom de l'axe de gauche
+		graph.Background = chart.Style{Padding: chart.Box{Top: 20, Left: 30, Right: 20, Bottom: 20}}
+	}
+	graph.YAxis.Range = flatRange(primary)
+
+	// Active la légende
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+
+	return opts.render(w, graph)
+}
+
+// renderMacros trace en anneau la part de chaque macronutriment dans les
+// calories des menus de la période, à 4 kcal par gramme de protéines ou de
+// glucides et 9 kcal par gramme de lipides
+func (u *User) renderMacros(w io.Writer, opts ChartOptions) error {
+	var kcal [3]float64
+	for _, dm := range u.DailyMenus {
+		if !opts.in(CalendarDay(dm.Date)) {
+			continue
+		}
+		_, prot, carb, fat, _ := dm.GetDailyMacroSummary()
+		kcal[0] += prot * 4
+		kcal[1] += carb * 4
+		kcal[2] += fat * 9
+	}
+	total := kcal[0] + kcal[1] + kcal[2]
+	if total <= 0 {
+		return ErrChartEmpty
+	}
+
+	width, height := opts.size()
+	donut := chart.DonutChart{Width: width, Height: height}
+	names := []string{i18n.T("Protéines"), i18n.T("Glucides"), i18n.T("Lipides")}
+	for i, v := range kcal {
+		if v <= 0 {
+			continue
+		}
+		// Mêmes couleurs que les courbes de ChartNutrition
+		donut.Values = append(donut.Values, chart.Value{
+			Label: i18n.Sprintf("%s %s %%", names[i], i18n.Float(v/total*100, 0)),
+			Value: v,
+			Style: chart.Style{FillColor: chart.GetDefaultColor(i + 1)},
+		})
+	}
+	return opts.render(w, donut)
+}
+
+// renderMeals trace pour chaque jour de la période une barre de la part des
+// calories de chaque type de repas, étiquetée en kcal
+func (u *User) renderMeals(w io.Writer, opts ChartOptions) error {
+	sort.Slice(u.DailyMenus, func(i, j int) bool {
+		return u.DailyMenus[i].Date.Before(u.DailyMenus[j].Date)
+	})
+	var bars []chart.StackedBar
+	for _, dm := range u.DailyMenus {
+		if !opts.in(CalendarDay(dm.Date)) {
+			continue
+		}
+		kcal := map[MealType]float64{}
+		var total float64
+		for _, meal := range dm.Meals {
+			kcal[meal.Type] += meal.Calories
+		}
+		for _, t := range chartMealTypes {
+			total += kcal[t]
+		}
+		if total <= 0 {
+			continue
+		}
+		bar := chart.StackedBar{Name: i18n.ShortDate(dm.Date)}
+		for i, t := range chartMealTypes {
+			value := chart.Value{Value: kcal[t], Style: mealStyle(i)}
+			// Pas d'étiquette sur les parts trop fines pour la contenir
+			if kcal[t]/total >= 0.08 {
+				value.Label = i18n.Float(kcal[t], 0)
+			}
+			bar.Values = append(bar.Values, value)
+		}
+		bars = append(bars, bar)
+	}
+	if len(bars) == 0 {
+		return ErrChartEmpty
+	}
+
+	width, height := opts.size()
+	graph := chart.StackedBarChart{
+		Width:      width,
+		Height:     height,
+		Background: chart.Style{Padding: chart.Box{Top: 40, Left: 20, Right: 60, Bottom: 10}},
+		Elements:   []chart.Renderable{mealLegend},
+	}
+	// Les barres se partagent la largeur laissée par l'axe des pourcentages
+	step := (width - 80) / len(bars)
+	graph.BarSpacing = max(step/4, 1)
+	for i := range bars {
+		bars[i].Width = max(step-graph.BarSpacing, 1)
+	}
+	graph.Bars = bars
+	return opts.render(w, graph)
+}
+
+// mealStyle renvoie le style de la part du type de repas chartMealTypes[i]
+func mealStyle(i int) chart.Style {
+	color := chart.GetDefaultColor(i)
+	return chart.Style{FillColor: color, StrokeColor: color, FontColor: chart.ColorWhite}
+}
+
+// mealLegend dessine au-dessus des barres la couleur de chaque type de repas ;
+// go-chart n'a de légende que pour les courbes
+func mealLegend(r chart.Renderer, box chart.Box, defaults chart.Style) {
+	style := chart.Style{FontColor: chart.ColorBlack}.InheritFrom(defaults)
+	x := box.Left
+	for i, t := range chartMealTypes {
+		chart.Draw.Box(r, chart.Box{Top: box.Top - 26, Left: x, Right: x + 12, Bottom: box.Top - 14}, mealStyle(i))
+		style.WriteTextOptionsToRenderer(r)
+		r.Text(string(t), x+18, box.Top-15)
+		x += 18 + r.MeasureText(string(t)).Width() + 24
+	}
+}
+
+// renderTargets trace en barres l'apport moyen des jours enregistrés de la
+// période en pourcentage des besoins journaliers, avec une ligne à 100 %
+func (u *User) renderTargets(w io.Writer, opts ChartOptions) error {
+	target := *u
+	if target.CalorieNeeds <= 0 {
+		target.UpdateNutritionGoals()
+	}
+	if target.CalorieNeeds <= 0 {
+		return i18n.Errorf("aucun objectif nutritionnel pour %s %s : enregistrez une mesure (gofit addmeasurement)",
+			u.FirstName, u.LastName)
+	}
+	var intake [4]float64
+	days := 0
+	for _, dm := range u.DailyMenus {
+		if !opts.in(CalendarDay(dm.Date)) {
+			continue
+		}
+		cal, prot, carb, fat, _ := dm.GetDailyMacroSummary()
+		for i, v := range []float64{cal, prot, carb, fat} {
+			intake[i] += v
+		}
+		days++
+	}
+	if days == 0 {
+		return ErrChartEmpty
+	}
+
+	needs := []float64{target.CalorieNeeds, target.ProteinNeeds, target.CarohydratesNeeds, target.LipidNeeds}
+	names := []string{i18n.T("Calories"), i18n.T("Protéines"), i18n.T("Glucides"), i18n.T("Lipides")}
+	units := []string{"kcal", "g", "g", "g"}
+	width, height := opts.size()
+	graph := chart.BarChart{
+		Width:      width,
+		Height:     height,
+		BarWidth:   width / 8,
+		BarSpacing: width / 8,
+	}
+	top := 100.0
+	for i, need := range needs {
+		average := intake[i] / float64(days)
+		percent := 0.0
+		if need > 0 {
+			percent = average / need * 100
+		}
+		top = math.Max(top, percent)
+		color := chart.GetDefaultColor(i)
+		graph.Bars = append(graph.Bars, chart.Value{
+			Label: i18n.Sprintf("%s : %s / %s %s", names[i], i18n.Float(average, 0), i18n.Float(need, 0), units[i]),
+			Value: percent,
+			Style: chart.Style{FillColor: color, StrokeColor: color},
+		})
+	}
+	// Graduations rondes, dont 100 %
+	step := 25.0
+	if top > 200 {
+		step = 50 * math.Ceil(top/400)
+	}
+	var ticks []chart.Tick
+	for v := 0.0; v < top+step; v += step {
+		ticks = append(ticks, chart.Tick{Value: v, Label: i18n.Float(v, 0) + " %"})
+	}
+	graph.YAxis = chart.YAxis{
+		Name:  i18n.T("% des besoins journaliers"),
+		Range: &chart.ContinuousRange{Min: 0, Max: ticks[len(ticks)-1].Value},
+		Ticks: ticks,
+		// Seule ligne du quadrillage : l'objectif
+		GridLines:      []chart.GridLine{{Value: 100}},
+		GridMajorStyle: chart.Style{StrokeColor: chart.ColorRed, StrokeWidth: 1.5, StrokeDashArray: []float64{6, 4}},
+	}
+	return opts.render(w, graph)
+}
+
+// normalized renvoie values en pourcentage de leur moyenne ; des zéros si
+// la moyenne est nulle
+func normalized(values []float64) []float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	out := make([]float64, len(values))
+	for i, v := range values {
+		if mean != 0 {
+			out[i] = v / mean * 100
+		}
+	}
+	return out
+}
+
+// flatRange renvoie un axe d'une unité autour de values si elles sont
+// toutes égales, que go-chart ne sait pas graduer ; nil sinon
+func flatRange(values []float64) chart.Range {
+	if len(values) == 0 {
+		return nil
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	if low != high {
+		return nil
+	}
+	return &chart.ContinuousRange{Min: low, Max: low + 1}
+}
+
+// ParseChartKind lit le sujet d'un graphique
+func ParseChartKind(s string) (ChartKind, error) {
+	switch k := ChartKind(strings.ToLower(s)); k {
+	case ChartNutrition, ChartBody, ChartMacros, ChartMeals, ChartTargets:
+		return k, nil
+	}
+	return "", i18n.Errorf("%q : utilisez %s, %s, %s, %s ou %s", s, ChartNutrition, ChartBody, ChartMacros, ChartMeals, ChartTargets)
+}
+
+// ParseChartView lit la façon de placer les courbes sur les axes
+func ParseChartView(s string) (ChartView, error) {
+	switch v := ChartView(strings.ToLower(s)); v {
+	case ChartCombined, ChartDualAxis, ChartNormalized:
+		return v, nil
+	}
+	return "", i18n.Errorf("%q : utilisez %s, %s ou %s", s, ChartCombined, ChartDualAxis, ChartNormalized)
+}
+
+// ParseChartFormat lit un format d'image
+func ParseChartFormat(s string) (ChartFormat, error) {
+	switch f := ChartFormat(strings.ToLower(s)); f {
+	case ChartPNG, ChartSVG:
+		return f, nil
+	}
+	return "", i18n.Errorf("%q : utilisez %s ou %s", s, ChartPNG, ChartSVG)
+}
+
+// writeChart crée filename et y écrit le graphique de render
+func writeChart(filename string, render func(io.Writer) error) error {
+	// Création du fichier image
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
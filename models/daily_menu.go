@@ -1,13 +1,20 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// DailyMenu regroupe les repas d'un utilisateur pour un jour calendaire.
+// Il existe au plus un menu par (UserID, Date) : voir l'index idx_daily_menus_user_day_active.
 type DailyMenu struct {
-	ID     uint      `gorm:"primaryKey"`
-	UserID uint
-	User   User      `gorm:"foreignKey:UserID"`
-	Date   time.Time
-	Meals  []Meal    `gorm:"many2many:dailymenu_meals;"`
+	ID        uint           `gorm:"primaryKey"`
+	UserID    uint           `gorm:"not null"`
+	User      User           `gorm:"foreignKey:UserID"`
+	Date      time.Time      `gorm:"type:date;not null"`
+	Meals     []Meal         `gorm:"foreignKey:DailyMenuID"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (d *DailyMenu) GetDailyMacroSummary() (float64, float64, float64, float64, error) {
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Food est un aliment FDC mis en cache : ses valeurs pour 100 g évitent
+// d'interroger l'API à chaque fois qu'il est ajouté à un repas.
+type Food struct {
+	FdcID         int `gorm:"primaryKey;autoIncrement:false"`
+	Name          string
+	Calories      float64
+	Proteins      float64
+	Carbohydrates float64
+	Lipids        float64
+	FetchedAt     time.Time
+	// DetailedAt est l'instant où ses nutriments (FoodNutrient) ont été
+	// enregistrés ; nil pour un aliment repris des repas sans interroger l'API
+	DetailedAt *time.Time
+}
+
+// FoodNutrient est la quantité pour 100 g d'un nutriment d'un aliment en
+// cache, identifié par son numéro FDC (307 pour le sodium...)
+type FoodNutrient struct {
+	FdcID  int    `gorm:"primaryKey;autoIncrement:false"`
+	Number string `gorm:"primaryKey"`
+	Name   string
+	Unit   string
+	Amount float64
+}
+
+// FavoriteFood est un aliment mis en favori par un utilisateur
+type FavoriteFood struct {
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	FdcID     int  `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt time.Time
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/lsoulet/gofit/i18n"
+
 type Goal string
 
 const (
@@ -7,3 +9,16 @@ const (
 	MuscleGain  Goal = "muscle_gain"
 	Maintenance Goal = "maintenance"
 )
+
+// Label renvoie le nom affiché de l'objectif
+func (g Goal) Label() string {
+	switch g {
+	case WeightLoss:
+		return i18n.T("Perte de poids")
+	case MuscleGain:
+		return i18n.T("Prise de masse")
+	case Maintenance:
+		return i18n.T("Maintien")
+	}
+	return string(g)
+}
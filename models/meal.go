@@ -1,14 +1,25 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Meal est soit un repas type (DailyMenuID nil), réutilisable via addmeal,
+// soit un repas rattaché à un unique menu journalier.
 type Meal struct {
 	ID            uint `gorm:"primaryKey"`
-	DailyMenuID   uint
+	DailyMenuID   *uint
 	Type          MealType
 	Description   string
 	Calories      float64
 	Proteins      float64
 	Carbohydrates float64
 	Lipids        float64
+	LoggedAt      time.Time
+	Items         []MealItem     `gorm:"foreignKey:MealID"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
 }
 
 func (m *Meal) GetMacros() (float64, float64, float64, float64) {
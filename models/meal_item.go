@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MealItem est un aliment FDC ajouté à un repas, avec ses valeurs nutritionnelles
+// pour la quantité saisie et l'instant où il a été enregistré.
+type MealItem struct {
+	ID            uint `gorm:"primaryKey"`
+	MealID        uint `gorm:"not null;index"`
+	FdcID         int
+	Name          string
+	Quantity      float64
+	Calories      float64
+	Proteins      float64
+	Carbohydrates float64
+	Lipids        float64
+	LoggedAt      time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
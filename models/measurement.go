@@ -1,24 +1,27 @@
 package models
 
 import (
-	"errors"
 	"math"
 	"time"
+
+	"github.com/lsoulet/gofit/i18n"
+	"gorm.io/gorm"
 )
 
 type Measurement struct {
-	ID      uint `gorm:"primaryKey"`
-	UserID  uint
-	Date    time.Time
-	Weight  float64
-	Height  float64
-	BodyFat float64
-	BMI     float64
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint
+	Date      time.Time
+	Weight    float64
+	Height    float64
+	BodyFat   float64
+	BMI       float64
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func CalculateBMI(weight, height float64) (float64, error) {
 	if height <= 0 {
-		return 0, errors.New("height must be greater than 0")
+		return 0, i18n.New("la taille doit être supérieure à 0")
 	}
 	heightInMeters := height / 100
 	bmi := weight / (heightInMeters * heightInMeters)
@@ -27,10 +30,10 @@ func CalculateBMI(weight, height float64) (float64, error) {
 
 func CalculateBodyFat(gender Gender, height, waistCircumference, neckCircumference, hipCircumference float64) (float64, error) {
 	if height <= 0 {
-		return 0, errors.New("height must be greater than 0")
+		return 0, i18n.New("la taille doit être supérieure à 0")
 	}
 	if waistCircumference <= 0 || neckCircumference <= 0 {
-		return 0, errors.New("waist and neck measurements must be positive")
+		return 0, i18n.New("les tours de taille et de cou doivent être positifs")
 	}
 
 	var bodyFat float64
@@ -39,20 +42,20 @@ func CalculateBodyFat(gender Gender, height, waistCircumference, neckCircumferen
 	case Male:
 		diff := waistCircumference - neckCircumference
 		if diff <= 0 {
-			return 0, errors.New("waist circumference must be greater than neck circumference for males")
+			return 0, i18n.New("le tour de taille doit être supérieur au tour de cou pour un homme")
 		}
 		bodyFat = math.Round((495 / (1.0324 - 0.19077*math.Log10(diff) + 0.15456*math.Log10(height))) - 450)
 	case Female:
 		if hipCircumference <= 0 {
-			return 0, errors.New("hip circumference must be positive for females")
+			return 0, i18n.New("le tour de hanches doit être positif pour une femme")
 		}
 		sum := waistCircumference + hipCircumference - neckCircumference
 		if sum <= 0 {
-			return 0, errors.New("the sum of waist + hip - neck circumference must be positive for females")
+			return 0, i18n.New("taille + hanches - cou doit être positif pour une femme")
 		}
 		bodyFat = math.Round((495 / (1.29579 - 0.35004*math.Log10(sum) + 0.22100*math.Log10(height))) - 450)
 	default:
-		return 0, errors.New("invalid gender: must be 'male' or 'female'")
+		return 0, i18n.New("genre invalide : 'male' ou 'female' attendu")
 	}
 
 	return bodyFat, nil
@@ -1,13 +1,11 @@
 package models
 
 import (
-	"errors"
 	"math"
-	"os"
-	"sort"
 	"time"
 
-	chart "github.com/wcharczuk/go-chart/v2"
+	"github.com/lsoulet/gofit/i18n"
+	"gorm.io/gorm"
 )
 
 type Gender string
@@ -17,6 +15,17 @@ const (
 	Female Gender = "female"
 )
 
+// Label renvoie le nom affiché du genre
+func (g Gender) Label() string {
+	switch g {
+	case Male:
+		return i18n.T("Homme")
+	case Female:
+		return i18n.T("Femme")
+	}
+	return string(g)
+}
+
 type User struct {
 	ID                uint   `gorm:"primaryKey"`
 	FirstName         string `gorm:"not null"`
@@ -25,57 +34,114 @@ type User struct {
 	Measurements      []Measurement `gorm:"foreignKey:UserID"`
 	Goal              Goal
 	Gender            Gender      `gorm:"not null"`
-	DailyMenus        []DailyMenu `gorm:"many2many:user_dailymenus;"`
+	DailyMenus        []DailyMenu `gorm:"foreignKey:UserID"`
 	CalorieNeeds      float64
 	ProteinNeeds      float64
 	CarohydratesNeeds float64
 	LipidNeeds        float64
+	TimeZone          string         `gorm:"not null;default:'Europe/Paris'"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+// DefaultTimeZone est le fuseau attribué aux utilisateurs qui n'en ont pas choisi.
+const DefaultTimeZone = "Europe/Paris"
+
+// Location renvoie le fuseau horaire de l'utilisateur, ou DefaultTimeZone
+// s'il est vide ou inconnu.
+func (u *User) Location() *time.Location {
+	if u.TimeZone != "" {
+		if loc, err := time.LoadLocation(u.TimeZone); err == nil {
+			return loc
+		}
+	}
+	if loc, err := time.LoadLocation(DefaultTimeZone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Now renvoie l'instant courant dans le fuseau de l'utilisateur.
+func (u *User) Now() time.Time {
+	return time.Now().In(u.Location())
+}
+
+// Day renvoie le jour calendaire de l'utilisateur correspondant à l'instant t,
+// sous la forme stockée dans DailyMenu.Date.
+func (u *User) Day(t time.Time) time.Time {
+	return CalendarDay(t.In(u.Location()))
+}
+
+// ParseDay interprète une date saisie au format layout dans le fuseau de
+// l'utilisateur et renvoie minuit heure locale, utilisable avec Day.
+func (u *User) ParseDay(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, u.Location())
 }
 
 func (u *User) GetMealsByDate(date time.Time) ([]Meal, error) {
 	for _, dm := range u.DailyMenus {
-		if sameDay(dm.Date, date) {
+		if sameDay(dm.Date, u.Day(date)) {
 			return dm.Meals, nil
 		}
 	}
-	return nil, errors.New("no meals found for this date")
+	return nil, i18n.New("aucun repas trouvé pour cette date")
+}
+
+// GetDailyMenuByDate renvoie le menu de l'utilisateur pour le jour de date
+func (u *User) GetDailyMenuByDate(date time.Time) (DailyMenu, error) {
+	for _, dm := range u.DailyMenus {
+		if sameDay(dm.Date, u.Day(date)) {
+			return dm, nil
+		}
+	}
+	return DailyMenu{}, i18n.New("aucun menu trouvé pour cette date")
 }
 
 func (u *User) AddMealToDate(date time.Time, meal Meal) error {
+	if meal.LoggedAt.IsZero() {
+		meal.LoggedAt = date
+	}
+
+	day := u.Day(date)
 	for i, dm := range u.DailyMenus {
-		if sameDay(dm.Date, date) {
+		if sameDay(dm.Date, day) {
+			// Rattache le repas au menu déjà persisté pour qu'un Save le retrouve
+			if dm.ID != 0 {
+				menuID := dm.ID
+				meal.DailyMenuID = &menuID
+			}
 			u.DailyMenus[i].Meals = append(u.DailyMenus[i].Meals, meal)
 			return nil
 		}
 	}
 
 	u.DailyMenus = append(u.DailyMenus, DailyMenu{
-		Date:  date,
-		Meals: []Meal{meal},
+		UserID: u.ID,
+		Date:   day,
+		Meals:  []Meal{meal},
 	})
 	return nil
 }
 
 func (u *User) RemoveMeal(date time.Time, mealIndex int) error {
 	for i := range u.DailyMenus {
-		if sameDay(u.DailyMenus[i].Date, date) {
+		if sameDay(u.DailyMenus[i].Date, u.Day(date)) {
 			if mealIndex < 0 || mealIndex >= len(u.DailyMenus[i].Meals) {
-				return errors.New("invalid meal index")
+				return i18n.New("index de repas invalide")
 			}
 			u.DailyMenus[i].Meals = append(u.DailyMenus[i].Meals[:mealIndex], u.DailyMenus[i].Meals[mealIndex+1:]...)
 			return nil
 		}
 	}
-	return errors.New("no meals found on this date")
+	return i18n.New("aucun repas trouvé pour cette date")
 }
 
 func (u *User) GetDailyMacros(date time.Time) (float64, float64, float64, float64, error) {
 	for _, dm := range u.DailyMenus {
-		if sameDay(dm.Date, date) {
+		if sameDay(dm.Date, u.Day(date)) {
 			return dm.GetDailyMacroSummary()
 		}
 	}
-	return 0, 0, 0, 0, errors.New("no meals found on this date")
+	return 0, 0, 0, 0, i18n.New("aucun repas trouvé pour cette date")
 }
 
 func (u *User) UpdateNutritionGoals() {
@@ -125,85 +191,9 @@ func (u *User) UpdateNutritionGoals() {
 	u.CarohydratesNeeds = math.Round(carbs*100) / 100
 }
 
-func (u *User) GenerateNutritionChart(filename string) error {
-	// Trie les menus par date
-	sort.Slice(u.DailyMenus, func(i, j int) bool {
-		return u.DailyMenus[i].Date.Before(u.DailyMenus[j].Date)
-	})
-
-	var dates []time.Time
-	var calories, proteins, carbohydrates, lipids []float64
-
-	for _, dm := range u.DailyMenus {
-		dates = append(dates, dm.Date)
-		cal, prot, carb, fat, _ := dm.GetDailyMacroSummary()
-		calories = append(calories, cal)
-		proteins = append(proteins, prot)
-		carbohydrates = append(carbohydrates, carb)
-		lipids = append(lipids, fat)
-	}
-
-	graph := chart.Chart{
-		Width:  1024,
-		Height: 512,
-		XAxis: chart.XAxis{
-			Name: "Date",
-			ValueFormatter: func(v interface{}) string {
-				if val, ok := v.(time.Time); ok {
-					return val.Format("02/01")
-				}
-				return ""
-			},
-		},
-		YAxis: chart.YAxis{
-			Name: "Valeur (kcal / g)",
-		},
-		Series: []chart.Series{
-			chart.TimeSeries{
-				Name:    "Calories",
-				XValues: dates,
-				YValues: calories,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(0), StrokeWidth: 2},
-			},
-			chart.TimeSeries{
-				Name:    "Protéines (g)",
-				XValues: dates,
-				YValues: proteins,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(1), StrokeWidth: 2},
-			},
-			chart.TimeSeries{
-				Name:    "Glucides (g)",
-				XValues: dates,
-				YValues: carbohydrates,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(2), StrokeWidth: 2},
-			},
-			chart.TimeSeries{
-				Name:    "Lipides (g)",
-				XValues: dates,
-				YValues: lipids,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(3), StrokeWidth: 2},
-			},
-		},
-	}
-
-	// Active la légende
-	graph.Elements = []chart.Renderable{
-		chart.Legend(&graph),
-	}
-
-	// Création du fichier image
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return graph.Render(chart.PNG, f)
-}
-
 func (u *User) UpdateProfile(weight, height float64, age int, goal Goal, gender Gender, waist, neck, hip float64) error {
 	if weight <= 0 || height <= 0 || age <= 0 {
-		return errors.New("weight, height and age must be greater than 0")
+		return i18n.New("le poids, la taille et l'âge doivent être supérieurs à 0")
 	}
 
 	u.Age = age
@@ -221,7 +211,8 @@ func (u *User) UpdateProfile(weight, height float64, age int, goal Goal, gender
 	}
 
 	measurement := Measurement{
-		Date:    time.Now(),
+		UserID:  u.ID,
+		Date:    u.Now(),
 		Weight:  weight,
 		Height:  height,
 		BMI:     bmi,
@@ -231,68 +222,3 @@ func (u *User) UpdateProfile(weight, height float64, age int, goal Goal, gender
 	u.Measurements = append(u.Measurements, measurement)
 	return nil
 }
-
-func (u *User) GenerateBodyTrackingChart(filename string) error {
-	if len(u.Measurements) == 0 {
-		return errors.New("no measurements available to generate the chart")
-	}
-
-	// Trie les mesures par date
-	sort.Slice(u.Measurements, func(i, j int) bool {
-		return u.Measurements[i].Date.Before(u.Measurements[j].Date)
-	})
-
-	var dates []time.Time
-	var bmiValues []float64
-	var bodyFatValues []float64
-
-	for _, m := range u.Measurements {
-		dates = append(dates, m.Date)
-		bmiValues = append(bmiValues, m.BMI)
-		bodyFatValues = append(bodyFatValues, m.BodyFat)
-	}
-
-	graph := chart.Chart{
-		Width:  1024,
-		Height: 512,
-		XAxis: chart.XAxis{
-			Name: "Date",
-			ValueFormatter: func(v interface{}) string {
-				if val, ok := v.(time.Time); ok {
-					return val.Format("02/01")
-				}
-				return ""
-			},
-		},
-		YAxis: chart.YAxis{
-			Name: "Value",
-		},
-		Series: []chart.Series{
-			chart.TimeSeries{
-				Name:    "BMI",
-				XValues: dates,
-				YValues: bmiValues,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(0), StrokeWidth: 2},
-			},
-			chart.TimeSeries{
-				Name:    "Body Fat (%)",
-				XValues: dates,
-				YValues: bodyFatValues,
-				Style:   chart.Style{StrokeColor: chart.GetDefaultColor(1), StrokeWidth: 2},
-			},
-		},
-	}
-
-	// Maintenant que graph est défini, on peut ajouter la légende
-	graph.Elements = []chart.Renderable{
-		chart.Legend(&graph),
-	}
-
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return graph.Render(chart.PNG, f)
-}
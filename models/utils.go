@@ -7,3 +7,10 @@ func sameDay(d1, d2 time.Time) bool {
 	y2, m2, d2day := d2.Date()
 	return y1 == y2 && m1 == m2 && d1day == d2day
 }
+
+// CalendarDay ramène t au jour calendaire qu'il représente dans son propre
+// fuseau, exprimé à minuit UTC : c'est la valeur stockée dans DailyMenu.Date.
+func CalendarDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,103 @@
+// Package output écrit les résultats des commandes dans un format lisible par
+// un script (JSON, CSV, YAML) ; le format table est laissé aux commandes.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/lsoulet/gofit/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Format est un format de sortie
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// ParseFormat valide un nom de format
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Table, JSON, CSV, YAML:
+		return f, nil
+	}
+	return "", i18n.Errorf("format inconnu : %q (table, json, csv ou yaml)", s)
+}
+
+// String et Set permettent d'utiliser un Format comme option de flag.FlagSet
+func (f *Format) String() string { return string(*f) }
+
+func (f *Format) Set(s string) error {
+	parsed, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// Record est un enregistrement qui sait s'écrire sur une ligne CSV
+type Record interface {
+	CSVHeader() []string
+	CSVRow() []string
+}
+
+// Tabular est un document qui sait s'écrire en CSV
+type Tabular interface {
+	CSV() (header []string, rows [][]string)
+}
+
+// List est une liste d'enregistrements, écrite en CSV à raison d'une ligne par enregistrement
+type List[T Record] []T
+
+func (l List[T]) CSV() ([]string, [][]string) {
+	var zero T
+	rows := make([][]string, len(l))
+	for i, r := range l {
+		rows[i] = r.CSVRow()
+	}
+	return zero.CSVHeader(), rows
+}
+
+// Write écrit doc au format f. En CSV, doc doit être un Record ou un Tabular.
+func Write(w io.Writer, f Format, doc any) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		var header []string
+		var rows [][]string
+		switch d := doc.(type) {
+		case Tabular:
+			header, rows = d.CSV()
+		case Record:
+			header, rows = d.CSVHeader(), [][]string{d.CSVRow()}
+		default:
+			return i18n.Errorf("ce résultat ne peut pas être écrit en CSV")
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+	return i18n.Errorf("format %q non géré par output.Write", f)
+}
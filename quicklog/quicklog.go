@@ -0,0 +1,314 @@
+// Package quicklog analyse une saisie libre de repas, comme
+// « 150g chicken breast, 1 cup rice for lunch » ou « 2 œufs et 1 tranche de
+// pain au petit déjeuner » : quantités, unités, noms d'aliments et type de repas.
+package quicklog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lsoulet/gofit/i18n"
+	"github.com/lsoulet/gofit/models"
+)
+
+// Unit est l'unité d'une quantité saisie
+type Unit string
+
+// Unités reconnues ; les masses et les volumes métriques sont convertis en
+// grammes et en millilitres à l'analyse
+const (
+	Gram       Unit = "g"
+	Milliliter Unit = "ml"
+	Cup        Unit = "cup"
+	Tablespoon Unit = "tbsp"
+	Teaspoon   Unit = "tsp"
+	Slice      Unit = "slice"
+	Serving    Unit = "serving"
+	// Piece désigne un aliment compté (« 2 eggs ») ou sans quantité
+	Piece Unit = "piece"
+)
+
+// Milliliters donne le volume d'une mesure ménagère, utilisé quand l'aliment
+// n'en précise pas le poids
+var Milliliters = map[Unit]float64{
+	Milliliter: 1,
+	Cup:        240,
+	Tablespoon: 15,
+	Teaspoon:   5,
+}
+
+// Entry est un aliment de la saisie
+type Entry struct {
+	// Text est l'élément tel que saisi, ex. « 150g chicken breast »
+	Text   string
+	Amount float64
+	Unit   Unit
+	Food   string
+}
+
+// Grams renvoie le poids de l'entrée quand son unité suffit à le connaître :
+// grammes, ou millilitres comptés comme de l'eau
+func (e Entry) Grams() (float64, bool) {
+	switch e.Unit {
+	case Gram, Milliliter:
+		return e.Amount, true
+	}
+	return 0, false
+}
+
+// Log est une saisie analysée
+type Log struct {
+	Entries []Entry
+	// MealType est vide si la saisie ne précise pas le repas
+	MealType models.MealType
+}
+
+// unitAlias associe une façon d'écrire une unité à l'unité et au facteur de conversion
+type unitAlias struct {
+	alias  string
+	unit   Unit
+	factor float64
+}
+
+// unitAliases liste les unités reconnues ; un alias doit être suivi d'une
+// espace, si bien que « g » ne reconnaît pas le début de « grapes »
+var unitAliases = []unitAlias{
+	{"g", Gram, 1}, {"gr", Gram, 1}, {"gram", Gram, 1}, {"grams", Gram, 1}, {"gramme", Gram, 1}, {"grammes", Gram, 1},
+	{"kg", Gram, 1000}, {"kilo", Gram, 1000}, {"kilos", Gram, 1000},
+	{"mg", Gram, 0.001},
+	{"oz", Gram, 28.35}, {"ounce", Gram, 28.35}, {"ounces", Gram, 28.35},
+	{"lb", Gram, 453.59}, {"lbs", Gram, 453.59}, {"pound", Gram, 453.59}, {"pounds", Gram, 453.59},
+	{"ml", Milliliter, 1}, {"cl", Milliliter, 10}, {"dl", Milliliter, 100},
+	{"l", Milliliter, 1000}, {"litre", Milliliter, 1000}, {"litres", Milliliter, 1000}, {"liter", Milliliter, 1000}, {"liters", Milliliter, 1000},
+	{"cup", Cup, 1}, {"cups", Cup, 1}, {"tasse", Cup, 1}, {"tasses", Cup, 1}, {"bol", Cup, 1}, {"bols", Cup, 1},
+	{"tbsp", Tablespoon, 1}, {"tablespoon", Tablespoon, 1}, {"tablespoons", Tablespoon, 1},
+	{"cuillère à soupe", Tablespoon, 1}, {"cuillères à soupe", Tablespoon, 1}, {"c. à s.", Tablespoon, 1}, {"càs", Tablespoon, 1},
+	{"tsp", Teaspoon, 1}, {"teaspoon", Teaspoon, 1}, {"teaspoons", Teaspoon, 1},
+	{"cuillère à café", Teaspoon, 1}, {"cuillères à café", Teaspoon, 1}, {"c. à c.", Teaspoon, 1}, {"càc", Teaspoon, 1},
+	{"slice", Slice, 1}, {"slices", Slice, 1}, {"tranche", Slice, 1}, {"tranches", Slice, 1},
+	{"piece", Piece, 1}, {"pieces", Piece, 1}, {"pièce", Piece, 1}, {"pièces", Piece, 1},
+	{"serving", Serving, 1}, {"servings", Serving, 1}, {"portion", Serving, 1}, {"portions", Serving, 1},
+}
+
+// demiPrefix divise par deux la quantité du mot qu'il précède : « demi-baguette »
+const demiPrefix = "demi-"
+
+// amountWords sont les quantités écrites en toutes lettres
+var amountWords = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "un": 1, "une": 1,
+	"two": 2, "deux": 2, "three": 3, "trois": 3,
+	"half": 0.5, "demi": 0.5, "demie": 0.5, "½": 0.5, "¼": 0.25, "¾": 0.75,
+}
+
+var (
+	// mealSuffix reconnaît le repas en fin de saisie : « for lunch », « au petit déjeuner »
+	mealSuffix = regexp.MustCompile(`(?i)[\s,]+(?:for|at|pour|au|à|en)\s+(?:(?:the|my|le|la|mon)\s+)?(petit[- ]d[ée]jeuner|breakfast|d[ée]jeuner|lunch|d[îi]ner|dinner|supper|souper|collation|snack|go[ûu]ter)\s*$`)
+	// mealPrefix reconnaît le repas en début de saisie : « lunch: ... »
+	mealPrefix = regexp.MustCompile(`(?i)^\s*(petit[- ]d[ée]jeuner|breakfast|d[ée]jeuner|lunch|d[îi]ner|dinner|supper|souper|collation|snack|go[ûu]ter)\s*:\s*`)
+	// separator sépare les aliments ; les virgules sont traitées à part
+	separator = regexp.MustCompile(`\s*;\s*|\s+\+\s+`)
+	// conjunction sépare deux aliments seulement si le second commence par une
+	// quantité, pour garder « macaroni and cheese » en un seul aliment
+	conjunction = regexp.MustCompile(`(?i)\s+(?:and|et|plus)\s+`)
+	// number reconnaît une quantité numérique : 150, 1.5, 1,5, 1/2
+	number = regexp.MustCompile(`^(\d+(?:[.,]\d+)?(?:/\d+)?)`)
+	// fraction reconnaît la fraction qui suit un entier : « 1 1/2 », « 1½ »
+	fraction = regexp.MustCompile(`^\s*(\d+/\d+|[½¼¾])`)
+	// partitive retire « of », « de », « d' » entre l'unité et l'aliment
+	partitive = regexp.MustCompile(`(?i)^(?:of\s+(?:the\s+)?|de\s+(?:la\s+|l')?|du\s+|des\s+|d')`)
+)
+
+// Parse analyse une saisie libre
+func Parse(text string) (Log, error) {
+	var log Log
+	text = strings.TrimSpace(text)
+	if m := mealSuffix.FindStringSubmatchIndex(text); m != nil {
+		log.MealType = mealType(text[m[2]:m[3]])
+		text = text[:m[0]]
+	} else if m := mealPrefix.FindStringSubmatchIndex(text); m != nil {
+		log.MealType = mealType(text[m[2]:m[3]])
+		text = text[m[1]:]
+	}
+
+	for _, part := range splitItems(text) {
+		entry, err := parseEntry(part)
+		if err != nil {
+			return log, err
+		}
+		log.Entries = append(log.Entries, entry)
+	}
+	if len(log.Entries) == 0 {
+		return log, i18n.Errorf("aucun aliment dans la saisie")
+	}
+	return log, nil
+}
+
+// MealTypeAt devine le repas d'après l'heure de la saisie
+func MealTypeAt(t time.Time) models.MealType {
+	switch h := t.Hour(); {
+	case h < 11:
+		return models.Breakfast
+	case h < 15:
+		return models.Lunch
+	case h < 18:
+		return models.Snack
+	}
+	return models.Dinner
+}
+
+func mealType(word string) models.MealType {
+	switch w := strings.ToLower(word); {
+	case strings.HasPrefix(w, "petit"), w == "breakfast":
+		return models.Breakfast
+	case strings.HasPrefix(w, "d") && strings.Contains(w, "jeuner"), w == "lunch":
+		return models.Lunch
+	case w == "collation", w == "snack", strings.HasPrefix(w, "go"):
+		return models.Snack
+	}
+	return models.Dinner
+}
+
+// splitItems découpe la saisie en aliments ; une virgule entre deux chiffres
+// est un séparateur décimal
+func splitItems(text string) []string {
+	var parts []string
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		if r != ',' {
+			continue
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+			continue
+		}
+		parts = append(parts, string(runes[start:i]))
+		start = i + 1
+	}
+	parts = append(parts, string(runes[start:]))
+
+	var items []string
+	for _, part := range parts {
+		for _, part := range separator.Split(part, -1) {
+			for _, item := range splitConjunctions(part) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+	return items
+}
+
+// splitConjunctions coupe text sur « and », « et » et « plus » suivis d'une
+// quantité : « 2 eggs and 1 slice of bread » donne deux aliments, « macaroni
+// and cheese » un seul
+func splitConjunctions(text string) []string {
+	var items []string
+	start := 0
+	for _, m := range conjunction.FindAllStringIndex(text, -1) {
+		if m[0] < start || !startsWithAmount(text[m[1]:]) {
+			continue
+		}
+		items = append(items, text[start:m[0]])
+		start = m[1]
+	}
+	return append(items, text[start:])
+}
+
+// startsWithAmount indique si text commence par une quantité, en chiffres ou
+// en lettres
+func startsWithAmount(text string) bool {
+	if number.MatchString(text) {
+		return true
+	}
+	word, _, _ := strings.Cut(strings.ToLower(text), " ")
+	return amountWords[word] > 0 || strings.HasPrefix(word, demiPrefix)
+}
+
+// parseEntry lit « <quantité> [unité] [de] <aliment> » ; sans quantité,
+// l'aliment compte pour une pièce
+func parseEntry(text string) (Entry, error) {
+	entry := Entry{Text: text, Amount: 1, Unit: Piece}
+	rest := text
+
+	if m := number.FindString(rest); m != "" {
+		amount, err := parseAmount(m)
+		if err != nil {
+			return entry, i18n.Errorf("quantité invalide dans « %s »", text)
+		}
+		entry.Amount = amount
+		rest = rest[len(m):]
+		// Nombre fractionnaire : « 1 1/2 cups », « 1½ cup »
+		if f := fraction.FindStringSubmatch(rest); f != nil && !strings.ContainsAny(m, ".,/") {
+			part, ok := amountWords[f[1]]
+			if !ok {
+				if part, err = parseAmount(f[1]); err != nil {
+					return entry, i18n.Errorf("quantité invalide dans « %s »", text)
+				}
+			}
+			entry.Amount += part
+			rest = rest[len(f[0]):]
+		}
+		rest = strings.TrimSpace(rest)
+	} else if word, after, _ := strings.Cut(rest, " "); amountWords[strings.ToLower(word)] > 0 {
+		entry.Amount = amountWords[strings.ToLower(word)]
+		rest = strings.TrimSpace(after)
+		// « half a cup », « un demi litre »
+		word, after, _ := strings.Cut(rest, " ")
+		switch half := amountWords[strings.ToLower(word)]; {
+		case entry.Amount < 1 && (word == "a" || word == "an"):
+			rest = strings.TrimSpace(after)
+		case entry.Amount == 1 && half > 0 && half < 1:
+			entry.Amount = half
+			rest = strings.TrimSpace(after)
+		}
+	}
+	// « une demi-baguette », « demi-litre de lait »
+	if strings.HasPrefix(strings.ToLower(rest), demiPrefix) {
+		entry.Amount *= 0.5
+		rest = rest[len(demiPrefix):]
+	}
+
+	lower := strings.ToLower(rest)
+	for _, a := range unitAliases {
+		if !strings.HasPrefix(lower, a.alias) {
+			continue
+		}
+		after := rest[len(a.alias):]
+		if after != "" && !strings.HasPrefix(after, " ") {
+			continue
+		}
+		entry.Amount *= a.factor
+		entry.Unit = a.unit
+		rest = strings.TrimSpace(after)
+		break
+	}
+
+	entry.Food = strings.TrimSpace(partitive.ReplaceAllString(rest, ""))
+	if entry.Food == "" {
+		return entry, i18n.Errorf("aliment manquant dans « %s »", text)
+	}
+	if entry.Amount <= 0 {
+		return entry, i18n.Errorf("quantité invalide dans « %s »", text)
+	}
+	return entry, nil
+}
+
+func parseAmount(s string) (float64, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, err
+		}
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil || d == 0 {
+			return 0, strconv.ErrSyntax
+		}
+		return n / d, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,116 @@
+package quicklog
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lsoulet/gofit/models"
+)
+
+// item est une entrée attendue, sans son texte saisi
+type item struct {
+	amount float64
+	unit   Unit
+	food   string
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		want     []item
+		mealType models.MealType
+	}{
+		{"grammes et mesure ménagère", "150g chicken breast, 1 cup rice for lunch",
+			[]item{{150, Gram, "chicken breast"}, {1, Cup, "rice"}}, models.Lunch},
+		{"français", "2 œufs et 1 tranche de pain au petit déjeuner",
+			[]item{{2, Piece, "œufs"}, {1, Slice, "pain"}}, models.Breakfast},
+		{"repas en préfixe", "snack: une pomme",
+			[]item{{1, Piece, "pomme"}}, models.Snack},
+		{"aliment composé", "macaroni and cheese for dinner",
+			[]item{{1, Piece, "macaroni and cheese"}}, models.Dinner},
+		{"et sans quantité", "pain et beurre",
+			[]item{{1, Piece, "pain et beurre"}}, ""},
+		{"and suivi d'un article", "toast and a coffee",
+			[]item{{1, Piece, "toast"}, {1, Piece, "coffee"}}, ""},
+		{"plus et point-virgule", "rice plus 2 eggs; 1 tbsp olive oil",
+			[]item{{1, Piece, "rice"}, {2, Piece, "eggs"}, {1, Tablespoon, "olive oil"}}, ""},
+		{"virgule décimale", "1,5 kg de pommes de terre",
+			[]item{{1500, Gram, "pommes de terre"}}, ""},
+		{"fraction", "1/2 cup oats",
+			[]item{{0.5, Cup, "oats"}}, ""},
+		{"nombre fractionnaire", "1 1/2 cups rice",
+			[]item{{1.5, Cup, "rice"}}, ""},
+		{"fraction unicode accolée", "2½ cups milk",
+			[]item{{2.5, Cup, "milk"}}, ""},
+		{"quantité en lettres", "half a cup of milk",
+			[]item{{0.5, Cup, "milk"}}, ""},
+		{"demi-", "une demi-baguette",
+			[]item{{0.5, Piece, "baguette"}}, ""},
+		{"un demi", "un demi litre de lait",
+			[]item{{500, Milliliter, "lait"}}, ""},
+		{"unité collée à l'aliment", "3 grapes",
+			[]item{{3, Piece, "grapes"}}, ""},
+		{"unité en plusieurs mots", "2 cuillères à soupe de sucre",
+			[]item{{2, Tablespoon, "sucre"}}, ""},
+		{"onces", "4 oz of the salmon",
+			[]item{{4 * 28.35, Gram, "salmon"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q) : %v", tt.text, err)
+			}
+			var got []item
+			for _, e := range log.Entries {
+				got = append(got, item{math.Round(e.Amount*1000) / 1000, e.Unit, e.Food})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, attendu %v", tt.text, got, tt.want)
+			}
+			if log.MealType != tt.mealType {
+				t.Errorf("Parse(%q) : repas %q, attendu %q", tt.text, log.MealType, tt.mealType)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"vide", "   "},
+		{"aliment manquant", "150 g"},
+		{"quantité nulle", "0 g rice"},
+		{"division par zéro", "1/0 cup rice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if log, err := Parse(tt.text); err == nil {
+				t.Errorf("Parse(%q) = %v, une erreur était attendue", tt.text, log.Entries)
+			}
+		})
+	}
+}
+
+func TestMealTypeAt(t *testing.T) {
+	tests := []struct {
+		hour int
+		want models.MealType
+	}{
+		{7, models.Breakfast},
+		{12, models.Lunch},
+		{16, models.Snack},
+		{20, models.Dinner},
+	}
+	for _, tt := range tests {
+		at := time.Date(2026, 10, 19, tt.hour, 0, 0, 0, time.UTC)
+		if got := MealTypeAt(at); got != tt.want {
+			t.Errorf("MealTypeAt(%dh) = %q, attendu %q", tt.hour, got, tt.want)
+		}
+	}
+}
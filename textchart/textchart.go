@@ -0,0 +1,88 @@
+// Package textchart dessine des graphiques en caractères Unicode, lisibles
+// directement dans le terminal : sparklines et barres horizontales.
+package textchart
+
+import (
+	"math"
+	"strings"
+)
+
+// levels sont les hauteurs d'une sparkline, de la plus basse à la plus haute
+var levels = []rune("▁▂▃▄▅▆▇█")
+
+// eighths sont les fins de barre, de zéro à un caractère plein par huitième
+var eighths = []rune(" ▏▎▍▌▋▊▉█")
+
+// Sparkline renvoie la courbe de values sur une ligne, un caractère par
+// valeur, entre la plus petite et la plus grande. Une valeur NaN est un trou,
+// dessiné par une espace. Au-delà de width valeurs, elles sont regroupées en
+// width colonnes dont chacune montre la moyenne ; width ≤ 0 ne limite pas.
+func Sparkline(values []float64, width int) string {
+	values = resample(values, width)
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case high == low:
+			// Une série constante reste à mi-hauteur
+			b.WriteRune(levels[len(levels)/2-1])
+		default:
+			i := int(math.Round((v - low) / (high - low) * float64(len(levels)-1)))
+			b.WriteRune(levels[i])
+		}
+	}
+	return b.String()
+}
+
+// resample regroupe values en width colonnes, chacune la moyenne des valeurs
+// qui ne sont pas NaN ; NaN si la colonne n'en a aucune
+func resample(values []float64, width int) []float64 {
+	if width <= 0 || len(values) <= width {
+		return values
+	}
+	out := make([]float64, width)
+	for col := range out {
+		first, end := col*len(values)/width, (col+1)*len(values)/width
+		var sum float64
+		n := 0
+		for _, v := range values[first:end] {
+			if !math.IsNaN(v) {
+				sum += v
+				n++
+			}
+		}
+		out[col] = math.NaN()
+		if n > 0 {
+			out[col] = sum / float64(n)
+		}
+	}
+	return out
+}
+
+// Bar renvoie une barre horizontale de value sur une échelle de 0 à max,
+// au huitième de caractère près, complétée par des espaces jusqu'à width
+// caractères. Une valeur au-delà de max remplit toute la barre.
+func Bar(value, max float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	units := 0
+	if max > 0 && value > 0 {
+		units = int(math.Round(math.Min(value/max, 1) * float64(width*8)))
+	}
+	full, rest := units/8, units%8
+	bar := strings.Repeat(string(eighths[8]), full)
+	if rest > 0 {
+		bar += string(eighths[rest])
+		full++
+	}
+	return bar + strings.Repeat(" ", width-full)
+}
@@ -0,0 +1,65 @@
+package textchart
+
+import (
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBar(t *testing.T) {
+	tests := []struct {
+		name       string
+		value, max float64
+		width      int
+		want       string
+	}{
+		{"vide", 0, 10, 4, "    "},
+		{"moitié", 5, 10, 4, "██  "},
+		{"huitièmes", 1, 8, 1, "▏"},
+		{"plein", 10, 10, 4, "████"},
+		{"au-delà du maximum", 20, 10, 4, "████"},
+		{"échelle nulle", 5, 0, 3, "   "},
+		{"largeur nulle", 5, 10, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Bar(tt.value, tt.max, tt.width); got != tt.want {
+				t.Errorf("Bar(%v, %v, %d) = %q, attendu %q", tt.value, tt.max, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		want   string
+	}{
+		{"croissante", []float64{0, 1, 2, 3, 4, 5, 6, 7}, 0, "▁▂▃▄▅▆▇█"},
+		{"trou", []float64{1, nan, 3}, 0, "▁ █"},
+		{"constante", []float64{5, 5, 5}, 0, "▄▄▄"},
+		{"regroupée", []float64{0, 0, 10, 10}, 2, "▁█"},
+		{"colonne sans valeur", []float64{nan, nan, 1, 2}, 2, " ▄"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sparkline(tt.values, tt.width)
+			if got != tt.want {
+				t.Errorf("Sparkline(%v, %d) = %q, attendu %q", tt.values, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparklineWidth(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	if got := utf8.RuneCountInString(Sparkline(values, 30)); got != 30 {
+		t.Errorf("Sparkline sur 30 colonnes : %d caractères", got)
+	}
+}
@@ -0,0 +1,232 @@
+// Package tui affiche une application plein écran dans le terminal : écran
+// alternatif, mode brut, lecture des touches et dessin des lignes.
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// ErrNotTerminal est renvoyée par Start quand l'entrée n'est pas un terminal
+var ErrNotTerminal = i18n.New("l'entrée n'est pas un terminal")
+
+// Key est une touche lue au clavier : un caractère, ou une touche spéciale
+type Key struct {
+	Rune    rune
+	Special Special
+}
+
+// Special est une touche sans caractère
+type Special int
+
+const (
+	NoSpecial Special = iota
+	Up
+	Down
+	Left
+	Right
+	Enter
+	Escape
+	Interrupt
+)
+
+// Séquences de l'écran alternatif, du curseur et de l'effacement
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	home           = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+// Screen est le terminal passé en plein écran
+type Screen struct {
+	in    *os.File
+	out   io.Writer
+	r     *bufio.Reader
+	state *term.State
+}
+
+// Start passe le terminal in en mode brut et affiche l'écran alternatif sur out
+func Start(in *os.File, out io.Writer) (*Screen, error) {
+	if !term.IsTerminal(int(in.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	s := &Screen{in: in, out: out, r: bufio.NewReader(in)}
+	return s, s.Resume()
+}
+
+// Input renvoie le tampon de lecture du terminal, à partager avec tout autre
+// lecteur, par exemple pendant Suspend
+func (s *Screen) Input() *bufio.Reader {
+	return s.r
+}
+
+// Suspend rend au terminal son mode et son écran normaux, par exemple pour
+// poser une question ligne à ligne ; Resume revient au plein écran
+func (s *Screen) Suspend() {
+	fmt.Fprint(s.out, leaveAltScreen)
+	if s.state != nil {
+		term.Restore(int(s.in.Fd()), s.state)
+		s.state = nil
+	}
+}
+
+// Resume repasse en mode brut et en plein écran
+func (s *Screen) Resume() error {
+	state, err := term.MakeRaw(int(s.in.Fd()))
+	if err != nil {
+		return err
+	}
+	s.state = state
+	fmt.Fprint(s.out, enterAltScreen)
+	return nil
+}
+
+// Stop quitte le plein écran ; l'écran est retrouvé tel qu'avant Start
+func (s *Screen) Stop() {
+	s.Suspend()
+}
+
+// Size renvoie le nombre de colonnes et de lignes du terminal, 80 × 24 si
+// elles sont inconnues
+func (s *Screen) Size() (int, int) {
+	width, height, err := term.GetSize(int(s.in.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Draw remplace l'écran par lines, coupées à la largeur du terminal ; les
+// lignes au-delà de sa hauteur ne sont pas affichées
+func (s *Screen) Draw(lines []string) {
+	width, height := s.Size()
+	var b strings.Builder
+	b.WriteString(home)
+	for i, line := range lines {
+		if i == height {
+			break
+		}
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(Truncate(line, width))
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+	fmt.Fprint(s.out, b.String())
+}
+
+// ReadKey attend une touche
+func (s *Screen) ReadKey() (Key, error) {
+	r, _, err := s.r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	switch r {
+	case '\r', '\n':
+		return Key{Special: Enter}, nil
+	case 3: // Ctrl-C
+		return Key{Special: Interrupt}, nil
+	case 27:
+		return s.escape()
+	}
+	return Key{Rune: r}, nil
+}
+
+// escape interprète les séquences des touches fléchées ; Échap seule n'est
+// reconnue que si rien ne la suit dans le tampon
+func (s *Screen) escape() (Key, error) {
+	if s.r.Buffered() == 0 {
+		return Key{Special: Escape}, nil
+	}
+	r, _, err := s.r.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return Key{Special: Escape}, err
+	}
+	var seq []rune
+	for {
+		r, _, err = s.r.ReadRune()
+		if err != nil {
+			return Key{}, err
+		}
+		seq = append(seq, r)
+		if r < '0' || r > '9' {
+			break
+		}
+	}
+	switch string(seq) {
+	case "A":
+		return Key{Special: Up}, nil
+	case "B":
+		return Key{Special: Down}, nil
+	case "C":
+		return Key{Special: Right}, nil
+	case "D":
+		return Key{Special: Left}, nil
+	}
+	return Key{}, nil
+}
+
+// Width renvoie le nombre de caractères affichés de s, sans ses séquences
+// de couleur
+func Width(s string) int {
+	return utf8.RuneCountInString(stripEscapes(s))
+}
+
+// Pad complète s par des espaces jusqu'à width caractères
+func Pad(s string, width int) string {
+	if w := Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// Truncate coupe s à width caractères ; ses séquences de couleur sont toutes
+// gardées, pour qu'une couleur ouverte soit refermée
+func Truncate(s string, width int) string {
+	var b strings.Builder
+	w := 0
+	for i := 0; i < len(s); {
+		if s[i] == 27 {
+			end := strings.IndexByte(s[i:], 'm')
+			if end < 0 {
+				break
+			}
+			b.WriteString(s[i : i+end+1])
+			i += end + 1
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if w < width {
+			b.WriteString(s[i : i+size])
+			w++
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// stripEscapes retire de s les séquences de couleur
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 27 {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,243 @@
+// Package wizard enchaîne des questions dans la console : validation des
+// réponses, valeurs par défaut, retour à la question précédente, annulation
+// et récapitulatif avant confirmation.
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lsoulet/gofit/i18n"
+)
+
+// ErrCancelled est renvoyée par Run quand l'utilisateur annule le wizard
+var ErrCancelled = i18n.New("opération annulée")
+
+// Mots-clés reconnus à chaque question ; CancelKeyword est affiché traduit
+// et accepté dans chacune des langues de cancelKeywords
+const (
+	BackKeyword   = "<"
+	CancelKeyword = "annuler"
+)
+
+// cancelKeywords sont les mots-clés d'abandon acceptés, quelle que soit la langue
+var cancelKeywords = []string{CancelKeyword, "cancel"}
+
+// LineReader fournit les réponses saisies par l'utilisateur
+type LineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// Answers contient les réponses déjà validées, indexées par Step.Key
+type Answers map[string]any
+
+// Choice est une réponse possible d'une question à choix numérotés
+type Choice struct {
+	Label string
+	Value any
+}
+
+// Step est une question du wizard
+type Step struct {
+	Key    string
+	Prompt string
+
+	// Label nomme la réponse dans le récapitulatif ; Prompt par défaut
+	Label string
+
+	// Choices propose des réponses numérotées ; l'utilisateur saisit le numéro
+	Choices func(Answers) ([]Choice, error)
+
+	// Default renvoie la réponse utilisée quand la saisie est vide ; "" pour aucune
+	Default func(Answers) string
+
+	// Parse valide et convertit la saisie ; sans Parse, la saisie non vide est gardée telle quelle
+	Parse func(input string, answers Answers) (any, error)
+
+	// Display affiche la valeur convertie dans le récapitulatif ; la saisie par défaut
+	Display func(value any) string
+
+	// Skip permet de sauter la question selon les réponses précédentes
+	Skip func(Answers) bool
+}
+
+// Wizard est une suite de questions
+type Wizard struct {
+	Title string
+	Steps []Step
+
+	// Confirm affiche un récapitulatif et demande confirmation avant de terminer
+	Confirm bool
+}
+
+// Run pose les questions dans l'ordre et renvoie les réponses.
+// « < » revient à la question précédente, « annuler » (ou « cancel »)
+// interrompt le wizard.
+func (w *Wizard) Run(in LineReader, out io.Writer) (Answers, error) {
+	answers := Answers{}
+	display := map[string]string{}
+	var history []int
+
+	if w.Title != "" {
+		fmt.Fprintln(out, w.Title)
+	}
+	i18n.Fprintf(out, "(« %s » pour revenir en arrière, « %s » pour abandonner)\n", BackKeyword, i18n.T(CancelKeyword))
+
+	for i := 0; i <= len(w.Steps); {
+		if i == len(w.Steps) {
+			if !w.Confirm {
+				break
+			}
+			ok, back, err := w.confirm(in, out, display)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				break
+			}
+			if back && len(history) > 0 {
+				i, history = history[len(history)-1], history[:len(history)-1]
+				continue
+			}
+			return nil, ErrCancelled
+		}
+
+		step := w.Steps[i]
+		if step.Skip != nil && step.Skip(answers) {
+			i++
+			continue
+		}
+
+		value, shown, back, err := ask(step, answers, in, out)
+		if err != nil {
+			return nil, err
+		}
+		if back {
+			if len(history) == 0 {
+				i18n.Fprintln(out, "Vous êtes déjà à la première question.")
+				continue
+			}
+			i, history = history[len(history)-1], history[:len(history)-1]
+			continue
+		}
+
+		answers[step.Key] = value
+		display[step.Key] = shown
+		history = append(history, i)
+		i++
+	}
+
+	return answers, nil
+}
+
+// ask pose une question jusqu'à obtenir une réponse valide
+func ask(step Step, answers Answers, in LineReader, out io.Writer) (value any, shown string, back bool, err error) {
+	var choices []Choice
+	if step.Choices != nil {
+		if choices, err = step.Choices(answers); err != nil {
+			return nil, "", false, err
+		}
+		if len(choices) == 0 {
+			return nil, "", false, i18n.Errorf("aucun choix possible pour « %s »", step.Prompt)
+		}
+	}
+
+	def := ""
+	if step.Default != nil {
+		def = step.Default(answers)
+	}
+
+	for {
+		i18n.Fprintf(out, "\n%s\n", step.Prompt)
+		for i, c := range choices {
+			i18n.Fprintf(out, "%d. %s\n", i+1, c.Label)
+		}
+		prompt := "> "
+		if def != "" {
+			prompt = i18n.Sprintf("[%s] > ", def)
+		}
+
+		input, err := in.ReadLine(prompt)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, "", false, ErrCancelled
+			}
+			return nil, "", false, err
+		}
+		input = strings.TrimSpace(input)
+
+		switch lower := strings.ToLower(input); {
+		case lower == BackKeyword:
+			return nil, "", true, nil
+		case slices.Contains(cancelKeywords, lower):
+			return nil, "", false, ErrCancelled
+		case lower == "":
+			if def == "" {
+				i18n.Fprintln(out, "Une réponse est attendue.")
+				continue
+			}
+			input = def
+		}
+
+		if len(choices) > 0 {
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > len(choices) {
+				i18n.Fprintf(out, "Choix invalide. Veuillez entrer un nombre entre 1 et %d.\n", len(choices))
+				continue
+			}
+			return choices[n-1].Value, choices[n-1].Label, false, nil
+		}
+
+		if step.Parse == nil {
+			return input, input, false, nil
+		}
+		v, err := step.Parse(input, answers)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		if step.Display != nil {
+			return v, step.Display(v), false, nil
+		}
+		return v, input, false, nil
+	}
+}
+
+// confirm affiche le récapitulatif ; ok si l'utilisateur valide, back s'il veut corriger
+func (w *Wizard) confirm(in LineReader, out io.Writer, display map[string]string) (ok, back bool, err error) {
+	i18n.Fprintln(out, "\nRécapitulatif :")
+	for _, step := range w.Steps {
+		shown, answered := display[step.Key]
+		if !answered {
+			continue
+		}
+		label := step.Label
+		if label == "" {
+			label = step.Prompt
+		}
+		i18n.Fprintf(out, "  %s : %s\n", label, shown)
+	}
+
+	for {
+		input, err := in.ReadLine(i18n.T("Confirmer ? (o/n, « < » pour corriger) > "))
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, false, ErrCancelled
+			}
+			return false, false, err
+		}
+		switch answer := strings.ToLower(strings.TrimSpace(input)); {
+		case slices.Contains([]string{"o", "oui", "y", "yes"}, answer):
+			return true, false, nil
+		case slices.Contains([]string{"n", "non", "no"}, answer), slices.Contains(cancelKeywords, answer):
+			return false, false, nil
+		case answer == BackKeyword:
+			return false, true, nil
+		}
+		i18n.Fprintln(out, "Répondez o ou n.")
+	}
+}